@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/simota/yam/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+var (
+	anchorName         string
+	anchorWriteInPlace bool
+)
+
+var anchorCmd = &cobra.Command{
+	Use:   "anchor <path> [file]",
+	Short: "Turn a repeated subtree into an anchor and aliases",
+	Long: `Define an anchor on the subtree at a path and replace every other subtree
+with the same content with an alias to it, then print the document as
+formatted YAML.
+
+Subtrees are compared like yam hash: values and resolved tags count, key
+order, quoting and comments do not. An alias must come after its anchor, so
+identical subtrees before the path are left as they are and counted in the
+report. Subtrees that define anchors of their own are not replaced either.
+Comments inside a replaced subtree are dropped.
+
+The number of replaced subtrees is reported on stderr. The output is
+formatted like yam fmt with its default options. Use -w to change the file
+in place; find candidates with yam dups --subtrees. In a file with several
+documents, only the first one with the path is changed.
+
+Examples:
+  yam anchor .defaults --name base config.yaml
+  yam anchor .defaults --name base -w config.yaml
+  yam anchor '.jobs.build.env' --name env -w .gitlab-ci.yml`,
+	Args:          cobra.RangeArgs(1, 2),
+	RunE:          runAnchor,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+func init() {
+	rootCmd.AddCommand(anchorCmd)
+	anchorCmd.Flags().StringVar(&anchorName, "name", "", "Name of the anchor (&name)")
+	anchorCmd.Flags().BoolVarP(&anchorWriteInPlace, "write", "w", false, "Write result to source file instead of stdout")
+	_ = anchorCmd.MarkFlagRequired("name")
+}
+
+func runAnchor(cmd *cobra.Command, args []string) error {
+	var filename string
+	if len(args) == 2 {
+		filename = args[1]
+	}
+	if anchorWriteInPlace && (filename == "" || filename == "-" || isURL(filename)) {
+		return fmt.Errorf("cannot use -w without a file")
+	}
+	if isJSONFile(filename) {
+		return fmt.Errorf("cannot anchor %s: JSON has no anchors", filename)
+	}
+
+	r, _, err := openInput(filename)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	p, err := newParser()
+	if err != nil {
+		return err
+	}
+	docs, err := p.ParseAll(r)
+	if err != nil {
+		return err
+	}
+
+	// Anchors belong to one document; the others are kept as they are
+	root, node, err := documentAt(docs, args[0])
+	if err != nil {
+		return err
+	}
+	replaced, skipped, err := parser.ExtractAnchor(root, node, anchorName)
+	if err != nil {
+		return err
+	}
+
+	write := func(w io.Writer) error {
+		return writeDocuments(w, docs, formatDocument)
+	}
+	if anchorWriteInPlace {
+		err = writeFileAtomic(filename, write)
+	} else {
+		err = write(os.Stdout)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Replaced %s with *%s", occurrences(replaced), anchorName)
+	if skipped > 0 {
+		fmt.Fprintf(os.Stderr, " (%s before %s left as is)", occurrences(skipped), args[0])
+	}
+	fmt.Fprintln(os.Stderr)
+	return nil
+}
+
+// occurrences returns "1 occurrence" or "N occurrences"
+func occurrences(n int) string {
+	if n == 1 {
+		return "1 occurrence"
+	}
+	return fmt.Sprintf("%d occurrences", n)
+}
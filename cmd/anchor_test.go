@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAnchor_MultiDocument(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(file, []byte("d: {a: 1}\ne: {a: 1}\n---\nother: {a: 1}\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	anchorName = "base"
+	anchorWriteInPlace = true
+	defer func() {
+		anchorName = ""
+		anchorWriteInPlace = false
+	}()
+	if err := runAnchor(anchorCmd, []string{".d", file}); err != nil {
+		t.Fatalf("runAnchor failed: %v", err)
+	}
+
+	// An alias cannot cross documents, so the second one is kept as is
+	want := "d: &base {a: 1}\ne: *base\n---\nother: {a: 1}\n"
+	if data, _ := os.ReadFile(file); string(data) != want {
+		t.Errorf("expected %q, got %q", want, data)
+	}
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/simota/yam/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+var (
+	anchorsJSON   bool
+	anchorsUnused bool
+)
+
+var anchorsCmd = &cobra.Command{
+	Use:   "anchors [file]",
+	Short: "List anchors and the aliases that use them",
+	Long: `List every anchor definition (name, path, line) with the number of aliases
+that refer to it and the path and line of each alias. Paths are in JSONPath
+notation, e.g. $.jobs.test['<<'] or $.list[0].
+
+Use this to understand the reuse graph of a heavily anchored file, such as a
+Docker Compose or GitLab CI file, before refactoring it.
+
+Examples:
+  yam anchors docker-compose.yaml
+  yam anchors --unused .gitlab-ci.yml
+  yam anchors --json docker-compose.yaml`,
+	Args:          cobra.MaximumNArgs(1),
+	RunE:          runAnchors,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+func init() {
+	rootCmd.AddCommand(anchorsCmd)
+	anchorsCmd.Flags().BoolVar(&anchorsUnused, "unused", false, "Only list anchors that no alias refers to")
+	anchorsCmd.Flags().BoolVar(&anchorsJSON, "json", false, "Output as JSON")
+}
+
+func runAnchors(cmd *cobra.Command, args []string) error {
+	filename := ""
+	if len(args) == 1 {
+		filename = args[0]
+	}
+	root, err := parseInput(filename)
+	if err != nil {
+		return err
+	}
+
+	anchors := parser.Anchors(root)
+	if anchorsUnused {
+		var unused []parser.AnchorUsage
+		for _, a := range anchors {
+			if a.References == 0 {
+				unused = append(unused, a)
+			}
+		}
+		anchors = unused
+	}
+
+	if anchorsJSON {
+		if anchors == nil {
+			anchors = []parser.AnchorUsage{}
+		}
+		out, err := json.MarshalIndent(anchors, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, a := range anchors {
+		refs := references(a.References)
+		if a.Redefines > 0 {
+			refs += fmt.Sprintf(", redefines line %d", a.Redefines)
+		}
+		fmt.Fprintf(w, "&%s\t%s\t%d\t%s\n", a.Name, a.Path, a.Line, refs)
+		for _, alias := range a.Aliases {
+			fmt.Fprintf(w, "  *%s\t%s\t%d\n", a.Name, alias.Path, alias.Line)
+		}
+	}
+	return w.Flush()
+}
+
+func references(n int) string {
+	if n == 1 {
+		return "1 reference"
+	}
+	return fmt.Sprintf("%d references", n)
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/simota/yam/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+var commentsJSON bool
+
+var commentsCmd = &cobra.Command{
+	Use:   "comments [file]",
+	Short: "List the comments in a YAML file",
+	Long: `List every head, line and foot comment in a YAML file together with the
+path (in JSONPath notation, e.g. $.hosts[0]) and line of the node it is
+attached to.
+
+Comments cannot survive conversion to JSON; this command extracts them so
+documentation tooling can index them.
+
+Examples:
+  yam comments config.yaml
+  yam comments --json config.yaml
+  cat config.yaml | yam comments`,
+	Args:          cobra.MaximumNArgs(1),
+	RunE:          runComments,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+func init() {
+	rootCmd.AddCommand(commentsCmd)
+	commentsCmd.Flags().BoolVar(&commentsJSON, "json", false, "Output as JSON")
+}
+
+func runComments(cmd *cobra.Command, args []string) error {
+	filename := ""
+	if len(args) == 1 {
+		filename = args[0]
+	}
+	root, err := parseInput(filename)
+	if err != nil {
+		return err
+	}
+
+	comments := parser.Comments(root)
+
+	if commentsJSON {
+		if comments == nil {
+			comments = []parser.Comment{}
+		}
+		out, err := json.MarshalIndent(comments, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, c := range comments {
+		lines := strings.Split(c.Text, "\n")
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", c.Line, c.Path, c.Kind, lines[0])
+		for _, line := range lines[1:] {
+			fmt.Fprintf(w, "\t\t\t%s\n", line)
+		}
+	}
+	return w.Flush()
+}
@@ -1,9 +1,15 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"text/tabwriter"
 
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 	"github.com/simota/yam/internal/diff"
 	"github.com/simota/yam/internal/parser"
 	diffui "github.com/simota/yam/internal/ui/diff"
@@ -12,9 +18,32 @@ import (
 
 var summaryOnly bool
 var diffInteractive bool
+var diffQuiet bool
+var diffPath string
+var diffWordDiff bool
+var diffMaxDepth int
+var diffAgainst string
+var diffMatrix bool
+var diffSetFields []string
+var diffList bool
+var diffJSON bool
+var diffPorcelain string
+var diffDetectReorder bool
+var diffNullIsAbsent bool
+var diffOrder string
+var diffOnly []string
+var diffOnlyTypes []diff.DiffType
+var diffDoc int
+var diffMatchKeys []string
+var diffStream bool
+var diffGit string
+var diffOutput string
+var diffNoSummary bool
+var diffNoHeader bool
+var diffSelf bool
 
 var diffCmd = &cobra.Command{
-	Use:   "diff <file1> <file2>",
+	Use:   "diff <file1> <file2> | diff --against <base> <file>... | diff --git[=REV] <file> | diff --self <file> <path1> <path2>",
 	Short: "Compare two YAML/JSON files",
 	Long: `Compare two YAML or JSON files and show structural differences.
 
@@ -22,7 +51,54 @@ The diff command parses both files and performs a structural comparison,
 showing added, removed, and modified values. File format (YAML or JSON)
 is automatically detected based on file extension.
 
-Exit codes:
+Scalars of the same type are compared by value, not spelling: 3, 3.0 and
+0x3 are the same number, True and true the same boolean, and null, ~ and
+an empty value the same null. A string never equals a number.
+--null-is-absent also treats a null value and a missing key or item as
+equal, to compare a config with explicit defaults against a sparse one.
+
+Files with several documents separated by --- are compared document by
+document: by position, or with --match-key by the values at the given paths
+(e.g. kind,metadata.name for Kubernetes manifests). Each document gets its
+own section and a summary, followed by the totals. --doc N compares only
+the Nth documents (counting from 0).
+
+--stream prints each change as soon as it is found instead of after the
+whole comparison, so output starts early for huge files. The output is the
+same; it works with the default output, --summary and --quiet.
+
+--git compares a file with its version at a git revision (HEAD unless
+given as --git=REV), as read with "git show REV:path", to review local
+edits without saving the old version first.
+
+--self compares two subtrees of one file, e.g. two environments that should
+stay in sync: yam diff --self config.yaml .environments.dev .environments.prod.
+The file is parsed once and the paths label the two sides.
+
+-o FILE writes the output to a file instead of stdout, in any output mode
+and without colors (unless --color always is given). The file is replaced
+atomically, and is left as it was when the comparison fails.
+
+--no-header and --no-summary leave out the --- and +++ lines naming the
+files and the summary after the changes, for output read by other tools.
+
+With --against, each file is compared with a common base and the output
+is labeled per file; --summary prints one line per file and --matrix a
+table of pairwise change counts: the values added, removed or modified
+(and, with --detect-reorder, the reordered mappings), as listed by --list.
+
+--porcelain prints one change per line in a format that is guaranteed not
+to change between releases (version v1):
+
+  STATUS<TAB>PATH<TAB>OLD<TAB>NEW
+
+STATUS is A (added), D (removed), M (modified), T (type changed) or R (key
+order differs, with --detect-reorder). OLD and NEW are compact JSON, empty
+when the side does not exist; for R they are arrays of the keys in order.
+Backslash, tab, CR and LF in PATH are escaped as \\, \t, \r and \n. No
+colors, header or summary are printed.
+
+Exit codes (in every output mode, including --summary and --quiet):
   0  No differences found
   1  Differences found
   2  Error occurred
@@ -30,75 +106,592 @@ Exit codes:
 Examples:
   yam diff config-dev.yaml config-prod.yaml
   yam diff --summary config-dev.yaml config-prod.yaml
+  yam diff -q config-dev.yaml config-prod.yaml && echo same
+  yam diff --path '.spec' old.yaml new.yaml     # Compare only a subtree
+  yam diff --word-diff old.yaml new.yaml        # Highlight changed words
+  yam diff --max-depth 1 old.yaml new.yaml      # Top-level overview only
+  yam diff --set-fields '.tags' old.yaml new.yaml  # Ignore order in .tags
+  yam diff --detect-reorder sorted.yaml unsorted.yaml  # Report key order changes
+  yam diff --order source old.yaml new.yaml    # Keys in the order of new.yaml
+  yam diff --only removed old.yaml new.yaml    # Did we drop a field?
+  yam diff --list old.yaml new.yaml            # TYPE<TAB>PATH<TAB>OLD<TAB>NEW per change
+  yam diff --list --json old.yaml new.yaml     # One JSON object per change
+  yam diff --json old.yaml new.yaml            # Summary and changes as one JSON document
+  yam diff --summary --json old.yaml new.yaml  # Counts, with a breakdown per top-level key
+  yam diff --porcelain old.yaml new.yaml       # Stable format for scripts (see below)
+  yam diff --stream huge-old.yaml huge-new.yaml # Print changes as they are found
+  yam diff --against base.yaml dev.yaml prod.yaml --summary
+  yam diff --match-key kind,metadata.name old-bundle.yaml new-bundle.yaml
+  yam diff --doc 2 old-bundle.yaml new-bundle.yaml  # Third document only
   yam diff -i config-dev.yaml config-prod.yaml  # Interactive TUI mode
-  yam diff config.yaml config.json  # Cross-format comparison`,
-	Args: cobra.ExactArgs(2),
-	RunE: runDiff,
+  yam diff config.yaml config.json  # Cross-format comparison
+  cat new.yaml | yam diff old.yaml -           # Read one side from stdin
+  yam diff https://example.com/base.yaml local.yaml
+  yam diff --git config.yaml                   # Local edits since HEAD
+  yam diff --git=main config.yaml              # Changes since the main branch
+  yam diff --self config.yaml .env.dev .env.prod  # Compare two sections of one file`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if diffAgainst != "" {
+			return withExitCode(exitCodeDiffError, cobra.MinimumNArgs(1)(cmd, args))
+		}
+		if diffGit != "" {
+			return withExitCode(exitCodeDiffError, cobra.ExactArgs(1)(cmd, args))
+		}
+		if diffSelf {
+			return withExitCode(exitCodeDiffError, cobra.ExactArgs(3)(cmd, args))
+		}
+		return withExitCode(exitCodeDiffError, cobra.ExactArgs(2)(cmd, args))
+	},
+	RunE:          runDiff,
+	SilenceUsage:  true,
+	SilenceErrors: true,
 }
 
 func init() {
 	rootCmd.AddCommand(diffCmd)
 	diffCmd.Flags().BoolVarP(&summaryOnly, "summary", "s", false, "Show only summary (no detailed diff)")
 	diffCmd.Flags().BoolVarP(&diffInteractive, "interactive", "i", false, "Interactive TUI mode with split view")
+	diffCmd.Flags().BoolVarP(&diffQuiet, "quiet", "q", false, "Print nothing; only set the exit code")
+	diffCmd.Flags().StringVarP(&diffPath, "path", "p", "", "Compare only the subtree at this path (e.g. '.spec')")
+	diffCmd.Flags().IntVar(&diffMaxDepth, "max-depth", 0, "Compare containers below this depth as a whole (0 = unlimited)")
+	diffCmd.Flags().StringVar(&diffAgainst, "against", "", "Compare each file against this base file")
+	diffCmd.Flags().BoolVar(&diffMatrix, "matrix", false, "With --against, print pairwise change counts for all files")
+	diffCmd.Flags().StringSliceVar(&diffSetFields, "set-fields", nil, "Compare the sequences at these paths as unordered sets (e.g. '.tags,.origins')")
+	diffCmd.Flags().BoolVar(&diffList, "list", false, "Print one tab-separated line per change: TYPE, PATH, OLD, NEW")
+	diffCmd.Flags().BoolVar(&diffJSON, "json", false, "Output as JSON (with --list, one JSON object per line)")
+	diffCmd.Flags().StringVar(&diffPorcelain, "porcelain", "", "Print changes in a stable, script-friendly format (version: v1)")
+	diffCmd.Flags().Lookup("porcelain").NoOptDefVal = diff.PorcelainVersion
+	diffCmd.Flags().StringSliceVar(&diffOnly, "only", nil, "Show only these changes: added, removed, modified, reordered")
+	diffCmd.Flags().StringVar(&diffOrder, "order", "alpha", "Order of mapping keys: alpha, or source (as in the new file)")
+	diffCmd.Flags().BoolVar(&diffDetectReorder, "detect-reorder", false, "Report mappings whose keys appear in a different order")
+	diffCmd.Flags().BoolVar(&diffNullIsAbsent, "null-is-absent", false, "Treat a null value and a missing key or item as equal")
+	diffCmd.Flags().IntVar(&diffDoc, "doc", -1, "Compare only the Nth document of each file, counting from 0 (default: all)")
+	diffCmd.Flags().StringSliceVar(&diffMatchKeys, "match-key", nil, "Pair documents by the values at these paths (e.g. 'kind,metadata.name') instead of by position")
+	diffCmd.Flags().StringVar(&diffGit, "git", "", "Compare the file with its version at this git revision (default HEAD)")
+	diffCmd.Flags().Lookup("git").NoOptDefVal = "HEAD"
+	diffCmd.Flags().StringVarP(&diffOutput, "output", "o", "", "Write the diff to this file instead of stdout (without colors unless --color always)")
+	diffCmd.Flags().BoolVar(&diffStream, "stream", false, "Print changes while comparing instead of after (faster first output for huge files)")
+	diffCmd.Flags().BoolVar(&diffWordDiff, "word-diff", false, "Highlight only the changed words in modified values")
+	diffCmd.Flags().BoolVar(&diffNoHeader, "no-header", false, "Omit the --- and +++ lines naming the files")
+	diffCmd.Flags().BoolVar(&diffNoSummary, "no-summary", false, "Omit the summary line after the changes")
+	diffCmd.Flags().BoolVar(&diffSelf, "self", false, "Compare two subtrees of one file: diff --self <file> <path1> <path2>")
+	diffCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return withExitCode(exitCodeDiffError, err)
+	})
 }
 
 func runDiff(cmd *cobra.Command, args []string) error {
-	file1 := args[0]
-	file2 := args[1]
+	if diffPorcelain != "" && diffPorcelain != diff.PorcelainVersion {
+		return withExitCode(exitCodeDiffError, fmt.Errorf("unsupported --porcelain version %q (supported: %s)", diffPorcelain, diff.PorcelainVersion))
+	}
+	diffOnlyTypes = nil
+	for _, name := range diffOnly {
+		t, err := diff.ParseDiffType(name)
+		if err != nil {
+			return withExitCode(exitCodeDiffError, fmt.Errorf("invalid --only: %w", err))
+		}
+		diffOnlyTypes = append(diffOnlyTypes, t)
+	}
+	if len(diffOnlyTypes) > 0 && (diffInteractive || diffList || diffJSON || diffPorcelain != "") {
+		return withExitCode(exitCodeDiffError, fmt.Errorf("--only applies to the default output and cannot be combined with -i, --list, --json or --porcelain"))
+	}
+
+	if diffNoSummary && summaryOnly {
+		return withExitCode(exitCodeDiffError, fmt.Errorf("--no-summary cannot be combined with --summary"))
+	}
 
-	// Parse both files
-	left, err := parseFile(file1)
+	if diffStream && (diffInteractive || diffList || diffJSON || diffPorcelain != "" || diffAgainst != "") {
+		return withExitCode(exitCodeDiffError, fmt.Errorf("--stream applies to the default output and cannot be combined with -i, --list, --json, --porcelain or --against"))
+	}
+
+	if diffOutput != "" {
+		if diffInteractive {
+			return withExitCode(exitCodeDiffError, fmt.Errorf("--output cannot be combined with -i"))
+		}
+		return writeDiffOutput(diffOutput, args)
+	}
+	return runDiffTo(os.Stdout, args)
+}
+
+// writeDiffOutput writes the diff to filename instead of stdout, without
+// colors unless --color always is given. The file is replaced atomically,
+// also when the files differ; an error leaves it untouched.
+func writeDiffOutput(filename string, args []string) error {
+	if colorMode != "always" {
+		defer lipgloss.SetColorProfile(lipgloss.ColorProfile())
+		lipgloss.SetColorProfile(termenv.Ascii)
+	}
+
+	var status error
+	err := writeFileAtomic(filename, func(w io.Writer) error {
+		status = runDiffTo(w, args)
+		var exitErr *exitError
+		if errors.As(status, &exitErr) && exitErr.err == nil {
+			return nil // Differences found: keep the file, exit with status
+		}
+		return status
+	})
 	if err != nil {
-		return fmt.Errorf("failed to parse %s: %w", file1, err)
+		return err
+	}
+	return status
+}
+
+// runDiffTo compares the files named by args and writes the diff to out
+func runDiffTo(out io.Writer, args []string) error {
+	if diffAgainst != "" {
+		if diffGit != "" {
+			return withExitCode(exitCodeDiffError, fmt.Errorf("--git cannot be combined with --against"))
+		}
+		return runDiffAgainst(out, diffAgainst, args)
+	}
+
+	if diffSelf {
+		if diffGit != "" || diffPath != "" {
+			return withExitCode(exitCodeDiffError, fmt.Errorf("--self cannot be combined with --git or --path"))
+		}
+		return runDiffSelf(out, args[0], args[1], args[2])
+	}
+
+	if err := checkSingleStdin(args); err != nil {
+		return withExitCode(exitCodeDiffError, err)
 	}
 
-	right, err := parseFile(file2)
+	// Parse both files; with --git the old side is the file at a revision
+	var file1, file2 string
+	var leftDocs []*parser.YamNode
+	var err error
+	if diffGit != "" {
+		file2 = args[0]
+		file1, leftDocs, err = parseGitDocuments(diffGit, file2)
+	} else {
+		file1, file2 = args[0], args[1]
+		leftDocs, err = parseDiffDocuments(file1)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to parse %s: %w", file2, err)
+		return err
 	}
 
-	// Compare the two parsed trees
-	result := diff.Compare(left, right)
+	rightDocs, err := parseDiffDocuments(file2)
+	if err != nil {
+		return err
+	}
+
+	if diffDoc < 0 && (len(leftDocs) > 1 || len(rightDocs) > 1 || len(diffMatchKeys) > 0) {
+		if diffStream {
+			return withExitCode(exitCodeDiffError, fmt.Errorf("--stream compares one document; use --doc N with multi-document files"))
+		}
+		return runDiffDocuments(out, file1, file2, leftDocs, rightDocs)
+	}
+	left, err := pickDiffDocument(file1, leftDocs)
+	if err != nil {
+		return err
+	}
+	right, err := pickDiffDocument(file2, rightDocs)
+	if err != nil {
+		return err
+	}
+	return diffPair(out, file1, file2, left, right)
+}
+
+// runDiffSelf compares the subtrees at two paths of one file, which is
+// parsed only once. The paths label the sides in place of file names.
+func runDiffSelf(out io.Writer, filename, leftPath, rightPath string) error {
+	docs, err := parseDiffDocuments(filename)
+	if err != nil {
+		return err
+	}
+	doc, err := pickDiffDocument(filename, docs)
+	if err != nil {
+		return err
+	}
+
+	var sides [2]*parser.YamNode
+	for i, path := range []string{leftPath, rightPath} {
+		if sides[i], err = parser.GetByPath(doc, path); err != nil {
+			return withExitCode(exitCodeDiffError, fmt.Errorf("%s: %w", filename, err))
+		}
+	}
+	return diffPair(out, fmt.Sprintf("%s (%s)", filename, leftPath), fmt.Sprintf("%s (%s)", filename, rightPath), sides[0], sides[1])
+}
+
+// diffPair compares two trees labeled file1 and file2 and prints the
+// result in the selected output mode (or opens the TUI)
+func diffPair(out io.Writer, file1, file2 string, left, right *parser.YamNode) error {
+	if diffStream {
+		return streamDiff(out, file1, file2, left, right)
+	}
+
+	result, left, right, err := compareTrees(left, right)
+	if err != nil {
+		return err
+	}
 	result.LeftFile = file1
 	result.RightFile = file2
 
 	// Interactive TUI mode
 	if diffInteractive {
-		return diffui.Run(result, left, right)
+		return withExitCode(exitCodeDiffError, diffui.Run(result, left, right))
 	}
 
-	// Render output
-	if summaryOnly {
-		fmt.Println(diff.RenderSummary(result.Summary))
-	} else {
-		if result.Summary.Total == 0 {
-			fmt.Println("No differences found.")
-		} else {
-			output := diff.Render(result)
-			fmt.Print(output)
+	if err := printDiff(out, result); err != nil {
+		return err
+	}
+	return diffExitStatus(result)
+}
+
+// runDiffDocuments compares two multi-document files document by document
+// and prints a section per document followed by the totals
+func runDiffDocuments(out io.Writer, file1, file2 string, leftDocs, rightDocs []*parser.YamNode) error {
+	if diffInteractive || diffList || diffJSON || diffPorcelain != "" {
+		return withExitCode(exitCodeDiffError, fmt.Errorf("multi-document files support the default output, --summary and --quiet; use --doc N to compare one document"))
+	}
+
+	pairs, err := diff.PairDocuments(leftDocs, rightDocs, diffMatchKeys)
+	if err != nil {
+		return withExitCode(exitCodeDiffError, err)
+	}
+
+	var total diff.DiffSummary
+	changed := 0
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for i, pair := range pairs {
+		result, _, _, err := compareTrees(pair.Left, pair.Right)
+		if err != nil {
+			return err
+		}
+		result.LeftFile = file1
+		result.RightFile = file2
+		total.Add(result.Summary)
+		if result.Summary.Total > 0 {
+			changed++
+		}
+
+		switch {
+		case diffQuiet:
+			// Exit code only
+		case summaryOnly:
+			fmt.Fprintf(w, "%s:\t%s\n", pair.Label, diff.RenderSummary(result.Summary))
+		default:
+			if i > 0 {
+				fmt.Fprintln(out)
+			}
+			fmt.Fprintf(out, "=== %s%s ===\n", pair.Label, documentSide(pair))
+			if err := printDiff(out, result); err != nil {
+				return err
+			}
 		}
 	}
+	w.Flush()
 
-	// Exit with code 1 if there are differences
-	if result.Summary.Total > 0 {
-		os.Exit(1)
+	if !diffQuiet && !diffNoSummary {
+		if !summaryOnly {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "Documents: %d compared, %d changed\n%s\n", len(pairs), changed, diff.RenderSummary(total))
+	}
+	if total.Total > 0 {
+		return &exitError{code: exitCodeDiffFound}
+	}
+	return nil
+}
+
+// documentSide notes a document that exists in only one file
+func documentSide(pair diff.DocumentPair) string {
+	switch {
+	case pair.Left == nil:
+		return " (added)"
+	case pair.Right == nil:
+		return " (removed)"
+	}
+	return ""
+}
+
+// runDiffAgainst diffs each file against a common base and prints one
+// labeled section per file, a summary line per file, or a matrix
+func runDiffAgainst(out io.Writer, baseFile string, files []string) error {
+	if diffInteractive {
+		return withExitCode(exitCodeDiffError, fmt.Errorf("--interactive cannot be combined with --against"))
+	}
+	if diffPorcelain != "" {
+		return withExitCode(exitCodeDiffError, fmt.Errorf("--porcelain cannot be combined with --against"))
+	}
+
+	allFiles := append([]string{baseFile}, files...)
+	if err := checkSingleStdin(allFiles); err != nil {
+		return withExitCode(exitCodeDiffError, err)
+	}
+	trees := make([]*parser.YamNode, len(allFiles))
+	for i, file := range allFiles {
+		tree, err := parseDiffFile(file)
+		if err != nil {
+			return err
+		}
+		trees[i] = tree
+	}
+
+	// results[i][j] compares allFiles[i] (left) with allFiles[j] (right);
+	// without --matrix only the base row is needed
+	rows := 1
+	if diffMatrix {
+		rows = len(allFiles)
+	}
+	results := make([][]*diff.DiffResult, rows)
+	for i := 0; i < rows; i++ {
+		results[i] = make([]*diff.DiffResult, len(allFiles))
+		for j := range allFiles {
+			if i == j {
+				continue
+			}
+			result, _, _, err := compareTrees(trees[i], trees[j])
+			if err != nil {
+				return err
+			}
+			result.LeftFile = allFiles[i]
+			result.RightFile = allFiles[j]
+			results[i][j] = result
+		}
+	}
+
+	switch {
+	case diffQuiet:
+		// Exit code only
+	case diffMatrix:
+		printDiffMatrix(out, allFiles, results)
+	case summaryOnly:
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		for _, result := range results[0][1:] {
+			fmt.Fprintf(w, "%s:\t%s\n", result.RightFile, diff.RenderSummary(result.Summary))
+		}
+		w.Flush()
+	default:
+		for i, result := range results[0][1:] {
+			if i > 0 {
+				fmt.Fprintln(out)
+			}
+			fmt.Fprintf(out, "=== %s (against %s) ===\n", result.RightFile, baseFile)
+			if err := printDiff(out, result); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, result := range results[0][1:] {
+		if err := diffExitStatus(result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printDiffMatrix prints the number of changes between every pair of files,
+// with the left file as the row and the right file as the column. Changes
+// are counted as --list shows them, so a changed value counts once and not
+// again for each mapping or sequence it is in.
+func printDiffMatrix(out io.Writer, files []string, results [][]*diff.DiffResult) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprint(w, "\t"+strings.Join(files, "\t")+"\n")
+	for i, row := range results {
+		cells := []string{files[i]}
+		for j, result := range row {
+			if i == j {
+				cells = append(cells, "-")
+				continue
+			}
+			cells = append(cells, fmt.Sprintf("%d", len(diff.NewJSONResult(result).Changes)))
+		}
+		fmt.Fprint(w, strings.Join(cells, "\t")+"\n")
+	}
+	w.Flush()
+}
+
+// compareTrees narrows both trees to --path (if set) and compares them.
+// The narrowed trees are returned for the interactive view.
+func compareTrees(left, right *parser.YamNode) (*diff.DiffResult, *parser.YamNode, *parser.YamNode, error) {
+	opts, left, right, err := diffSetup(left, right)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return diff.CompareWithOptions(left, right, opts), left, right, nil
+}
+
+// diffSetup returns the comparison options from the flags and both trees
+// narrowed to --path (if set)
+func diffSetup(left, right *parser.YamNode) (diff.Options, *parser.YamNode, *parser.YamNode, error) {
+	opts := diff.DefaultOptions()
+	opts.MaxDepth = diffMaxDepth
+	opts.DetectReorder = diffDetectReorder
+	opts.NullIsAbsent = diffNullIsAbsent
+	order, err := diff.ParseKeyOrder(diffOrder)
+	if err != nil {
+		return opts, nil, nil, withExitCode(exitCodeDiffError, err)
+	}
+	opts.Order = order
+	for _, field := range diffSetFields {
+		if _, err := parser.ParsePath(field); err != nil {
+			return opts, nil, nil, withExitCode(exitCodeDiffError, fmt.Errorf("invalid --set-fields path %q: %w", field, err))
+		}
+		opts.SetFields = append(opts.SetFields, diffRootPath(field))
+	}
+	if diffPath != "" {
+		left, right, err = selectDiffPath(left, right, diffPath)
+		if err != nil {
+			return opts, nil, nil, withExitCode(exitCodeDiffError, err)
+		}
+		opts.RootPath = diffRootPath(diffPath)
+	}
+	return opts, left, right, nil
+}
+
+// streamDiff compares two trees with diff.Stream, printing each change as
+// soon as it is found
+func streamDiff(out io.Writer, file1, file2 string, left, right *parser.YamNode) error {
+	opts, left, right, err := diffSetup(left, right)
+	if err != nil {
+		return err
+	}
+	ropts := diff.DefaultRenderOptions()
+	ropts.WordDiff = diffWordDiff
+	ropts.Only = diffOnlyTypes
+	ropts.NoHeader = diffNoHeader
+	ropts.NoSummary = diffNoSummary
+
+	w := out
+	if diffQuiet || summaryOnly {
+		w = io.Discard
+	}
+	summary, err := diff.Stream(w, left, right, file1, file2, opts, ropts)
+	if err != nil {
+		return withExitCode(exitCodeDiffError, fmt.Errorf("failed to write diff: %w", err))
 	}
 
+	switch {
+	case diffQuiet:
+	case summaryOnly:
+		fmt.Fprintln(out, diff.RenderSummary(summary))
+	case summary.Total == 0:
+		fmt.Fprintln(out, "No differences found.")
+	}
+	if summary.Total > 0 {
+		return &exitError{code: exitCodeDiffFound}
+	}
+	return nil
+}
+
+// printDiff writes the diff result in the selected output mode
+func printDiff(w io.Writer, result *diff.DiffResult) error {
+	switch {
+	case diffQuiet:
+		// Exit code only
+	case diffPorcelain != "":
+		fmt.Fprint(w, diff.RenderPorcelain(result))
+	case diffList && diffJSON:
+		out, err := diff.RenderListJSON(result)
+		if err != nil {
+			return withExitCode(exitCodeDiffError, fmt.Errorf("failed to encode JSON: %w", err))
+		}
+		fmt.Fprint(w, out)
+	case diffList:
+		fmt.Fprint(w, diff.RenderList(result))
+	case summaryOnly && diffJSON:
+		out, err := diff.MarshalSummaryJSON(result.Summary)
+		if err != nil {
+			return withExitCode(exitCodeDiffError, fmt.Errorf("failed to encode JSON: %w", err))
+		}
+		fmt.Fprintln(w, string(out))
+	case diffJSON:
+		out, err := diff.MarshalJSON(result)
+		if err != nil {
+			return withExitCode(exitCodeDiffError, fmt.Errorf("failed to encode JSON: %w", err))
+		}
+		fmt.Fprintln(w, string(out))
+	case summaryOnly:
+		fmt.Fprintln(w, diff.RenderSummary(result.Summary))
+	case result.Summary.Total == 0:
+		fmt.Fprintln(w, "No differences found.")
+	default:
+		opts := diff.DefaultRenderOptions()
+		opts.WordDiff = diffWordDiff
+		opts.Only = diffOnlyTypes
+		opts.NoHeader = diffNoHeader
+		opts.NoSummary = diffNoSummary
+		fmt.Fprint(w, diff.RenderWith(result, opts))
+	}
 	return nil
 }
 
-// parseFile opens and parses a file, detecting format from extension
-func parseFile(filename string) (*parser.YamNode, error) {
-	f, err := os.Open(filename)
+// diffExitStatus is the single place that maps a diff result to the
+// command's exit status: nil when identical, exit code 1 otherwise
+func diffExitStatus(result *diff.DiffResult) error {
+	if result.Summary.Total > 0 {
+		return &exitError{code: exitCodeDiffFound}
+	}
+	return nil
+}
+
+// selectDiffPath resolves path in both trees. A side where the path does not
+// exist becomes nil, so its subtree is reported as added or removed.
+func selectDiffPath(left, right *parser.YamNode, path string) (*parser.YamNode, *parser.YamNode, error) {
+	if _, err := parser.ParsePath(path); err != nil {
+		return nil, nil, fmt.Errorf("invalid path: %w", err)
+	}
+
+	leftSub, leftErr := getDiffSide(left, path)
+	rightSub, rightErr := getDiffSide(right, path)
+	if leftErr != nil && rightErr != nil {
+		return nil, nil, fmt.Errorf("path %s not found in either file: %w", path, leftErr)
+	}
+	return leftSub, rightSub, nil
+}
+
+// getDiffSide resolves path in one side of a diff; a missing side (a
+// document that exists in only one file) has no nodes at any path
+func getDiffSide(tree *parser.YamNode, path string) (*parser.YamNode, error) {
+	if tree == nil {
+		return nil, fmt.Errorf("%w: %s", parser.ErrPathNotFound, path)
+	}
+	return parser.GetByPath(tree, path)
+}
+
+// diffRootPath converts a query path like ".spec.items[0]" to the
+// JSONPath-like form used in diff output ("$.spec.items[0]")
+func diffRootPath(path string) string {
+	if path == "." {
+		return "$"
+	}
+	return "$" + path
+}
+
+// parseDiffFile parses a file, URL or stdin ("-") for diff and picks the
+// document to compare, mapping failures to exit code 2
+func parseDiffFile(filename string) (*parser.YamNode, error) {
+	docs, err := parseDiffDocuments(filename)
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
+	return pickDiffDocument(filename, docs)
+}
 
-	p := parser.New()
+// parseDiffDocuments parses every document of a file for diff, mapping
+// failures to exit code 2
+func parseDiffDocuments(filename string) ([]*parser.YamNode, error) {
+	docs, err := parseInputDocuments(filename)
+	if err != nil {
+		return nil, withExitCode(exitCodeDiffError, fmt.Errorf("failed to parse %s: %w", filename, err))
+	}
+	return docs, nil
+}
 
-	if isJSONFile(filename) {
-		return p.ParseJSON(f)
+// pickDiffDocument returns the document selected with --doc, or the only
+// document of a single-document file
+func pickDiffDocument(filename string, docs []*parser.YamNode) (*parser.YamNode, error) {
+	switch {
+	case diffDoc >= len(docs):
+		return nil, withExitCode(exitCodeDiffError, fmt.Errorf("%s has no document %d (it has %d)", filename, diffDoc, len(docs)))
+	case diffDoc >= 0:
+		return docs[diffDoc], nil
+	case len(docs) > 1:
+		return nil, withExitCode(exitCodeDiffError, fmt.Errorf("%s has %d documents; use --doc N to choose one", filename, len(docs)))
 	}
-	return p.Parse(f)
+	return docs[0], nil
 }
@@ -0,0 +1,364 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// captureStdout runs fn with os.Stdout redirected and returns what was written
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	orig := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = orig }()
+
+	done := make(chan string)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- string(out)
+	}()
+
+	fn()
+	w.Close()
+	return <-done
+}
+
+func TestDiffExitCodes(t *testing.T) {
+	dev := filepath.Join("..", "testdata", "config-dev.yaml")
+	prod := filepath.Join("..", "testdata", "config-prod.yaml")
+	missing := filepath.Join("..", "testdata", "does-not-exist.yaml")
+
+	tests := []struct {
+		name     string
+		args     []string
+		summary  bool
+		quiet    bool
+		wantCode int
+		wantOut  bool
+	}{
+		{"identical", []string{dev, dev}, false, false, 0, true},
+		{"different", []string{dev, prod}, false, false, 1, true},
+		{"different summary", []string{dev, prod}, true, false, 1, true},
+		{"different quiet", []string{dev, prod}, false, true, 1, false},
+		{"identical quiet", []string{dev, dev}, false, true, 0, false},
+		{"missing file", []string{dev, missing}, false, false, 2, false},
+		{"missing file quiet", []string{missing, dev}, false, true, 2, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summaryOnly, diffQuiet = tt.summary, tt.quiet
+			defer func() { summaryOnly, diffQuiet = false, false }()
+
+			var err error
+			out := captureStdout(t, func() {
+				err = runDiff(diffCmd, tt.args)
+			})
+
+			if code := ExitCode(err); code != tt.wantCode {
+				t.Errorf("expected exit code %d, got %d (err: %v)", tt.wantCode, code, err)
+			}
+			if gotOut := out != ""; gotOut != tt.wantOut {
+				t.Errorf("expected output=%v, got %q", tt.wantOut, out)
+			}
+		})
+	}
+}
+
+func TestDiffAgainst(t *testing.T) {
+	dev := filepath.Join("..", "testdata", "config-dev.yaml")
+	prod := filepath.Join("..", "testdata", "config-prod.yaml")
+
+	diffAgainst, summaryOnly = dev, true
+	defer func() { diffAgainst, summaryOnly = "", false }()
+
+	var err error
+	out := captureStdout(t, func() {
+		err = runDiff(diffCmd, []string{prod, dev})
+	})
+
+	if code := ExitCode(err); code != 1 {
+		t.Errorf("expected exit code 1, got %d (err: %v)", code, err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], prod+":") || !strings.Contains(lines[1], "no changes") {
+		t.Errorf("expected one summary line per file, got:\n%s", out)
+	}
+}
+
+func TestDiffAgainst_Matrix(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"d1.yaml": "a: {b: 1, c: 1}\n",
+		"d2.yaml": "a: {b: 2, c: 1}\n",
+		"d3.yaml": "a: {b: 3, c: 3}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+
+	diffAgainst, diffMatrix = filepath.Join(dir, "d1.yaml"), true
+	defer func() { diffAgainst, diffMatrix = "", false }()
+	out := captureStdout(t, func() {
+		_ = runDiff(diffCmd, []string{filepath.Join(dir, "d2.yaml"), filepath.Join(dir, "d3.yaml")})
+	})
+
+	// The mapping holding a changed value is not counted as a change
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 4 || strings.Join(strings.Fields(lines[2])[1:], " ") != "1 - 2" {
+		t.Errorf("expected the d2 row to count 1 and 2 changes, got:\n%s", out)
+	}
+}
+
+func TestDiffGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	if err := os.WriteFile(file, []byte("port: 80\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	git("add", "config.yaml")
+	git("commit", "-q", "-m", "initial")
+	if err := os.WriteFile(file, []byte("port: 8080\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	diffGit, diffList = "HEAD", true
+	defer func() { diffGit, diffList = "", false }()
+
+	var err error
+	out := captureStdout(t, func() {
+		err = runDiff(diffCmd, []string{file})
+	})
+	if code := ExitCode(err); code != 1 {
+		t.Errorf("expected exit code 1, got %d (err: %v)", code, err)
+	}
+	if !strings.Contains(out, "$.port\t80\t8080") {
+		t.Errorf("expected the change from HEAD, got:\n%s", out)
+	}
+
+	diffGit = "no-such-rev"
+	if err := runDiff(diffCmd, []string{file}); ExitCode(err) != 2 {
+		t.Errorf("expected exit code 2 for an unknown revision, got %v", err)
+	}
+
+	// A revision must not reach git as an option. git appends ":./file",
+	// so with this directory --output would name a path it can write.
+	written := filepath.Join(dir, "written")
+	_ = os.Mkdir(written+":.", 0o755)
+	diffGit = "--output=" + written
+	if err := runDiff(diffCmd, []string{file}); ExitCode(err) != 2 {
+		t.Errorf("expected exit code 2 for an option as revision, got %v", err)
+	}
+	if _, err := os.Stat(written + ":./config.yaml"); err == nil {
+		t.Errorf("expected git not to write a file")
+	}
+}
+
+func TestDiffList(t *testing.T) {
+	dev := filepath.Join("..", "testdata", "config-dev.yaml")
+	prod := filepath.Join("..", "testdata", "config-prod.yaml")
+
+	diffList = true
+	defer func() { diffList = false }()
+
+	var err error
+	out := captureStdout(t, func() {
+		err = runDiff(diffCmd, []string{dev, prod})
+	})
+
+	if code := ExitCode(err); code != 1 {
+		t.Errorf("expected exit code 1, got %d (err: %v)", code, err)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		if fields := strings.Split(line, "\t"); len(fields) != 4 || !strings.HasPrefix(fields[1], "$.") {
+			t.Errorf("expected TYPE, PATH, OLD, NEW fields, got %q", line)
+		}
+	}
+	if !strings.Contains(out, "modified\t$.database.host\tlocalhost\tdb.prod.example.com\n") {
+		t.Errorf("expected the database.host change, got:\n%s", out)
+	}
+}
+
+func TestDiffSelf(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	content := "environments:\n  dev: {host: localhost, port: 80, debug: true}\n  prod: {host: db, port: 80}\n  qa: {host: db, port: 80}\n"
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	diffSelf, diffList = true, true
+	defer func() { diffSelf, diffList = false, false }()
+
+	var err error
+	out := captureStdout(t, func() {
+		err = runDiff(diffCmd, []string{file, ".environments.dev", ".environments.prod"})
+	})
+	if code := ExitCode(err); code != 1 {
+		t.Errorf("expected exit code 1, got %d (err: %v)", code, err)
+	}
+	if out != "removed\t$.debug\ttrue\t\nmodified\t$.host\tlocalhost\tdb\n" {
+		t.Errorf("unexpected changes:\n%s", out)
+	}
+
+	if err := runDiff(diffCmd, []string{file, ".environments.prod", ".environments.qa"}); err != nil {
+		t.Errorf("expected identical subtrees, got %v", err)
+	}
+	if err := runDiff(diffCmd, []string{file, ".environments.dev", ".environments.stage"}); ExitCode(err) != 2 {
+		t.Errorf("expected exit code 2 for a missing path, got %v", err)
+	}
+}
+
+func TestDiffOutput(t *testing.T) {
+	dev := filepath.Join("..", "testdata", "config-dev.yaml")
+	prod := filepath.Join("..", "testdata", "config-prod.yaml")
+	file := filepath.Join(t.TempDir(), "changes.diff")
+
+	// Colors would be on for a terminal; the file must not get them
+	defer lipgloss.SetColorProfile(lipgloss.ColorProfile())
+	lipgloss.SetColorProfile(termenv.TrueColor)
+
+	diffOutput = file
+	defer func() { diffOutput = "" }()
+
+	var err error
+	out := captureStdout(t, func() {
+		err = runDiff(diffCmd, []string{dev, prod})
+	})
+	if code := ExitCode(err); code != 1 {
+		t.Errorf("expected exit code 1, got %d (err: %v)", code, err)
+	}
+	if out != "" {
+		t.Errorf("expected nothing on stdout, got:\n%s", out)
+	}
+	written, readErr := os.ReadFile(file)
+	if readErr != nil {
+		t.Fatalf("failed to read output: %v", readErr)
+	}
+	if !strings.Contains(string(written), "database") || strings.Contains(string(written), "\x1b[") {
+		t.Errorf("expected the diff without colors, got:\n%q", written)
+	}
+
+	// An error leaves the previous output in place
+	err = runDiff(diffCmd, []string{dev, "missing.yaml"})
+	if code := ExitCode(err); code != 2 {
+		t.Errorf("expected exit code 2 for a missing file, got %d (err: %v)", code, err)
+	}
+	if after, _ := os.ReadFile(file); string(after) != string(written) {
+		t.Errorf("expected the output file to be kept on error, got:\n%s", after)
+	}
+}
+
+func TestColorMode(t *testing.T) {
+	dev := filepath.Join("..", "testdata", "config-dev.yaml")
+	prod := filepath.Join("..", "testdata", "config-prod.yaml")
+	file := filepath.Join(t.TempDir(), "changes.diff")
+
+	defer lipgloss.SetColorProfile(lipgloss.ColorProfile())
+	defer func() { colorMode, diffOutput = "auto", "" }()
+
+	// Tests do not run on a terminal: always must still color the file
+	colorMode = "always"
+	if err := applyColorMode(rootCmd, nil); err != nil {
+		t.Fatalf("applyColorMode failed: %v", err)
+	}
+	if lipgloss.ColorProfile() == termenv.Ascii {
+		t.Error("expected colors with --color always")
+	}
+	diffOutput = file
+	if err := runDiff(diffCmd, []string{dev, prod}); ExitCode(err) != 1 {
+		t.Fatalf("expected exit code 1, got %v", err)
+	}
+	if written, _ := os.ReadFile(file); !strings.Contains(string(written), "\x1b[") {
+		t.Errorf("expected colors in the output file, got:\n%q", written)
+	}
+
+	colorMode = "never"
+	if err := applyColorMode(rootCmd, nil); err != nil {
+		t.Fatalf("applyColorMode failed: %v", err)
+	}
+	if lipgloss.ColorProfile() != termenv.Ascii {
+		t.Error("expected no colors with --color never")
+	}
+
+	colorMode = "sometimes"
+	if err := applyColorMode(rootCmd, nil); err == nil {
+		t.Error("expected an error for an unknown color mode")
+	}
+}
+
+func TestDiffDocuments(t *testing.T) {
+	dir := t.TempDir()
+	oldFile := filepath.Join(dir, "old.yaml")
+	newFile := filepath.Join(dir, "new.yaml")
+	if err := os.WriteFile(oldFile, []byte("kind: A\nv: 1\n---\nkind: B\nv: 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(newFile, []byte("kind: B\nv: 2\n---\nkind: A\nv: 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		doc      int
+		keys     []string
+		against  bool
+		wantCode int
+		wantOut  string
+	}{
+		{"by position", -1, nil, false, 1, "Documents: 2 compared, 2 changed"},
+		{"by key", -1, []string{"kind"}, false, 0, "Documents: 2 compared, 0 changed"},
+		{"one document", 1, nil, false, 1, "Summary:"},
+		{"document out of range", 2, nil, false, 2, ""},
+		{"against needs --doc", -1, nil, true, 2, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diffDoc, diffMatchKeys, summaryOnly = tt.doc, tt.keys, true
+			if tt.against {
+				diffAgainst = oldFile
+			}
+			defer func() { diffDoc, diffMatchKeys, summaryOnly, diffAgainst = -1, nil, false, "" }()
+
+			args := []string{oldFile, newFile}
+			if tt.against {
+				args = []string{newFile}
+			}
+			var err error
+			out := captureStdout(t, func() {
+				err = runDiff(diffCmd, args)
+			})
+
+			if code := ExitCode(err); code != tt.wantCode {
+				t.Errorf("expected exit code %d, got %d (err: %v)", tt.wantCode, code, err)
+			}
+			if !strings.Contains(out, tt.wantOut) {
+				t.Errorf("expected %q in output, got:\n%s", tt.wantOut, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/simota/yam/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dupsMinCount int
+	dupsSubtrees bool
+	dupsJSON     bool
+)
+
+// dupsValueWidth is the width at which long values are cut in the listing
+const dupsValueWidth = 60
+
+var dupsCmd = &cobra.Command{
+	Use:   "dups [file]",
+	Short: "Find values repeated at several paths",
+	Long: `List scalar values that appear more than once, with every path that
+holds them, most repeated first. Repeated URLs, ports or magic numbers are
+candidates for an anchor or a single setting.
+
+Each occurrence is listed with its path (e.g. $.hosts[0]) and line.
+
+Values are compared with their type, so 8080 and "8080" differ; strings
+are printed quoted to tell them apart, and --json gives each scalar's type.
+Nulls, booleans and empty strings are not reported, and aliases are skipped
+since they are already shared.
+
+Use --subtrees to also report mappings and sequences with the same content
+(compared like yam hash); values inside a repeated subtree are then not
+listed separately.
+
+Examples:
+  yam dups config.yaml
+  yam dups --min-count 3 config.yaml
+  yam dups --subtrees docker-compose.yaml
+  yam dups --json config.yaml`,
+	Args:          cobra.MaximumNArgs(1),
+	RunE:          runDups,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+func init() {
+	rootCmd.AddCommand(dupsCmd)
+	dupsCmd.Flags().IntVar(&dupsMinCount, "min-count", 2, "Only report values that appear at least this many times")
+	dupsCmd.Flags().BoolVar(&dupsSubtrees, "subtrees", false, "Also report repeated mappings and sequences")
+	dupsCmd.Flags().BoolVar(&dupsJSON, "json", false, "Output as JSON")
+}
+
+func runDups(cmd *cobra.Command, args []string) error {
+	filename := ""
+	if len(args) == 1 {
+		filename = args[0]
+	}
+	root, err := parseInput(filename)
+	if err != nil {
+		return err
+	}
+
+	dups := parser.FindDuplicates(root, dupsMinCount, dupsSubtrees)
+
+	if dupsJSON {
+		if dups == nil {
+			dups = []parser.Duplicate{}
+		}
+		out, err := json.MarshalIndent(dups, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, d := range dups {
+		fmt.Fprintf(w, "%s\t%d occurrences\n", dupsValue(d), d.Count)
+		for _, loc := range d.Locations {
+			fmt.Fprintf(w, "  %s\tline %d\n", loc.Path, loc.Line)
+		}
+	}
+	return w.Flush()
+}
+
+// dupsValue writes a scalar in its typed form, quoting strings so that 3
+// and "3" and surrounding spaces are told apart, and cuts long values
+func dupsValue(d parser.Duplicate) string {
+	value := d.Value
+	if runes := []rune(value); len(runes) > dupsValueWidth {
+		value = string(runes[:dupsValueWidth-1]) + "…"
+	}
+	if d.Type == parser.TypeString.String() {
+		return strconv.Quote(value)
+	}
+	return value
+}
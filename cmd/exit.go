@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/simota/yam/internal/parser"
+)
+
+// Process exit codes
+const (
+	exitCodeError     = 1 // Generic failure
+	exitCodeDiffFound = 1 // yam diff: differences found
+	exitCodeDiffError = 2 // yam diff: error occurred
+	exitCodeLintFound = 1 // yam lint: errors found
+	exitCodeFmtCheck  = 1 // yam fmt --check: input not formatted
+
+	exitCodePathNotFound = 3 // yam: the queried path does not exist
+	exitCodeParseError   = 4 // yam: the input is not valid YAML or JSON
+)
+
+// exitError carries a process exit code through cobra's error return.
+// A nil err means the command should exit silently with code.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return ""
+	}
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// withExitCode wraps err so that the process exits with code
+func withExitCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitError{code: code, err: err}
+}
+
+// classifyError gives the root command's errors their exit code: 3 when the
+// queried path does not exist and 4 when the input does not parse. Other
+// errors, and every error of a subcommand, exit with their own code or 1.
+func classifyError(err error) error {
+	var exitErr *exitError
+	switch {
+	case err == nil || errors.As(err, &exitErr):
+		return err
+	case errors.Is(err, parser.ErrPathNotFound):
+		return withExitCode(exitCodePathNotFound, err)
+	case parser.IsSyntaxError(err):
+		return withExitCode(exitCodeParseError, err)
+	}
+	return err
+}
+
+// ExitCode returns the process exit code for an error returned by Execute
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return exitCodeError
+}
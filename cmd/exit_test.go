@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExitCode_Classification(t *testing.T) {
+	dev := filepath.Join("..", "testdata", "config-dev.yaml")
+	bad := filepath.Join(t.TempDir(), "bad.yaml")
+	if err := os.WriteFile(bad, []byte("a: [1\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+	}{
+		{"found", []string{".app", dev}, 0},
+		{"missing key", []string{".no.such.key", dev}, exitCodePathNotFound},
+		{"index out of bounds", []string{".app[99]", dev}, exitCodePathNotFound},
+		{"malformed yaml", []string{bad}, exitCodeParseError},
+		{"missing file", []string{filepath.Join("..", "testdata", "does-not-exist.yaml")}, exitCodeError},
+		{"invalid path syntax", []string{".a[x]", dev}, exitCodeError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadPathArgs(tt.args)
+			if code := ExitCode(classifyError(err)); code != tt.wantCode {
+				t.Errorf("expected exit code %d, got %d (err: %v)", tt.wantCode, code, err)
+			}
+		})
+	}
+}
+
+func TestExitCode_Subcommand(t *testing.T) {
+	bad := filepath.Join(t.TempDir(), "bad.yaml")
+	if err := os.WriteFile(bad, []byte("a: [1\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	// Subcommands document 1 for any error; 4 is only the root command's
+	if code := ExitCode(runFmt(fmtCmd, []string{bad})); code != exitCodeError {
+		t.Errorf("expected exit code %d, got %d", exitCodeError, code)
+	}
+}
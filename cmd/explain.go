@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/simota/yam/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+var explainJSON bool
+
+var explainCmd = &cobra.Command{
+	Use:   "explain <path> [file]",
+	Short: "Show how yam interprets the value at a path",
+	Long: `Show the raw value, tag, inferred type, and position of the node at path,
+together with the reason yam treats it the way it does.
+
+Useful for debugging why a value behaves as a string or a number.
+
+Examples:
+  yam explain '.port' config.yaml
+  yam explain '.features.enabled' config.yaml --json
+  cat config.yaml | yam explain '.version'`,
+	Args:          cobra.RangeArgs(1, 2),
+	RunE:          runExplain,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+	explainCmd.Flags().BoolVar(&explainJSON, "json", false, "Output as JSON")
+}
+
+func runExplain(cmd *cobra.Command, args []string) error {
+	node, err := loadPathArgs(args)
+	if err != nil {
+		return err
+	}
+
+	e := parser.Explain(node)
+
+	if explainJSON {
+		out, err := json.MarshalIndent(e, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	printField := func(name, value string) {
+		if value != "" {
+			fmt.Printf("%-10s %s\n", name+":", value)
+		}
+	}
+
+	printField("path", e.Path)
+	printField("kind", e.Kind)
+	if e.Value != nil {
+		fmt.Printf("%-10s %q\n", "value:", *e.Value)
+	}
+	printField("tag", e.Tag)
+	printField("style", e.Style)
+	printField("type", e.Type)
+	printField("position", fmt.Sprintf("line %d, column %d", e.Line, e.Column))
+	if e.Unquoted != nil {
+		safety := "needs quoting"
+		if *e.Unquoted {
+			safety = "safe"
+		}
+		printField("unquoted", safety)
+	}
+	printField("reason", e.Reason)
+	return nil
+}
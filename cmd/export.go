@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/simota/yam/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+var exportFormat string
+
+var exportCmd = &cobra.Command{
+	Use:   "export [path] [file]",
+	Short: "Export YAML/JSON to other configuration formats",
+	Long: `Export a YAML or JSON document (or the subtree at path) to another format.
+
+Formats:
+  env   dotenv KEY=value lines; keys are UPPER_SNAKE paths joined by "__"
+        (e.g. DATABASE__HOST=localhost, ITEMS__0=first)
+  properties
+        Java .properties key=value lines; keys are paths joined by "."
+        (e.g. database.host=localhost, items.0=first), with =, :, # and !
+        escaped and non-ASCII characters written as \uXXXX
+  csv   one row per element of a sequence of mappings, with a header row of
+        the union of keys; nested values are JSON-encoded
+
+Aliases are exported as the values they refer to, and merge keys (<<) are
+applied: merged entries appear under the mapping that merges them, unless
+it sets the same key itself.
+
+Examples:
+  yam export --format env config.yaml
+  yam export --format env '.database' config.yaml
+  yam export --format properties config.yaml
+  yam export --format csv '.users' users.yaml
+  cat config.yaml | yam export --format env`,
+	Args:          cobra.MaximumNArgs(2),
+	RunE:          runExport,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "", "Output format: env, properties, csv")
+	_ = exportCmd.MarkFlagRequired("format")
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	root, err := loadPathArgs(args)
+	if err != nil {
+		return err
+	}
+
+	switch exportFormat {
+	case "env":
+		fmt.Print(parser.ToEnv(root))
+	case "properties":
+		fmt.Print(parser.ToProperties(root))
+	case "csv":
+		if err := parser.ToCSV(root, os.Stdout); err != nil {
+			return fmt.Errorf("failed to export CSV: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported export format: %s", exportFormat)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/simota/yam/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+var (
+	flattenJSON  bool
+	flattenTypes []string
+)
+
+var flattenCmd = &cobra.Command{
+	Use:   "flatten [path] [file]",
+	Short: "Print every leaf value with its full path",
+	Long: `Print every leaf of a YAML/JSON document (or of the subtree at a path) on
+its own line as "path: value", with the path in JSONPath notation from the
+document root. The output is easy to grep and to diff.
+
+  $.spec.containers[0].image: nginx:1.25
+
+Empty mappings and sequences are printed as {} and []. Strings that are
+empty, contain line breaks or tabs, or start or end with spaces are written
+as JSON strings so that each leaf stays on one line.
+
+Aliases are expanded into the leaves they refer to, under the alias's own
+path, and merge keys (<<) are applied. Only an alias inside the node it
+refers to is printed as *name.
+
+Use --json to print a flat JSON object of path/value pairs instead.
+
+--type keeps only the scalars of the given inferred types (string, number,
+bool, null, timestamp, binary), e.g. to review every numeric setting at
+once. Empty mappings and sequences are left out when filtering.
+
+Examples:
+  yam flatten config.yaml
+  yam flatten config.yaml | grep 'image'
+  yam flatten .spec deployment.yaml
+  yam flatten --json config.yaml
+  yam flatten --type number config.yaml   # Every numeric setting`,
+	Args:          cobra.MaximumNArgs(2),
+	RunE:          runFlatten,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+func init() {
+	rootCmd.AddCommand(flattenCmd)
+	flattenCmd.Flags().BoolVar(&flattenJSON, "json", false, "Print a flat JSON object mapping each path to its value")
+	flattenCmd.Flags().StringSliceVar(&flattenTypes, "type", nil, "Print only scalars of these types: string, number, bool, null, timestamp, binary")
+}
+
+func runFlatten(cmd *cobra.Command, args []string) error {
+	var types []parser.ScalarType
+	for _, name := range flattenTypes {
+		t, err := parser.ParseScalarType(name)
+		if err != nil {
+			return err
+		}
+		types = append(types, t)
+	}
+
+	node, err := loadPathArgs(args)
+	if err != nil {
+		return err
+	}
+
+	if flattenJSON {
+		out, err := parser.ToFlatJSON(node, true, types...)
+		if err != nil {
+			return fmt.Errorf("failed to convert to JSON: %w", err)
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	fmt.Print(parser.ToFlat(node, types...))
+	return nil
+}
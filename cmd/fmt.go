@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"os"
-	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/simota/yam/internal/parser"
 	"github.com/spf13/cobra"
@@ -14,33 +17,109 @@ var (
 	fmtWriteInPlace bool
 	fmtIndent       int
 	fmtSortKeys     bool
+	fmtFlowWidth    int
+	fmtBlankLines   bool
+	fmtTimestamps   bool
+	fmtTabs         bool
+	fmtBools        bool
+	fmtFixUnused    bool
+	fmtNullStyle    string
+	fmtTransforms   []string
+	fmtTo           string
+	fmtAlign        bool
+	fmtVerify       bool
+	fmtInclude      []string
+	fmtIndentSeqs   bool
+	fmtExclude      []string
+	fmtCheck        bool
+	fmtNoComments   bool
+	fmtDocMarkers   bool
+	fmtWrap         int
+	fmtKeepQuotes   bool
 )
 
+// errNotFormatted is returned by formatInput with --check when the input
+// differs from its formatted output
+var errNotFormatted = errors.New("not formatted")
+
 var fmtCmd = &cobra.Command{
-	Use:   "fmt [file]",
+	Use:   "fmt [file|dir]",
 	Short: "Format YAML files",
 	Long: `Format YAML files with consistent styling.
 
 Reads YAML from a file or stdin and outputs formatted YAML.
 By default, output goes to stdout. Use -w to overwrite the input file.
 
+Given a directory, fmt formats every *.yaml and *.yml file below it in
+place (-w or --check is required). --include and --exclude choose the
+files by glob: a pattern without "/" matches file names, one with "/"
+matches paths relative to the directory, and "**" matches any number of
+directories. Patterns in a .yamignore file at the top of the directory are
+excluded as well, one per line. Hidden directories such as .git are
+skipped.
+
+--check writes nothing and exits with status 1 if any input is not
+formatted, listing the files that would change when given a directory.
+Add --ignore-comments to compare only the data-bearing lines, so inputs
+whose comments alone would be reformatted still pass.
+
+Files with a .json extension are formatted as pretty-printed JSON (keys
+sorted, indented with --indent or --tabs); the YAML-specific options do not
+apply to them. Use --to to choose the output format explicitly, e.g. to
+convert between YAML and JSON.
+
 Formatting includes:
-  - Consistent indentation (default: 2 spaces)
+  - Consistent indentation (default: 2 spaces, or tabs with --tabs)
+  - Sequence dashes indented below their key; --indent-sequences=false
+    writes them at the key's indentation instead ("key:\n- item")
   - Trailing whitespace removal
-  - Normalized quoting (unquoted when safe)
+  - Normalized quoting (unquoted when safe; --preserve-quotes keeps the
+    quotes the author wrote)
   - Final newline ensured
+  - %YAML and %TAG directives and ---/... document markers kept
+  - Optionally: the document always started with --- and ended with ...
+    (--document-markers)
   - Optionally: alphabetically sorted keys (--sort-keys)
+  - Optionally: short containers in flow style (--flow-width)
+  - Optionally: blank lines between entries kept (--preserve-blank-lines)
+  - Optionally: timestamps rewritten to RFC 3339 (--normalize-timestamps)
+  - Optionally: booleans rewritten to true/false, and strings such as NO or
+    yes quoted so YAML 1.1 parsers keep them as strings (--normalize-bools;
+    with --yaml-version 1.1 plain yes/no/on/off are booleans and become
+    true/false)
+  - Optionally: anchors that no alias refers to removed (--fix-unused)
+  - Optionally: nulls written as null, ~ or nothing (--null-style)
+  - Optionally: trailing comments of the entries in each mapping or
+    sequence lined up in one column (--align-comments)
+  - Optionally: folded (>) block scalars re-wrapped at a column, keeping
+    their paragraphs; literal (|) scalars are left alone (--wrap)
+  - Optionally: the output re-parsed and compared with the input, failing
+    without writing anything if a value or its type changed (--verify;
+    on by default with -w, turn off with --verify=false)
+  - Optionally: bulk rewrites applied before formatting (--transform):
+      lowercase-keys  lowercase all mapping keys (fails if two keys collide)
+      trim-values     strip leading and trailing whitespace from values
 
 Exit codes:
-  0  Success
-  1  Error occurred
+  0  Success (with --check: everything is formatted)
+  1  Error occurred, or --check found input that is not formatted
 
 Examples:
   yam fmt config.yaml              # Format and print to stdout
-  yam fmt -w config.yaml           # Format in-place
+  yam fmt -w config.yaml           # Format in-place (verified)
+  yam fmt --verify config.yaml     # Fail if formatting would change a value
+  yam fmt --check .                # Fail if any file needs formatting (CI)
+  yam fmt --check --ignore-comments .  # Same, ignoring comment formatting
   cat config.yaml | yam fmt        # Format from stdin
   yam fmt --indent 4 config.yaml   # Use 4-space indentation
-  yam fmt --sort-keys config.yaml  # Sort keys alphabetically`,
+  yam fmt --tabs config.yaml       # Indent with tabs
+  yam fmt --sort-keys config.yaml  # Sort keys alphabetically
+  yam fmt --flow-width 40 config.yaml  # Inline containers shorter than 40 chars
+  yam fmt --preserve-blank-lines config.yaml  # Keep section spacing
+  yam fmt --transform lowercase-keys,trim-values config.yaml
+  yam fmt -w package.json          # Pretty-print JSON in place
+  yam fmt -w --exclude 'vendor/**' .   # Format a repository, skipping vendor/
+  yam fmt --to json config.yaml    # Convert YAML to JSON`,
 	Args:          cobra.MaximumNArgs(1),
 	RunE:          runFmt,
 	SilenceUsage:  true,
@@ -51,7 +130,26 @@ func init() {
 	rootCmd.AddCommand(fmtCmd)
 	fmtCmd.Flags().BoolVarP(&fmtWriteInPlace, "write", "w", false, "Write result to source file instead of stdout")
 	fmtCmd.Flags().IntVarP(&fmtIndent, "indent", "i", 2, "Indentation width in spaces")
+	fmtCmd.Flags().BoolVar(&fmtIndentSeqs, "indent-sequences", true, "Indent sequence dashes below their key; false writes them at the key's indentation")
+	fmtCmd.Flags().BoolVar(&fmtTabs, "tabs", false, "Indent with tabs instead of spaces")
 	fmtCmd.Flags().BoolVarP(&fmtSortKeys, "sort-keys", "s", false, "Sort keys alphabetically")
+	fmtCmd.Flags().IntVar(&fmtFlowWidth, "flow-width", 0, "Use flow style for containers shorter than this width (0 = disabled)")
+	fmtCmd.Flags().BoolVar(&fmtBlankLines, "preserve-blank-lines", false, "Keep blank lines between entries from the source")
+	fmtCmd.Flags().BoolVar(&fmtBools, "normalize-bools", false, "Rewrite booleans to true/false and quote yes/no/on/off strings")
+	fmtCmd.Flags().BoolVar(&fmtTimestamps, "normalize-timestamps", false, "Rewrite timestamps to canonical RFC 3339")
+	fmtCmd.Flags().StringVar(&fmtNullStyle, "null-style", "original", "Write nulls as: original, canonical (null), tilde (~), empty")
+	fmtCmd.Flags().StringSliceVar(&fmtTransforms, "transform", nil, "Apply transforms before formatting: "+strings.Join(parser.TransformNames(), ", "))
+	fmtCmd.Flags().StringVar(&fmtTo, "to", "", "Output format: yaml, json (default: json for .json files, yaml otherwise)")
+	fmtCmd.Flags().BoolVar(&fmtDocMarkers, "document-markers", false, "Start the document with --- and end it with ...")
+	fmtCmd.Flags().BoolVar(&fmtKeepQuotes, "preserve-quotes", false, "Keep quoted values quoted as written instead of unquoting them when safe")
+	fmtCmd.Flags().IntVar(&fmtWrap, "wrap", 0, "Re-wrap folded (>) block scalars at this column (0 = one line per paragraph)")
+	fmtCmd.Flags().BoolVar(&fmtAlign, "align-comments", false, "Align the trailing comments of sibling entries in a column")
+	fmtCmd.Flags().BoolVar(&fmtCheck, "check", false, "Write nothing and exit with status 1 if the input is not formatted")
+	fmtCmd.Flags().BoolVar(&fmtNoComments, "ignore-comments", false, "With --check, ignore differences in comments")
+	fmtCmd.Flags().BoolVar(&fmtVerify, "verify", false, "Re-parse the output and fail if any value changed (default true with -w)")
+	fmtCmd.Flags().StringSliceVar(&fmtInclude, "include", nil, "With a directory, format only files matching these globs (default '*.yaml,*.yml')")
+	fmtCmd.Flags().StringSliceVar(&fmtExclude, "exclude", nil, "With a directory, skip files and directories matching these globs (e.g. 'vendor/**')")
+	fmtCmd.Flags().BoolVar(&fmtFixUnused, "fix-unused", false, "Remove anchors that are never referenced by an alias")
 }
 
 func runFmt(cmd *cobra.Command, args []string) error {
@@ -59,9 +157,16 @@ func runFmt(cmd *cobra.Command, args []string) error {
 	var filename string
 	var isStdin bool
 
+	if err := checkFmtFlags(); err != nil {
+		return err
+	}
+
 	// Determine input source
 	if len(args) == 1 {
 		filename = args[0]
+		if info, err := os.Stat(filename); err == nil && info.IsDir() {
+			return runFmtDir(cmd, filename)
+		}
 		f, err := os.Open(filename)
 		if err != nil {
 			return fmt.Errorf("failed to open file: %w", err)
@@ -82,49 +187,160 @@ func runFmt(cmd *cobra.Command, args []string) error {
 	if fmtWriteInPlace && isStdin {
 		return fmt.Errorf("cannot use -w with stdin input")
 	}
+	err := formatInput(cmd, filename, input)
+	if errors.Is(err, errNotFormatted) {
+		if isStdin {
+			filename = "stdin"
+		}
+		return withExitCode(exitCodeFmtCheck, fmt.Errorf("%s is not formatted", filename))
+	}
+	return err
+}
+
+// checkFmtFlags rejects combinations of --check with the flags it does
+// not apply to
+func checkFmtFlags() error {
+	if fmtCheck && fmtWriteInPlace {
+		return fmt.Errorf("cannot use --check with -w")
+	}
+	if fmtNoComments && !fmtCheck {
+		return fmt.Errorf("--ignore-comments requires --check")
+	}
+	if fmtWrap < 0 {
+		return fmt.Errorf("--wrap must not be negative")
+	}
+	return nil
+}
+
+// formatInput formats input read from filename (empty for stdin) to stdout,
+// or back to filename with -w
+func formatInput(cmd *cobra.Command, filename string, input io.Reader) error {
+	// Output format follows the file extension unless --to is given
+	toJSON := isJSONFile(filename)
+	switch fmtTo {
+	case "":
+	case "yaml", "yml":
+		toJSON = false
+	case "json":
+		toJSON = true
+	default:
+		return fmt.Errorf("unsupported output format: %s (want yaml or json)", fmtTo)
+	}
+	if fmtWriteInPlace && toJSON != isJSONFile(filename) {
+		return fmt.Errorf("cannot use -w with --to %s: %s would change format", fmtTo, filename)
+	}
 
 	// Parse YAML
-	p := parser.New()
-	yamNode, err := p.Parse(input)
+	p, err := newParser()
+	if err != nil {
+		return err
+	}
+	src, err := io.ReadAll(input)
+	if err != nil {
+		return fmt.Errorf("failed to read input: %w", err)
+	}
+	// Every document is formatted; a stream is written back as a stream
+	docs, err := p.ParseAll(bytes.NewReader(src))
 	if err != nil {
 		return fmt.Errorf("invalid YAML: %w", err)
 	}
+	if toJSON && len(docs) > 1 {
+		return fmt.Errorf("cannot write %d documents as JSON", len(docs))
+	}
 
 	// Format options
 	opts := parser.FormatOptions{
-		Indent:   fmtIndent,
-		SortKeys: fmtSortKeys,
+		Indent:              fmtIndent,
+		SortKeys:            fmtSortKeys,
+		FlowThreshold:       fmtFlowWidth,
+		PreserveBlankLines:  fmtBlankLines,
+		NormalizeTimestamps: fmtTimestamps,
+		NormalizeBools:      fmtBools,
+		RemoveUnusedAnchors: fmtFixUnused,
+		AlignComments:       fmtAlign,
+		BlockStyle:          isJSONFile(filename),
+		CompactSequences:    !fmtIndentSeqs,
+		DocumentMarkers:     fmtDocMarkers,
+		WrapWidth:           fmtWrap,
+		PreserveQuotes:      fmtKeepQuotes,
+		YAMLVersion:         p.YAMLVersion,
 	}
 
-	// Get the raw yaml.Node for formatting
-	rawNode := yamNode.Raw
-
-	// Determine output destination
-	if fmtWriteInPlace {
-		// Write to temp file then rename (atomic)
-		dir := filepath.Dir(filename)
-		tmpFile, err := os.CreateTemp(dir, ".yam-fmt-*.yaml")
+	if fmtTabs {
+		opts.IndentStyle = parser.IndentTabs
+	}
+	if opts.NullStyle, err = parser.ParseNullStyle(fmtNullStyle); err != nil {
+		return err
+	}
+	for _, name := range fmtTransforms {
+		fn, err := parser.LookupTransform(name)
 		if err != nil {
-			return fmt.Errorf("failed to create temp file: %w", err)
+			return err
 		}
-		tmpPath := tmpFile.Name()
-		defer os.Remove(tmpPath) // cleanup on error
+		opts.Transforms = append(opts.Transforms, fn)
+	}
+
+	// Check the YAML output before it replaces anything; -w checks unless
+	// told not to
+	verify := !toJSON && (fmtVerify || fmtWriteInPlace && !cmd.Flags().Changed("verify"))
 
-		if err := parser.FormatTo(rawNode, tmpFile, opts); err != nil {
-			tmpFile.Close()
+	formatDoc := func(doc *parser.YamNode, w io.Writer) error {
+		opts := opts
+		opts.Directives = doc.Directives
+		if toJSON {
+			err = parser.FormatJSON(doc, w, opts)
+		} else if verify {
+			var buf bytes.Buffer
+			if err = parser.FormatTo(doc.Raw, &buf, opts); err == nil {
+				if err := parser.VerifyFormat(doc.Raw, buf.Bytes(), opts); err != nil {
+					if len(docs) > 1 {
+						err = fmt.Errorf("document %d: %w", slices.Index(docs, doc), err)
+					}
+					return fmt.Errorf("verification failed, nothing written: %w", err)
+				}
+				_, err = w.Write(buf.Bytes())
+			}
+		} else {
+			err = parser.FormatTo(doc.Raw, w, opts)
+		}
+		if err != nil {
 			return fmt.Errorf("failed to format: %w", err)
 		}
-		tmpFile.Close()
+		return nil
+	}
+	format := func(w io.Writer) error {
+		return writeDocuments(w, docs, formatDoc)
+	}
 
-		// Rename temp file to original
-		if err := os.Rename(tmpPath, filename); err != nil {
-			return fmt.Errorf("failed to write file: %w", err)
+	if fmtCheck {
+		var buf bytes.Buffer
+		if err := format(&buf); err != nil {
+			return err
 		}
-	} else {
-		if err := parser.FormatTo(rawNode, os.Stdout, opts); err != nil {
+		return checkFormatted(src, buf.Bytes())
+	}
+
+	// Determine output destination
+	if fmtWriteInPlace {
+		return writeFileAtomic(filename, format)
+	}
+	return format(os.Stdout)
+}
+
+// checkFormatted returns errNotFormatted unless src is the same as its
+// formatted output, apart from comments with --ignore-comments
+func checkFormatted(src, formatted []byte) error {
+	if fmtNoComments {
+		var err error
+		if src, err = parser.StripComments(src); err != nil {
+			return err
+		}
+		if formatted, err = parser.StripComments(formatted); err != nil {
 			return fmt.Errorf("failed to format: %w", err)
 		}
 	}
-
+	if !bytes.Equal(src, formatted) {
+		return errNotFormatted
+	}
 	return nil
 }
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFmt_NormalizeBoolsYAML11(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "flags.yaml")
+	if err := os.WriteFile(file, []byte("a: yes\nb: on\nc: 'no'\nd: True\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	yamlVersion = "1.1"
+	fmtBools = true
+	fmtWriteInPlace = true
+	defer func() {
+		yamlVersion = "1.2"
+		fmtBools = false
+		fmtWriteInPlace = false
+	}()
+	if err := runFmt(fmtCmd, []string{file}); err != nil {
+		t.Fatalf("runFmt failed: %v", err)
+	}
+
+	// Plain yes and on are booleans under 1.1 and must stay booleans
+	want := "a: true\nb: true\nc: 'no'\nd: true\n"
+	if data, _ := os.ReadFile(file); string(data) != want {
+		t.Errorf("expected %q, got %q", want, data)
+	}
+}
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultFmtInclude selects the files formatted below a directory when
+// --include is not given
+var defaultFmtInclude = []string{"*.yaml", "*.yml"}
+
+// ignoreFileName is the file at the top of a directory listing extra
+// --exclude patterns
+const ignoreFileName = ".yamignore"
+
+// runFmtDir formats in place every file below dir selected by --include
+// and not excluded by --exclude or .yamignore. A file that fails is
+// reported and skipped; the others are still formatted. With --check the
+// files that are not formatted are listed instead.
+func runFmtDir(cmd *cobra.Command, dir string) error {
+	if !fmtWriteInPlace && !fmtCheck {
+		return fmt.Errorf("formatting a directory requires -w or --check")
+	}
+
+	include := fmtInclude
+	if len(include) == 0 {
+		include = defaultFmtInclude
+	}
+	exclude, err := readIgnoreFile(filepath.Join(dir, ignoreFileName))
+	if err != nil {
+		return err
+	}
+	exclude = append(exclude, fmtExclude...)
+	for _, pattern := range append(append([]string{}, include...), exclude...) {
+		if _, err := matchGlob(pattern, "x"); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+
+	var files []string
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if matchAnyGlob(exclude, rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() && matchAnyGlob(include, rel) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	failed, unformatted := 0, 0
+	for _, file := range files {
+		err := formatFile(cmd, file)
+		switch {
+		case errors.Is(err, errNotFormatted):
+			fmt.Println(file)
+			unformatted++
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to format %d of %d files", failed, len(files))
+	}
+	if unformatted > 0 {
+		return withExitCode(exitCodeFmtCheck, fmt.Errorf("%d of %d files are not formatted", unformatted, len(files)))
+	}
+	return nil
+}
+
+// formatFile formats one file found by runFmtDir
+func formatFile(cmd *cobra.Command, filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+	return formatInput(cmd, filename, f)
+}
+
+// readIgnoreFile returns the patterns in an ignore file, skipping blank
+// lines and # comments. A missing file has no patterns.
+func readIgnoreFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", ignoreFileName, err)
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, strings.TrimSuffix(line, "/"))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", ignoreFileName, err)
+	}
+	return patterns, nil
+}
+
+// matchAnyGlob reports whether rel matches one of patterns
+func matchAnyGlob(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := matchGlob(pattern, rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches a slash-separated path relative to the formatted
+// directory. A pattern without "/" matches the last element of the path;
+// otherwise the pattern matches the whole path element by element with
+// filepath.Match, where a "**" element matches any number of elements.
+func matchGlob(pattern, rel string) (bool, error) {
+	pattern = strings.TrimPrefix(pattern, "./")
+	parts := strings.Split(rel, "/")
+	if !strings.Contains(pattern, "/") {
+		return filepath.Match(pattern, parts[len(parts)-1])
+	}
+	return matchGlobParts(strings.Split(pattern, "/"), parts)
+}
+
+func matchGlobParts(pattern, parts []string) (bool, error) {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(parts); i++ {
+				if ok, err := matchGlobParts(pattern[1:], parts[i:]); ok || err != nil {
+					return ok, err
+				}
+			}
+			return false, nil
+		}
+		if len(parts) == 0 {
+			return false, nil
+		}
+		ok, err := filepath.Match(pattern[0], parts[0])
+		if !ok || err != nil {
+			return false, err
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0, nil
+}
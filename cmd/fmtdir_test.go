@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern, rel string
+		want         bool
+	}{
+		{"*.yaml", "config.yaml", true},
+		{"*.yaml", "deploy/app.yaml", true},
+		{"*.yaml", "deploy/app.yml", false},
+		{"vendor/**", "vendor", true},
+		{"vendor/**", "vendor/lib/a.yaml", true},
+		{"vendor/**", "src/vendor/a.yaml", false},
+		{"**/vendor/**", "src/vendor/a.yaml", true},
+		{"deploy/*.yaml", "deploy/app.yaml", true},
+		{"deploy/*.yaml", "deploy/prod/app.yaml", false},
+		{"deploy/**/*.yaml", "deploy/prod/app.yaml", true},
+		{"./generated/*", "generated/a.yaml", true},
+	}
+
+	for _, tt := range tests {
+		got, err := matchGlob(tt.pattern, tt.rel)
+		if err != nil {
+			t.Fatalf("matchGlob(%q, %q) failed: %v", tt.pattern, tt.rel, err)
+		}
+		if got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, expected %v", tt.pattern, tt.rel, got, tt.want)
+		}
+	}
+}
+
+func TestRunFmtDir(t *testing.T) {
+	dir := t.TempDir()
+	unformatted := "a:   1\nb:\n    - x\n"
+	files := map[string]string{
+		"app.yaml":           unformatted,
+		"deploy/prod.yml":    unformatted,
+		"deploy/notes.txt":   unformatted,
+		"vendor/lib.yaml":    unformatted,
+		"generated/out.yaml": unformatted,
+		".git/config.yaml":   unformatted,
+		ignoreFileName:       "# generated files\ngenerated/\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+
+	fmtWriteInPlace = true
+	fmtExclude = []string{"vendor/**"}
+	defer func() {
+		fmtWriteInPlace = false
+		fmtExclude = nil
+	}()
+	if err := runFmtDir(fmtCmd, dir); err != nil {
+		t.Fatalf("runFmtDir failed: %v", err)
+	}
+
+	formatted := "a: 1\nb:\n  - x\n"
+	want := map[string]string{
+		"app.yaml":           formatted,
+		"deploy/prod.yml":    formatted,
+		"deploy/notes.txt":   unformatted,
+		"vendor/lib.yaml":    unformatted,
+		"generated/out.yaml": unformatted,
+		".git/config.yaml":   unformatted,
+	}
+	for name, content := range want {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", name, err)
+		}
+		if string(data) != content {
+			t.Errorf("%s: expected %q, got %q", name, content, string(data))
+		}
+	}
+}
+
+func TestRunFmtDir_Check(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"ok.yaml":       "a: 1\nb:\n  - x # note\n",
+		"comments.yaml": "a: 1   #note   \nb:\n  - x\n#  trailing  \n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+
+	fmtCheck = true
+	defer func() {
+		fmtCheck = false
+		fmtNoComments = false
+	}()
+	if err := runFmtDir(fmtCmd, dir); ExitCode(err) != exitCodeFmtCheck {
+		t.Fatalf("expected exit code %d for comments that need formatting, got %v", exitCodeFmtCheck, err)
+	}
+
+	fmtNoComments = true
+	if err := runFmtDir(fmtCmd, dir); err != nil {
+		t.Fatalf("expected --ignore-comments to pass, got %v", err)
+	}
+
+	bad := filepath.Join(dir, "bad.yaml")
+	if err := os.WriteFile(bad, []byte("a:   1\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := runFmtDir(fmtCmd, dir); ExitCode(err) != exitCodeFmtCheck {
+		t.Fatalf("expected exit code %d for data that needs formatting, got %v", exitCodeFmtCheck, err)
+	}
+	if data, _ := os.ReadFile(bad); string(data) != "a:   1\n" {
+		t.Errorf("--check modified %s: %q", bad, data)
+	}
+}
+
+func TestRunFmtDir_MultiDocument(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "stream.yaml")
+	if err := os.WriteFile(file, []byte("a:   1\n---\nb:\n    - x\n---\nc: 3\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	fmtWriteInPlace = true
+	defer func() { fmtWriteInPlace = false }()
+	if err := runFmtDir(fmtCmd, dir); err != nil {
+		t.Fatalf("runFmtDir failed: %v", err)
+	}
+	want := "a: 1\n---\nb:\n  - x\n---\nc: 3\n"
+	if data, _ := os.ReadFile(file); string(data) != want {
+		t.Errorf("expected every document to be kept, got %q", data)
+	}
+
+	fmtWriteInPlace = false
+	fmtCheck = true
+	defer func() { fmtCheck = false }()
+	if err := runFmtDir(fmtCmd, dir); err != nil {
+		t.Errorf("expected the formatted stream to pass --check, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/simota/yam/internal/parser"
+)
+
+// gitShow returns the content of a file at a revision of the git
+// repository containing it, as printed by "git show REV:path"
+func gitShow(rev, filename string) ([]byte, error) {
+	// git would read it as an option, e.g. --output=FILE
+	if strings.HasPrefix(rev, "-") {
+		return nil, fmt.Errorf("invalid revision %q", rev)
+	}
+	dir, base := filepath.Split(filename)
+	if dir == "" {
+		dir = "."
+	}
+	cmd := exec.Command("git", "-C", dir, "show", rev+":./"+base)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("git show %s:%s failed: %s", rev, filename, msg)
+	}
+	return out, nil
+}
+
+// parseGitDocuments parses the documents of a file at a git revision. It
+// returns the label used for that side of the diff, e.g. "HEAD:config.yaml".
+func parseGitDocuments(rev, filename string) (string, []*parser.YamNode, error) {
+	if filename == "-" || isURL(filename) {
+		return "", nil, withExitCode(exitCodeDiffError, fmt.Errorf("--git needs a file in a git repository"))
+	}
+	label := rev + ":" + filename
+	src, err := gitShow(rev, filename)
+	if err != nil {
+		return "", nil, withExitCode(exitCodeDiffError, err)
+	}
+	docs, err := parseDocuments(bytes.NewReader(src), isJSONFile(filename))
+	if err != nil {
+		return "", nil, withExitCode(exitCodeDiffError, fmt.Errorf("failed to parse %s: %w", label, err))
+	}
+	return label, docs, nil
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/simota/yam/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+var hashWithComments bool
+
+var hashCmd = &cobra.Command{
+	Use:   "hash [path] [file]",
+	Short: "Print a content hash of a YAML subtree",
+	Long: `Print a stable SHA-256 hash of a YAML/JSON document or of the subtree at
+a path.
+
+The hash covers keys, values and their types. Key order, indentation,
+quoting and comments do not affect it, so two files with the same content
+hash alike even when formatted differently, and a YAML file hashes like its
+JSON equivalent. Aliases are hashed as the content they refer to.
+
+Use --with-comments to include comments in the hash.
+
+Examples:
+  yam hash config.yaml
+  yam hash .spec deployment.yaml
+  yam hash --with-comments .spec deployment.yaml
+  cat config.yaml | yam hash`,
+	Args:          cobra.MaximumNArgs(2),
+	RunE:          runHash,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+func init() {
+	rootCmd.AddCommand(hashCmd)
+	hashCmd.Flags().BoolVar(&hashWithComments, "with-comments", false, "Include comments in the hash")
+}
+
+func runHash(cmd *cobra.Command, args []string) error {
+	node, err := loadPathArgs(args)
+	if err != nil {
+		return err
+	}
+
+	if hashWithComments {
+		fmt.Println(parser.HashWithComments(node))
+	} else {
+		fmt.Println(parser.Hash(node))
+	}
+	return nil
+}
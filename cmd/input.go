@@ -0,0 +1,210 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/simota/yam/internal/parser"
+)
+
+// splitPathArgs splits "[path] [file]" arguments.
+// A single argument is treated as a path when it starts with '.'.
+func splitPathArgs(args []string) (pathQuery, filename string) {
+	switch len(args) {
+	case 2:
+		// yam '.path' file.yaml
+		return args[0], args[1]
+	case 1:
+		// Could be path or file
+		if strings.HasPrefix(args[0], ".") {
+			// yam '.path' (with stdin)
+			return args[0], ""
+		}
+		// yam file.yaml
+		return "", args[0]
+	}
+	return "", ""
+}
+
+// urlTimeout bounds the time spent fetching a remote input
+const urlTimeout = 30 * time.Second
+
+// openInput opens the named input: a local file, an http(s) URL, or stdin
+// when filename is "-" or empty. isJSON reports the detected format, taken
+// from the Content-Type of a URL response or else the file extension.
+func openInput(filename string) (r io.ReadCloser, isJSON bool, err error) {
+	switch {
+	case filename == "-":
+		return io.NopCloser(os.Stdin), false, nil
+	case isURL(filename):
+		return fetchURL(filename)
+	case filename != "":
+		f, err := os.Open(filename)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to open file: %w", err)
+		}
+		return f, isJSONFile(filename), nil
+	}
+
+	// Check if stdin has data
+	stat, _ := os.Stdin.Stat()
+	if (stat.Mode() & os.ModeCharDevice) != 0 {
+		return nil, false, fmt.Errorf("no input: provide a file or pipe YAML content")
+	}
+	return io.NopCloser(os.Stdin), false, nil
+}
+
+// fetchURL downloads a remote input
+func fetchURL(rawURL string) (io.ReadCloser, bool, error) {
+	client := &http.Client{Timeout: urlTimeout}
+	resp, err := client.Get(rawURL)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to fetch URL: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, false, fmt.Errorf("failed to fetch %s: %s", rawURL, resp.Status)
+	}
+	return resp.Body, isJSONResponse(rawURL, resp.Header.Get("Content-Type")), nil
+}
+
+// isJSONResponse detects JSON from a Content-Type, falling back to the URL
+// extension for generic types such as text/plain
+func isJSONResponse(rawURL, contentType string) bool {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if strings.HasSuffix(mediaType, "json") {
+			return true
+		}
+		if strings.Contains(mediaType, "yaml") {
+			return false
+		}
+	}
+	if u, err := url.Parse(rawURL); err == nil {
+		return isJSONFile(u.Path)
+	}
+	return false
+}
+
+// isURL reports whether an input argument names an http(s) URL
+func isURL(name string) bool {
+	return strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://")
+}
+
+// newParser returns a parser configured by the global --yaml-version and
+// --max-nesting flags
+func newParser() (*parser.Parser, error) {
+	version, err := parser.ParseYAMLVersion(yamlVersion)
+	if err != nil {
+		return nil, err
+	}
+	p := parser.New()
+	p.YAMLVersion = version
+	p.MaxDepth = maxNesting
+	return p, nil
+}
+
+// parseInput parses the named input (see openInput) as YAML or JSON
+func parseInput(filename string) (*parser.YamNode, error) {
+	r, isJSON, err := openInput(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	p, err := newParser()
+	if err != nil {
+		return nil, err
+	}
+	if isJSON {
+		return p.ParseJSON(r)
+	}
+	return p.Parse(r)
+}
+
+// parseInputDocuments is like parseInput but returns every document of a
+// multi-document YAML stream. JSON input is always one document.
+func parseInputDocuments(filename string) ([]*parser.YamNode, error) {
+	r, isJSON, err := openInput(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return parseDocuments(r, isJSON)
+}
+
+// parseDocuments parses every document of a YAML stream, or one JSON
+// document
+func parseDocuments(r io.Reader, isJSON bool) ([]*parser.YamNode, error) {
+	p, err := newParser()
+	if err != nil {
+		return nil, err
+	}
+	if isJSON {
+		root, err := p.ParseJSON(r)
+		if err != nil {
+			return nil, err
+		}
+		return []*parser.YamNode{root}, nil
+	}
+	return p.ParseAll(r)
+}
+
+// documentAt returns the first document of a stream that has a node at
+// path, with that node, so a command can change one document and write
+// the others back untouched
+func documentAt(docs []*parser.YamNode, path string) (doc, node *parser.YamNode, err error) {
+	for _, doc := range docs {
+		node, err = parser.GetByPath(doc, path)
+		if err == nil {
+			return doc, node, nil
+		}
+		if !errors.Is(err, parser.ErrPathNotFound) {
+			break
+		}
+	}
+	return nil, nil, fmt.Errorf("path query failed: %w", err)
+}
+
+// checkSingleStdin rejects argument lists that name stdin ("-") more than once
+func checkSingleStdin(files []string) error {
+	count := 0
+	for _, f := range files {
+		if f == "-" {
+			count++
+		}
+	}
+	if count > 1 {
+		return fmt.Errorf("stdin (-) can only be used for one input")
+	}
+	return nil
+}
+
+// loadPathArgs parses the input named by "[path] [file]" arguments and
+// returns the node selected by the path (the whole document when omitted)
+func loadPathArgs(args []string) (*parser.YamNode, error) {
+	pathQuery, filename := splitPathArgs(args)
+
+	root, err := parseInput(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if pathQuery != "" {
+		root, err = parser.GetByPath(root, pathQuery)
+		if err != nil {
+			return nil, fmt.Errorf("path query failed: %w", err)
+		}
+	}
+	return root, nil
+}
+
+func isJSONFile(filename string) bool {
+	return strings.HasSuffix(strings.ToLower(filename), ".json")
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseInput_URL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/config.yaml":
+			w.Header().Set("Content-Type", "text/plain")
+			fmt.Fprint(w, "name: remote\n")
+		case "/api":
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			fmt.Fprint(w, `{"name": "remote"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	for _, path := range []string{"/config.yaml", "/api"} {
+		root, err := parseInput(server.URL + path)
+		if err != nil {
+			t.Fatalf("parseInput(%s) failed: %v", path, err)
+		}
+		if len(root.Children) == 0 {
+			t.Fatalf("parseInput(%s): empty document", path)
+		}
+	}
+
+	if _, err := parseInput(server.URL + "/missing.yaml"); err == nil {
+		t.Error("expected error for 404 response")
+	}
+}
+
+func TestIsJSONResponse(t *testing.T) {
+	tests := []struct {
+		url, contentType string
+		want             bool
+	}{
+		{"https://example.com/a.yaml", "application/json", true},
+		{"https://example.com/a", "application/vnd.api+json", true},
+		{"https://example.com/a.json", "application/yaml", false},
+		{"https://example.com/a.json?raw=1", "text/plain", true},
+		{"https://example.com/a.yaml", "", false},
+	}
+
+	for _, tt := range tests {
+		if got := isJSONResponse(tt.url, tt.contentType); got != tt.want {
+			t.Errorf("isJSONResponse(%q, %q) = %v, expected %v", tt.url, tt.contentType, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/simota/yam/internal/lint"
+	"github.com/spf13/cobra"
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint [file...]",
+	Short: "Check YAML files for anchor and alias problems",
+	Long: `Check YAML files for anchor/alias integrity problems. Every document of a
+multi-document file is checked.
+
+Reported issues:
+  dangling-alias    (error)    An alias refers to an anchor that is not
+                               defined before it
+  unused-anchor     (warning)  An anchor is never referenced by an alias
+  duplicate-anchor  (warning)  An anchor reuses the name of an earlier anchor
+                               in the same document; later aliases refer to
+                               the new one
+
+Each issue is printed as file:line:column, followed by the severity, the
+message, the rule name, and the path of the affected node. --format json
+prints a JSON array of {file, rule, severity, path, line, column, message}
+objects instead, and --format github prints GitHub Actions annotations
+(::error file=...::) that show up on the changed lines of a pull request.
+Unused anchors can be removed with 'yam fmt --fix-unused'.
+
+Exit codes:
+  0  No errors found (warnings may have been reported)
+  1  Errors found, or a file could not be read
+
+Examples:
+  yam lint config.yaml
+  yam lint base.yaml overrides.yaml
+  yam lint --format github deploy/*.yaml
+  cat config.yaml | yam lint`,
+	RunE:          runLint,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+var lintFormat string
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+	lintCmd.Flags().StringVar(&lintFormat, "format", "text", "Output format: text, json, github (GitHub Actions annotations)")
+}
+
+// fileIssue is a lint issue with the file it was found in
+type fileIssue struct {
+	File string `json:"file"`
+	lint.Issue
+}
+
+func runLint(cmd *cobra.Command, args []string) error {
+	switch lintFormat {
+	case "text", "json", "github":
+	default:
+		return fmt.Errorf("unsupported lint format %q (supported: text, json, github)", lintFormat)
+	}
+
+	files := args
+	if len(files) == 0 {
+		files = []string{""}
+	}
+	if err := checkSingleStdin(files); err != nil {
+		return err
+	}
+
+	all := []fileIssue{}
+	failed := false
+	for _, filename := range files {
+		issues, err := lintFile(filename)
+		if err != nil {
+			return err
+		}
+
+		name := filename
+		if name == "" || name == "-" {
+			name = "<stdin>"
+		}
+		for _, issue := range issues {
+			all = append(all, fileIssue{File: name, Issue: issue})
+			if issue.Severity == lint.SeverityError {
+				failed = true
+			}
+		}
+	}
+
+	switch lintFormat {
+	case "json":
+		out, err := json.MarshalIndent(all, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		fmt.Println(string(out))
+	case "github":
+		for _, i := range all {
+			fmt.Printf("::%s file=%s,line=%d,col=%d,title=%s::%s\n",
+				i.Severity, githubProperty(i.File), i.Line, i.Column, githubProperty(i.Rule),
+				githubData(fmt.Sprintf("%s (%s)", i.Message, i.Path)))
+		}
+	default:
+		for _, i := range all {
+			fmt.Printf("%s:%d:%d: %s: %s [%s] (%s)\n",
+				i.File, i.Line, i.Column, i.Severity, i.Message, i.Rule, i.Path)
+		}
+	}
+
+	if failed {
+		return &exitError{code: exitCodeLintFound}
+	}
+	return nil
+}
+
+// githubData escapes the message of a GitHub Actions workflow command
+func githubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// githubProperty escapes a property value of a GitHub Actions workflow
+// command, which also ends at ":" and ","
+func githubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// lintFile reads one input and returns its lint issues
+func lintFile(filename string) ([]lint.Issue, error) {
+	r, _, err := openInput(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+
+	issues, err := lint.Lint(src)
+	if err != nil {
+		if filename != "" && filename != "-" {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+		return nil, err
+	}
+	return issues, nil
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLintFormats(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(file, []byte("a: *missing\nb: &unused 1\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	defer func() { lintFormat = "text" }()
+
+	lintFormat = "json"
+	var err error
+	out := captureStdout(t, func() {
+		err = runLint(lintCmd, []string{file})
+	})
+	if code := ExitCode(err); code != 1 {
+		t.Errorf("expected exit code 1, got %d (err: %v)", code, err)
+	}
+	var issues []map[string]any
+	if err := json.Unmarshal([]byte(out), &issues); err != nil {
+		t.Fatalf("expected JSON, got %v:\n%s", err, out)
+	}
+	if len(issues) != 2 || issues[0]["rule"] != "dangling-alias" || issues[0]["severity"] != "error" ||
+		issues[0]["file"] != file || issues[0]["line"] != 1.0 || issues[1]["severity"] != "warning" {
+		t.Errorf("unexpected issues %v", issues)
+	}
+
+	lintFormat = "github"
+	out = captureStdout(t, func() {
+		err = runLint(lintCmd, []string{file})
+	})
+	want := "::error file=" + file + ",line=1,col=4,title=dangling-alias::alias *missing has no preceding anchor &missing ($.a)\n"
+	if !strings.HasPrefix(out, want) || !strings.Contains(out, "::warning ") {
+		t.Errorf("expected GitHub annotations, got:\n%s", out)
+	}
+
+	lintFormat = "xml"
+	if err := runLint(lintCmd, []string{file}); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
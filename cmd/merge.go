@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/simota/yam/internal/parser"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mergeArrayMode string
+	mergeForce     bool
+	mergeOutput    string
+)
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge <base> <overlay>",
+	Short: "Deep-merge an overlay YAML file onto a base file",
+	Long: `Deep-merge two YAML (or JSON) files and print the result as YAML. Either
+file may be - for stdin or an http(s) URL.
+
+Merge rules:
+  - Mappings are merged recursively
+  - Scalars from the overlay win
+  - Sequences are replaced by the overlay (default) or appended (--array-merge append)
+
+Values whose type differs between base and overlay (e.g. a mapping replaced
+by a scalar, or 80 replaced by "80") are reported as conflicts and nothing is
+written. Use --force to let the overlay win.
+
+Examples:
+  yam merge base.yaml prod.yaml
+  yam merge --array-merge append base.yaml extra.yaml
+  yam merge -o merged.yaml base.yaml prod.yaml`,
+	Args:          cobra.ExactArgs(2),
+	RunE:          runMerge,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+func init() {
+	rootCmd.AddCommand(mergeCmd)
+	mergeCmd.Flags().StringVar(&mergeArrayMode, "array-merge", "replace", "Sequence merge strategy: replace, append")
+	mergeCmd.Flags().BoolVar(&mergeForce, "force", false, "Let the overlay win type conflicts")
+	mergeCmd.Flags().StringVarP(&mergeOutput, "output", "o", "", "Write result to file instead of stdout")
+}
+
+func runMerge(cmd *cobra.Command, args []string) error {
+	opts := parser.MergeOptions{Force: mergeForce}
+	switch mergeArrayMode {
+	case "replace":
+		opts.ArrayMerge = parser.ArrayMergeReplace
+	case "append":
+		opts.ArrayMerge = parser.ArrayMergeAppend
+	default:
+		return fmt.Errorf("invalid --array-merge value: %s (expected replace or append)", mergeArrayMode)
+	}
+
+	if args[0] == "-" && args[1] == "-" {
+		return fmt.Errorf("only one of base and overlay can be read from stdin")
+	}
+	p, err := newParser()
+	if err != nil {
+		return err
+	}
+	base, err := parseMergeInput(p, args[0])
+	if err != nil {
+		return err
+	}
+	overlay, err := parseMergeInput(p, args[1])
+	if err != nil {
+		return err
+	}
+
+	opts.YAMLVersion = p.YAMLVersion
+	if err := parser.Merge(base.Raw, overlay.Raw, opts); err != nil {
+		var mergeErr *parser.MergeError
+		if errors.As(err, &mergeErr) {
+			return fmt.Errorf("merge aborted, %w\n\nUse --force to let the overlay win", err)
+		}
+		return err
+	}
+
+	write := func(w io.Writer) error {
+		return parser.FormatTo(base.Raw, w, parser.DefaultFormatOptions())
+	}
+	if mergeOutput != "" {
+		return writeFileAtomic(mergeOutput, write)
+	}
+	return write(os.Stdout)
+}
+
+// parseMergeInput parses one side of a merge (see openInput). JSON is
+// parsed as YAML so both sides carry complete yaml.Nodes.
+func parseMergeInput(p *parser.Parser, filename string) (*parser.YamNode, error) {
+	r, _, err := openInput(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	node, err := p.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+	return node, nil
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMerge_YAMLVersion(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	overlay := filepath.Join(dir, "overlay.yaml")
+	if err := os.WriteFile(base, []byte("debug: yes\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if err := os.WriteFile(overlay, []byte("debug: false\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	// yes is a string under 1.2, so replacing it with a boolean conflicts
+	if err := runMerge(mergeCmd, []string{base, overlay}); err == nil {
+		t.Fatal("expected a type conflict under YAML 1.2")
+	}
+
+	yamlVersion = "1.1"
+	defer func() { yamlVersion = "1.2" }()
+	var err error
+	out := captureStdout(t, func() { err = runMerge(mergeCmd, []string{base, overlay}) })
+	if err != nil {
+		t.Fatalf("runMerge failed under YAML 1.1: %v", err)
+	}
+	if out != "debug: false\n" {
+		t.Errorf("expected the overlay value, got %q", out)
+	}
+}
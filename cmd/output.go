@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/simota/yam/internal/parser"
+)
+
+// formatDocument writes one document formatted like yam fmt with its
+// default options
+func formatDocument(doc *parser.YamNode, w io.Writer) error {
+	opts := parser.DefaultFormatOptions()
+	opts.Directives = doc.Directives
+	return parser.FormatTo(doc.Raw, w, opts)
+}
+
+// writeDocuments writes every document of a stream with write, putting a
+// "---" line between them unless a document's output starts with one
+func writeDocuments(w io.Writer, docs []*parser.YamNode, write func(doc *parser.YamNode, w io.Writer) error) error {
+	for i, doc := range docs {
+		var buf bytes.Buffer
+		if err := write(doc, &buf); err != nil {
+			return err
+		}
+		if i > 0 && !bytes.HasPrefix(buf.Bytes(), []byte("---")) {
+			if _, err := io.WriteString(w, "---\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFileAtomic writes to a temp file in the target directory and renames
+// it over filename, so a failed write never leaves a truncated file behind
+func writeFileAtomic(filename string, write func(w io.Writer) error) error {
+	dir := filepath.Dir(filename)
+	tmpFile, err := os.CreateTemp(dir, ".yam-*"+filepath.Ext(filename))
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath) // cleanup on error
+
+	if err := write(tmpFile); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	// Keep the original file's permissions when overwriting
+	if info, err := os.Stat(filename); err == nil {
+		_ = os.Chmod(tmpPath, info.Mode().Perm())
+	}
+
+	// Rename temp file to target
+	if err := os.Rename(tmpPath, filename); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// defaultPager keeps colors and exits immediately when output fits on screen
+const defaultPager = "less -R"
+
+// printPaged writes output to stdout, through a pager when force is set or
+// when stdout is a terminal and output is taller than it. $PAGER selects
+// the pager; an empty value or "cat" disables paging.
+func printPaged(output string, force bool) error {
+	if !force && !exceedsTerminal(output) {
+		fmt.Print(output)
+		return nil
+	}
+
+	pager, ok := os.LookupEnv("PAGER")
+	if !ok {
+		pager = defaultPager
+	}
+	fields := strings.Fields(pager)
+	if len(fields) == 0 || fields[0] == "cat" {
+		fmt.Print(output)
+		return nil
+	}
+	if _, err := exec.LookPath(fields[0]); err != nil {
+		// Fall back to plain output when the pager is missing
+		fmt.Print(output)
+		return nil
+	}
+
+	cmd := exec.Command("sh", "-c", pager)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		// Quit if one screen, keep colors, don't clear the screen on exit
+		cmd.Env = append(cmd.Env, "LESS=FRX")
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to start pager: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start pager: %w", err)
+	}
+
+	// The pager may exit before reading everything (e.g. q in less); the
+	// resulting broken pipe and the pager's exit status are not errors
+	_, _ = io.WriteString(stdin, output)
+	stdin.Close()
+	_ = cmd.Wait()
+	return nil
+}
+
+// exceedsTerminal reports whether stdout is a terminal and output has more
+// lines than fit on it
+func exceedsTerminal(output string) bool {
+	fd := int(os.Stdout.Fd())
+	if !term.IsTerminal(fd) {
+		return false
+	}
+	_, height, err := term.GetSize(fd)
+	if err != nil || height <= 0 {
+		return false
+	}
+	return strings.Count(output, "\n") >= height
+}
@@ -1,24 +1,59 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
-	"io"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 	"github.com/simota/yam/internal/parser"
 	"github.com/simota/yam/internal/renderer"
 	"github.com/simota/yam/internal/ui"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
+// defaultOutputWidth is the line width used when stdout is not a terminal
+const defaultOutputWidth = 80
+
 var (
-	interactive bool
-	treeStyle   string
-	showTypes   bool
-	outputJSON  bool
-	rawOutput   bool
-	version     = "0.1.0"
+	interactive    bool
+	treeStyle      string
+	showTypes      bool
+	outputJSON     bool
+	rawOutput      bool
+	showComments   bool
+	treeJSON       bool
+	timezone       string
+	showTags       bool
+	outputWidth    int
+	nullStyle      string
+	yamlVersion    string
+	maxNesting     int
+	gotoPath       string
+	startSearch    string
+	noRemember     bool
+	selectPaths    []string
+	showBinary     bool
+	expandEnv      bool
+	showDirectives bool
+	countOnly      bool
+	groupByType    bool
+	redact         bool
+	redactKey      string
+	usePager       bool
+	noPager        bool
+	themeFile      string
+	compact        bool
+	colorMode      string
+	maxValueLength int
+	watch          bool
+	version        = "0.1.0"
 )
 
 var rootCmd = &cobra.Command{
@@ -32,12 +67,34 @@ Examples:
   yam -i config.yaml           # Interactive TUI mode
   yam '.data.host' config.yaml # Extract value at path
   yam '.items[0]' config.yaml  # Extract array element
+  yam --select '.a,.b.c' config.yaml # Keep only some paths, with their parents
   yam --json config.yaml       # Output as JSON
+  yam --count config.yaml      # Number of keys, leaves and max depth
+  yam --count --group-by-type config.yaml # Also scalars per type, with example paths
+  yam --compact config.yaml    # Top-level overview with container sizes
+  yam --max-value-length 40 config.yaml # Cut long values with …
+  yam --redact config.yaml     # Mask password/token/secret/key values
+  yam -P big.yaml              # Page the output through $PAGER (less -R)
+  yam --json-output config.yaml # Node metadata (path, kind, type, line) as JSON
   yam -r '.data.host' config.yaml # Raw value output (no decoration)
-  yam data.json                # Render JSON file as tree`,
-	Version: version,
-	Args:    cobra.MaximumNArgs(2),
-	RunE:    run,
+  yam data.json                # Render JSON file as tree
+  yam --expand-env app.yaml    # Preview ${VAR} placeholders resolved from the environment
+  yam --theme-file dark.yaml config.yaml # Use your own colors
+  yam --watch config.yaml      # Live preview while editing elsewhere
+  yam --color always config.yaml | less -R # Keep colors in a pipe
+
+Colors are written only when stdout is a terminal and NO_COLOR is not set.
+--color always keeps them when the output is piped or redirected, and
+--color never turns them off; this applies to every subcommand.`,
+	Version:           version,
+	Args:              cobra.MaximumNArgs(2),
+	PersistentPreRunE: applyColorMode,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return classifyError(run(cmd, args))
+	},
+	// Errors are printed once by main, without the usage text
+	SilenceUsage:  true,
+	SilenceErrors: true,
 }
 
 func Execute() error {
@@ -45,120 +102,287 @@ func Execute() error {
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&yamlVersion, "yaml-version", "1.2", "Read yes/no/on/off as strings (1.2) or booleans (1.1)")
+	rootCmd.PersistentFlags().StringVar(&colorMode, "color", "auto", "Color output: auto (only on a terminal), always, never")
+	rootCmd.PersistentFlags().IntVar(&maxNesting, "max-nesting", parser.DefaultMaxDepth, "Reject input whose mappings and sequences nest deeper than this")
 	rootCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Interactive TUI mode")
-	rootCmd.Flags().StringVarP(&treeStyle, "style", "s", "unicode", "Tree style: unicode, ascii, indent")
+	rootCmd.Flags().StringVar(&gotoPath, "goto", "", "With -i, start with the cursor on the node at this path (e.g. '.spec.containers[0]')")
+	rootCmd.Flags().StringVar(&startSearch, "search", "", "With -i, start with this search active and the cursor on the first match")
+	rootCmd.Flags().BoolVar(&noRemember, "no-remember", false, "With -i, do not restore or save which nodes are collapsed")
+	rootCmd.Flags().StringVarP(&treeStyle, "style", "s", "unicode", "Tree style: unicode, ascii, indent, rounded, heavy")
 	rootCmd.Flags().BoolVarP(&showTypes, "types", "t", false, "Show type annotations")
+	rootCmd.Flags().BoolVar(&showTags, "show-tags", false, "Show resolved tags (explicit tags are always shown)")
 	rootCmd.Flags().BoolVarP(&outputJSON, "json", "j", false, "Output as JSON")
 	rootCmd.Flags().BoolVarP(&rawOutput, "raw", "r", false, "Output raw value without decoration")
+	rootCmd.Flags().BoolVar(&showBinary, "show-binary", false, "Show binary and base64 values in full instead of <binary N bytes>")
+	rootCmd.Flags().BoolVar(&expandEnv, "expand-env", false, "Show strings with ${VAR} and $VAR replaced by environment variables (the file is not changed)")
+	rootCmd.Flags().BoolVar(&showDirectives, "directives", false, "Show %YAML/%TAG directives and ---/... document markers")
+	rootCmd.Flags().BoolVar(&showComments, "comments", true, "Show head and foot comments")
+	rootCmd.Flags().StringVar(&timezone, "timezone", "", "Display timestamps in this time zone (e.g. UTC, Local, Asia/Tokyo)")
+	rootCmd.Flags().StringVar(&nullStyle, "null-style", "canonical", "Display nulls as: canonical (null), original (as written), tilde (~), empty (nothing)")
+	rootCmd.Flags().IntVar(&outputWidth, "width", -1, "Truncate lines to this width (default: terminal width, or 80 when not a terminal; 0 = unlimited)")
+	rootCmd.Flags().StringSliceVar(&selectPaths, "select", nil, "Keep only these paths, with their parent structure (e.g. '.a,.b.c,.items[0]')")
+	rootCmd.Flags().IntVar(&maxValueLength, "max-value-length", 0, "Cut values longer than this many characters with … (0 = unlimited; in -i, v shows the full value)")
+	rootCmd.Flags().BoolVar(&compact, "compact", false, "Show only the top level, with mappings as {N keys} and sequences as [N items]")
+	rootCmd.Flags().BoolVar(&countOnly, "count", false, "Print the number of keys, leaves and the max depth instead of rendering")
+	rootCmd.Flags().BoolVar(&groupByType, "group-by-type", false, "With --count, also count the scalars of each type and show a few of their paths (implies --count)")
+	rootCmd.Flags().BoolVar(&redact, "redact", false, "Mask values under keys like password, token, secret or key with **** (also in -r and JSON output)")
+	rootCmd.Flags().StringVar(&redactKey, "redact-key", "", "Mask values under keys matching this regular expression (implies --redact)")
+	rootCmd.Flags().BoolVarP(&usePager, "pager", "P", false, "Page the output through $PAGER (default: automatic when it does not fit the terminal)")
+	rootCmd.Flags().BoolVar(&watch, "watch", false, "Render the file again whenever it changes (with -i, reload the tree)")
+	rootCmd.Flags().BoolVar(&noPager, "no-pager", false, "Never page the output")
+	rootCmd.Flags().StringVar(&themeFile, "theme-file", "", "Read colors from this YAML file (default: ~/.config/yam/theme.yaml when it exists)")
+	rootCmd.Flags().BoolVar(&treeJSON, "json-output", false, "Output node metadata (path, kind, type, position) as JSON")
 }
 
 func run(cmd *cobra.Command, args []string) error {
-	var input io.Reader
-	var filename string
-	var pathQuery string
-
-	// Parse arguments: [path] [file] or [file] or nothing
-	// Path starts with '.'
-	switch len(args) {
-	case 2:
-		// yam '.path' file.yaml
-		pathQuery = args[0]
-		filename = args[1]
-	case 1:
-		// Could be path or file
-		if strings.HasPrefix(args[0], ".") {
-			// yam '.path' (with stdin)
-			pathQuery = args[0]
-		} else {
-			// yam file.yaml
-			filename = args[0]
-		}
+	_, filename := splitPathArgs(args)
+	if filename == "" {
+		filename = "stdin"
 	}
 
-	// Set up input source
-	if filename != "" {
-		f, err := os.Open(filename)
-		if err != nil {
-			return fmt.Errorf("failed to open file: %w", err)
+	// Determine tree style
+	style, err := renderer.ParseTreeStyle(treeStyle)
+	if err != nil {
+		return err
+	}
+	if maxValueLength < 0 {
+		return fmt.Errorf("--max-value-length must not be negative")
+	}
+	// Only local files can be watched, and have a stable identity to
+	// remember folds by
+	localFile := filename != "stdin" && filename != "-" && !isURL(filename)
+	if watch && !localFile {
+		return fmt.Errorf("--watch needs a local file")
+	}
+
+	if interactive {
+		if len(selectPaths) > 0 {
+			// A projection could be saved over the source file
+			return fmt.Errorf("--select cannot be used with -i")
+		}
+		if redact || redactKey != "" {
+			// The TUI shows and edits the real values
+			return fmt.Errorf("--redact cannot be used with -i")
 		}
-		defer f.Close()
-		input = f
-	} else {
-		// Check if stdin has data
-		stat, _ := os.Stdin.Stat()
-		if (stat.Mode() & os.ModeCharDevice) != 0 {
-			return fmt.Errorf("no input: provide a file or pipe YAML content")
+
+		// Run TUI; the input is parsed in the background behind a spinner
+		load := func() (*parser.YamNode, error) {
+			return loadPathArgs(args)
+		}
+		theme, err := loadTheme()
+		if err != nil {
+			return err
 		}
-		input = os.Stdin
-		if filename == "" {
-			filename = "stdin"
+		var changes <-chan struct{}
+		if watch {
+			var stop func() error
+			if changes, stop, err = watchFile(filename); err != nil {
+				return err
+			}
+			defer stop()
 		}
+		return ui.Run(load, filename, style, showTypes, ui.Options{
+			GotoPath:       gotoPath,
+			Search:         startSearch,
+			RememberFolds:  !noRemember && localFile,
+			Theme:          theme,
+			MaxValueLength: maxValueLength,
+			Changes:        changes,
+		})
 	}
 
-	// Parse input (YAML or JSON based on file extension)
-	p := parser.New()
-	var root *parser.YamNode
-	var err error
-
-	if isJSONFile(filename) {
-		root, err = p.ParseJSON(input)
-	} else {
-		root, err = p.Parse(input)
+	if watch {
+		return runWatch(filename, func() (string, error) {
+			return renderArgs(args, style)
+		})
 	}
+
+	output, err := renderArgs(args, style)
 	if err != nil {
 		return err
 	}
+	// Only the tree is paged; the other modes are meant for scripts
+	if noPager || rawOutput || countOnly || groupByType || outputJSON || treeJSON {
+		fmt.Print(output)
+		return nil
+	}
+	return printPaged(output, usePager)
+}
 
-	// Apply path query if specified
-	if pathQuery != "" {
-		root, err = parser.GetByPath(root, pathQuery)
-		if err != nil {
-			return fmt.Errorf("path query failed: %w", err)
+// renderArgs loads the input named by args and returns it in the selected
+// output mode: the rendered tree by default
+func renderArgs(args []string, style renderer.TreeStyle) (string, error) {
+	// Parse input (YAML or JSON) and apply the path query if specified
+	root, err := loadPathArgs(args)
+	if err != nil {
+		return "", err
+	}
+	if len(selectPaths) > 0 {
+		if root, err = parser.Project(root, selectPaths); err != nil {
+			return "", err
 		}
 	}
 
-	// Determine tree style
-	style := renderer.TreeStyleUnicode
-	switch treeStyle {
-	case "ascii":
-		style = renderer.TreeStyleASCII
-	case "indent":
-		style = renderer.TreeStyleIndent
+	// Masked before any output mode, so none of them shows the values
+	redactPattern, err := redactPattern()
+	if err != nil {
+		return "", err
 	}
-
-	if interactive {
-		// Run TUI
-		return ui.Run(root, filename, style, showTypes)
+	if redactPattern != nil {
+		parser.Redact(root, redactPattern, renderer.RedactedValue)
 	}
 
 	// Raw output mode (for scripting)
 	if rawOutput {
-		output := parser.ToRawValue(root)
-		fmt.Println(output)
-		return nil
+		return parser.ToRawValue(root) + "\n", nil
+	}
+
+	// Size summary mode
+	if countOnly || groupByType {
+		return countOutput(root)
 	}
 
 	// JSON output mode
 	if outputJSON {
 		jsonBytes, err := parser.ToJSON(root, true)
 		if err != nil {
-			return fmt.Errorf("failed to convert to JSON: %w", err)
+			return "", fmt.Errorf("failed to convert to JSON: %w", err)
 		}
-		fmt.Println(string(jsonBytes))
-		return nil
+		return string(jsonBytes) + "\n", nil
+	}
+
+	// Node metadata output mode (for tooling)
+	if treeJSON {
+		jsonBytes, err := parser.ToTreeJSON(root, true)
+		if err != nil {
+			return "", fmt.Errorf("failed to convert to JSON: %w", err)
+		}
+		return string(jsonBytes) + "\n", nil
 	}
 
 	// CLI mode: render and print
 	opts := renderer.DefaultOptions()
 	opts.TreeStyle = style
 	opts.ShowTypes = showTypes
-	r := renderer.New(nil, opts)
-	output := r.Render(root)
-	fmt.Print(output)
+	opts.ShowComments = showComments
+	opts.ShowTags = showTags
+	opts.ShowBinary = showBinary
+	opts.ShowDirectives = showDirectives
+	opts.Compact = compact
+	opts.MaxValueLength = maxValueLength
+	if expandEnv {
+		opts.ExpandEnv = os.LookupEnv
+	}
+	opts.MaxWidth = outputWidth
+	if outputWidth < 0 {
+		opts.MaxWidth = terminalWidth()
+	}
+	if opts.NullStyle, err = parser.ParseNullStyle(nullStyle); err != nil {
+		return "", err
+	}
+	opts.Redact = redactPattern
+	if timezone != "" {
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			return "", fmt.Errorf("invalid time zone: %w", err)
+		}
+		opts.Timezone = loc
+	}
+	theme, err := loadTheme()
+	if err != nil {
+		return "", err
+	}
+	return renderer.New(theme, opts).Render(root), nil
+}
+
+// redactPattern returns the keys whose values --redact and --redact-key
+// mask, or nil when nothing is masked
+func redactPattern() (*regexp.Regexp, error) {
+	if redactKey != "" {
+		re, err := regexp.Compile(redactKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --redact-key: %w", err)
+		}
+		return re, nil
+	}
+	if redact {
+		return renderer.DefaultRedactPattern, nil
+	}
+	return nil, nil
+}
 
+// typeExamples is how many paths --group-by-type shows for each type
+const typeExamples = 3
+
+// countOutput returns the --count summary of root, as text or with --json
+// as JSON, with the scalars per type when --group-by-type is set
+func countOutput(root *parser.YamNode) (string, error) {
+	c := parser.Count(root)
+	var types []parser.TypeCount
+	if groupByType {
+		types = parser.CountTypes(root, typeExamples)
+	}
+
+	if outputJSON {
+		summary := struct {
+			parser.Counts
+			Types []parser.TypeCount `json:"types,omitempty"`
+		}{c, types}
+		out, err := json.MarshalIndent(summary, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to encode JSON: %w", err)
+		}
+		return string(out) + "\n", nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "keys:   %d\nleaves: %d\ndepth:  %d\n", c.Keys, c.Leaves, c.MaxDepth)
+	if len(types) > 0 {
+		width := 0
+		for _, t := range types {
+			width = max(width, len(strconv.Itoa(t.Count)))
+		}
+		b.WriteString("\n")
+		for _, t := range types {
+			examples := strings.Join(t.Examples, ", ")
+			if t.Count > len(t.Examples) {
+				examples += ", …"
+			}
+			fmt.Fprintf(&b, "%-6s %*d  %s\n", t.Type, width, t.Count, examples)
+		}
+	}
+	return b.String(), nil
+}
+
+// applyColorMode sets the color profile of all output from --color. In
+// auto mode lipgloss detects it from stdout, NO_COLOR and CLICOLOR_FORCE.
+func applyColorMode(cmd *cobra.Command, args []string) error {
+	switch colorMode {
+	case "auto":
+	case "always":
+		// Use the colors the terminal supports, as if stdout were one
+		profile := termenv.NewOutput(os.Stdout, termenv.WithTTY(true)).ColorProfile()
+		if profile == termenv.Ascii {
+			profile = termenv.ANSI
+		}
+		lipgloss.SetColorProfile(profile)
+	case "never":
+		lipgloss.SetColorProfile(termenv.Ascii)
+	default:
+		return fmt.Errorf("unknown color mode %q (want auto, always or never)", colorMode)
+	}
 	return nil
 }
 
-func isJSONFile(filename string) bool {
-	return strings.HasSuffix(strings.ToLower(filename), ".json")
+// terminalWidth returns the width of the terminal on stdout, or 80 when
+// stdout is not a terminal
+func terminalWidth() int {
+	fd := int(os.Stdout.Fd())
+	if term.IsTerminal(fd) {
+		if width, _, err := term.GetSize(fd); err == nil && width > 0 {
+			return width
+		}
+	}
+	return defaultOutputWidth
 }
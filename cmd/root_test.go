@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/simota/yam/internal/renderer"
+)
+
+func TestRedactOutputModes(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "secrets.yaml")
+	if err := os.WriteFile(file, []byte("user: admin\ndb:\n  password: hunter2\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	redact = true
+	defer func() {
+		redact = false
+		rawOutput, outputJSON, treeJSON = false, false, false
+	}()
+
+	tests := []struct {
+		name string
+		args []string
+		mode *bool
+	}{
+		{"tree", []string{file}, nil},
+		{"raw", []string{".db.password", file}, &rawOutput},
+		{"raw container", []string{".db", file}, &rawOutput},
+		{"json", []string{file}, &outputJSON},
+		{"json-output", []string{file}, &treeJSON},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mode != nil {
+				*tt.mode = true
+				defer func() { *tt.mode = false }()
+			}
+			out, err := renderArgs(tt.args, renderer.TreeStyleUnicode)
+			if err != nil {
+				t.Fatalf("renderArgs failed: %v", err)
+			}
+			if strings.Contains(out, "hunter2") || !strings.Contains(out, renderer.RedactedValue) {
+				t.Errorf("expected the password masked, got %q", out)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSort_MultiDocument(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "lists.yaml")
+	if err := os.WriteFile(file, []byte("other: doc\n---\nl: [b, a]\n---\nl: [d, c]\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	sortWriteInPlace = true
+	defer func() { sortWriteInPlace = false }()
+	if err := runSort(sortCmd, []string{".l", file}); err != nil {
+		t.Fatalf("runSort failed: %v", err)
+	}
+
+	// Only the first document with the path is sorted; none is dropped
+	want := "other: doc\n---\nl: [a, b]\n---\nl: [d, c]\n"
+	if data, _ := os.ReadFile(file); string(data) != want {
+		t.Errorf("expected %q, got %q", want, data)
+	}
+}
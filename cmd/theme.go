@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/simota/yam/internal/renderer"
+)
+
+// loadTheme returns the colors to render with: those in --theme-file, or
+// in the default theme file when it exists, or nil for the built-in theme.
+// Problems with individual colors are printed to stderr as warnings.
+func loadTheme() (*renderer.Theme, error) {
+	path := themeFile
+	if path == "" {
+		def, err := renderer.DefaultThemeFile()
+		if err != nil {
+			return nil, nil
+		}
+		if _, err := os.Stat(def); errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		path = def
+	}
+
+	theme, warnings, err := renderer.LoadThemeFile(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+	}
+	return theme, nil
+}
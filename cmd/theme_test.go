@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/simota/yam/internal/renderer"
+)
+
+func TestLoadThemeFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "theme.yaml")
+	content := `key: "#FFD700"
+string: {light: "#0A3069", dark: "#A5D6FF"}
+number: not-a-color
+bogus: "#000000"
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	theme, warnings, err := renderer.LoadThemeFile(path)
+	if err != nil {
+		t.Fatalf("LoadThemeFile failed: %v", err)
+	}
+	if got := theme.Key.GetForeground(); got != lipgloss.Color("#FFD700") {
+		t.Errorf("expected key color #FFD700, got %v", got)
+	}
+	if got := theme.String.GetForeground(); got != (lipgloss.AdaptiveColor{Light: "#0A3069", Dark: "#A5D6FF"}) {
+		t.Errorf("expected adaptive string color, got %v", got)
+	}
+	if got, want := theme.Number.GetForeground(), renderer.DefaultTheme().Number.GetForeground(); got != want {
+		t.Errorf("expected the default number color %v for an invalid color, got %v", want, got)
+	}
+
+	if len(warnings) != 2 {
+		t.Fatalf("expected 2 warnings, got %q", warnings)
+	}
+	if !strings.Contains(warnings[0], ":3: number: invalid color") {
+		t.Errorf("expected a warning for the number color, got %q", warnings[0])
+	}
+	if !strings.Contains(warnings[1], `unknown element "bogus"`) {
+		t.Errorf("expected a warning for the unknown element, got %q", warnings[1])
+	}
+}
+
+func TestLoadTheme_DefaultFile(t *testing.T) {
+	config := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", config)
+	t.Setenv("HOME", config)
+	themeFile = ""
+
+	// Without a theme file, the built-in theme is used
+	theme, err := loadTheme()
+	if err != nil || theme != nil {
+		t.Fatalf("expected no theme and no error, got %v, %v", theme, err)
+	}
+
+	def, err := renderer.DefaultThemeFile()
+	if err != nil {
+		t.Fatalf("DefaultThemeFile failed: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(def), 0o755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(def, []byte("comment: \"#888\"\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	theme, err = loadTheme()
+	if err != nil {
+		t.Fatalf("loadTheme failed: %v", err)
+	}
+	if theme == nil || theme.Comment.GetForeground() != lipgloss.Color("#888") {
+		t.Errorf("expected the comment color from the default theme file")
+	}
+
+	// An explicit --theme-file must exist
+	themeFile = filepath.Join(config, "missing.yaml")
+	defer func() { themeFile = "" }()
+	if _, err := loadTheme(); err == nil {
+		t.Error("expected an error for a missing --theme-file")
+	}
+}
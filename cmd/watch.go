@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDelay is how long a burst of file events is collected before it is
+// reported as one change; editors often write a file in several steps
+const watchDelay = 100 * time.Millisecond
+
+// clearScreen moves the cursor home and clears the terminal
+const clearScreen = "\x1b[H\x1b[2J"
+
+// watchFile reports changes to filename on the returned channel, one per
+// burst of events. The directory is watched rather than the file, so that
+// editors which save by replacing the file keep being followed. stop ends
+// the watch and closes the channel.
+func watchFile(filename string) (changes <-chan struct{}, stop func() error, err error) {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to watch %s: %w", filename, err)
+	}
+	if err := watcher.Add(filepath.Dir(abs)); err != nil {
+		watcher.Close()
+		return nil, nil, fmt.Errorf("failed to watch %s: %w", filename, err)
+	}
+
+	ch := make(chan struct{}, 1)
+	go func() {
+		defer close(ch)
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) != abs || !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+					continue
+				}
+				// Collect the rest of the burst
+				timer := time.After(watchDelay)
+			burst:
+				for {
+					select {
+					case _, ok := <-watcher.Events:
+						if !ok {
+							return
+						}
+					case <-timer:
+						break burst
+					}
+				}
+				// A change not yet picked up covers this one too
+				select {
+				case ch <- struct{}{}:
+				default:
+				}
+			case _, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+			}
+		}
+	}()
+	return ch, watcher.Close, nil
+}
+
+// runWatch prints the output of render, and again after every change to
+// filename, on a cleared screen. Errors such as a syntax error in the middle
+// of an edit are shown in place of the output and do not end the watch.
+func runWatch(filename string, render func() (string, error)) error {
+	changes, stop, err := watchFile(filename)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	for {
+		output, err := render()
+		fmt.Print(clearScreen)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+		} else {
+			fmt.Print(output)
+		}
+		fmt.Printf("\nWatching %s for changes (Ctrl+C to quit)\n", filename)
+		if _, ok := <-changes; !ok {
+			return nil
+		}
+	}
+}
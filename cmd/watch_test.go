@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(file, []byte("a: 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	changes, stop, err := watchFile(file)
+	if err != nil {
+		t.Fatalf("watchFile failed: %v", err)
+	}
+	defer stop()
+
+	expect := func(what string, changed bool) {
+		t.Helper()
+		select {
+		case <-changes:
+			if !changed {
+				t.Errorf("%s: unexpected change", what)
+			}
+		case <-time.After(10 * watchDelay):
+			if changed {
+				t.Errorf("%s: expected a change", what)
+			}
+		}
+	}
+
+	// Other files in the directory are not changes
+	if err := os.WriteFile(filepath.Join(dir, "other.yaml"), []byte("b: 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	expect("other file", false)
+
+	if err := os.WriteFile(file, []byte("a: 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	expect("write", true)
+
+	// Editors that save by renaming a new file over the old one
+	tmp := filepath.Join(dir, "config.yaml.tmp")
+	if err := os.WriteFile(tmp, []byte("a: 3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		t.Fatal(err)
+	}
+	expect("rename", true)
+
+	stop()
+	if _, ok := <-changes; ok {
+		t.Error("expected stop to close the channel")
+	}
+}
@@ -2,14 +2,80 @@ package diff
 
 import (
 	"fmt"
+	"hash/fnv"
 	"sort"
 
 	"github.com/simota/yam/internal/parser"
 )
 
+// Options configures comparison behavior
+type Options struct {
+	// RootPath is the JSONPath-like path of the compared nodes, used as the
+	// prefix for every DiffNode.Path (default "$")
+	RootPath string
+
+	// MaxDepth stops recursion below this depth (0 = unlimited). Containers
+	// at the limit are compared by a hash of their serialized form and
+	// reported as a single modification if they differ.
+	MaxDepth int
+
+	// SetFields lists the paths (in DiffNode.Path form, e.g. "$.tags") of
+	// sequences compared as unordered multisets: elements present on both
+	// sides are unchanged regardless of position
+	SetFields []string
+
+	// DetectReorder reports mappings whose common keys appear in a different
+	// order as DiffReordered. A reordered mapping with changes below it is
+	// reported as DiffModified, with the changes reported as usual.
+	DetectReorder bool
+
+	// Order is the order in which mapping keys are compared and reported
+	// (default: alphabetical)
+	Order KeyOrder
+
+	// NullIsAbsent treats a null value and a missing key or item as equal,
+	// so filling in explicit nulls is not reported as a change
+	NullIsAbsent bool
+}
+
+// KeyOrder selects the order of mapping keys in a diff
+type KeyOrder int
+
+const (
+	OrderAlpha  KeyOrder = iota // Alphabetical, independent of either file
+	OrderSource                 // As in the right (new) file, then left-only keys as in the left file
+)
+
+// ParseKeyOrder parses a key order name: alpha or source
+func ParseKeyOrder(name string) (KeyOrder, error) {
+	switch name {
+	case "alpha":
+		return OrderAlpha, nil
+	case "source":
+		return OrderSource, nil
+	}
+	return OrderAlpha, fmt.Errorf("unknown key order %q (want alpha or source)", name)
+}
+
+// DefaultOptions returns the default comparison options
+func DefaultOptions() Options {
+	return Options{
+		RootPath: "$",
+	}
+}
+
 // Compare compares two YamNode trees and returns a DiffResult.
 // It handles nil inputs gracefully and produces a structured diff tree.
 func Compare(left, right *parser.YamNode) *DiffResult {
+	return CompareWithOptions(left, right, DefaultOptions())
+}
+
+// CompareWithOptions compares two YamNode trees using the given options
+func CompareWithOptions(left, right *parser.YamNode, opts Options) *DiffResult {
+	if opts.RootPath == "" {
+		opts.RootPath = "$"
+	}
+
 	// Handle nil inputs
 	if left == nil && right == nil {
 		return &DiffResult{
@@ -19,7 +85,7 @@ func Compare(left, right *parser.YamNode) *DiffResult {
 	}
 
 	// Create root DiffNode by comparing the nodes
-	root := compareNodes(left, right, "$")
+	root := compareNodes(left, right, opts.RootPath, 0, opts)
 
 	// Calculate summary by walking the diff tree
 	summary := calculateSummary(root)
@@ -33,13 +99,23 @@ func Compare(left, right *parser.YamNode) *DiffResult {
 }
 
 // compareNodes recursively compares two YamNodes and returns a DiffNode.
-// The path parameter represents the JSONPath-like path to the current node.
-func compareNodes(left, right *parser.YamNode, path string) *DiffNode {
+// The path parameter represents the JSONPath-like path to the current node
+// and depth its nesting level below the compared root.
+func compareNodes(left, right *parser.YamNode, path string, depth int, opts Options) *DiffNode {
 	// Handle nil cases
 	if left == nil && right == nil {
 		return nil
 	}
 
+	if opts.NullIsAbsent && isNullOrMissing(left) && isNullOrMissing(right) {
+		return &DiffNode{
+			Left:  left,
+			Right: right,
+			Type:  DiffUnchanged,
+			Path:  path,
+		}
+	}
+
 	if left == nil {
 		// Node was added (exists only in right)
 		return &DiffNode{
@@ -60,6 +136,21 @@ func compareNodes(left, right *parser.YamNode, path string) *DiffNode {
 		}
 	}
 
+	// Depth limit reached: compare containers as a whole
+	if opts.MaxDepth > 0 && depth >= opts.MaxDepth && left.IsContainer() && right.IsContainer() {
+		diffType := DiffUnchanged
+		if shallowHash(left) != shallowHash(right) {
+			diffType = DiffModified
+		}
+		return &DiffNode{
+			Left:      left,
+			Right:     right,
+			Type:      diffType,
+			Path:      path,
+			Truncated: true,
+		}
+	}
+
 	// Both nodes exist - compare based on kind
 	if left.Kind() == parser.KindMapping && right.Kind() == parser.KindMapping {
 		// Build maps of children by key for efficient lookup
@@ -73,21 +164,7 @@ func compareNodes(left, right *parser.YamNode, path string) *DiffNode {
 			rightByKey[child.Key] = child
 		}
 
-		// Collect all unique keys from both maps and sort them for consistent output
-		allKeysMap := make(map[string]bool)
-		for k := range leftByKey {
-			allKeysMap[k] = true
-		}
-		for k := range rightByKey {
-			allKeysMap[k] = true
-		}
-
-		// Sort keys for consistent output order
-		allKeys := make([]string, 0, len(allKeysMap))
-		for k := range allKeysMap {
-			allKeys = append(allKeys, k)
-		}
-		sort.Strings(allKeys)
+		allKeys := mappingKeys(left, right, opts.Order)
 
 		// Compare each key
 		var children []*DiffNode
@@ -96,7 +173,7 @@ func compareNodes(left, right *parser.YamNode, path string) *DiffNode {
 			leftChild := leftByKey[key]
 			rightChild := rightByKey[key]
 			childPath := path + "." + key
-			childDiff := compareNodes(leftChild, rightChild, childPath)
+			childDiff := compareNodes(leftChild, rightChild, childPath, depth+1, opts)
 			if childDiff != nil {
 				children = append(children, childDiff)
 				if childDiff.Type != DiffUnchanged {
@@ -110,6 +187,9 @@ func compareNodes(left, right *parser.YamNode, path string) *DiffNode {
 		if hasChanges {
 			diffType = DiffModified
 		}
+		if !hasChanges && opts.DetectReorder && keyOrderDiffers(left, right) {
+			diffType = DiffReordered
+		}
 
 		return &DiffNode{
 			Left:     left,
@@ -121,6 +201,9 @@ func compareNodes(left, right *parser.YamNode, path string) *DiffNode {
 	}
 
 	// Sequence comparison
+	if left.Kind() == parser.KindSequence && right.Kind() == parser.KindSequence && opts.isSetField(path) {
+		return compareSets(left, right, path, depth, opts)
+	}
 	if left.Kind() == parser.KindSequence && right.Kind() == parser.KindSequence {
 		maxLen := len(left.Children)
 		if len(right.Children) > maxLen {
@@ -140,7 +223,7 @@ func compareNodes(left, right *parser.YamNode, path string) *DiffNode {
 			}
 
 			childPath := fmt.Sprintf("%s[%d]", path, i)
-			childDiff := compareNodes(leftChild, rightChild, childPath)
+			childDiff := compareNodes(leftChild, rightChild, childPath, depth+1, opts)
 			if childDiff != nil {
 				children = append(children, childDiff)
 				if childDiff.Type != DiffUnchanged {
@@ -166,14 +249,16 @@ func compareNodes(left, right *parser.YamNode, path string) *DiffNode {
 	// Handle Scalar nodes
 	if left.Kind() == parser.KindScalar && right.Kind() == parser.KindScalar {
 		diffType := DiffUnchanged
-		if left.Value() != right.Value() {
+		typeChanged := left.InferType() != right.InferType()
+		if typeChanged || !parser.ScalarsEqual(left, right) {
 			diffType = DiffModified
 		}
 		return &DiffNode{
-			Left:  left,
-			Right: right,
-			Type:  diffType,
-			Path:  path,
+			Left:        left,
+			Right:       right,
+			Type:        diffType,
+			Path:        path,
+			TypeChanged: typeChanged,
 		}
 	}
 
@@ -198,7 +283,7 @@ func compareNodes(left, right *parser.YamNode, path string) *DiffNode {
 		if len(right.Children) > 0 {
 			rightChild = right.Children[0]
 		}
-		return compareNodes(leftChild, rightChild, path)
+		return compareNodes(leftChild, rightChild, path, depth, opts)
 	}
 
 	// Fallback for any other cases
@@ -210,6 +295,139 @@ func compareNodes(left, right *parser.YamNode, path string) *DiffNode {
 	}
 }
 
+// isNullOrMissing reports whether node is missing or a null scalar
+func isNullOrMissing(node *parser.YamNode) bool {
+	return node == nil || node.Kind() == parser.KindScalar && node.InferType() == parser.TypeNull
+}
+
+// keyOrderDiffers reports whether the keys present in both mappings appear
+// in a different order
+func keyOrderDiffers(left, right *parser.YamNode) bool {
+	leftKeys := commonKeys(left, right)
+	rightKeys := commonKeys(right, left)
+	if len(leftKeys) != len(rightKeys) {
+		return true // duplicate keys
+	}
+	for i := range leftKeys {
+		if leftKeys[i] != rightKeys[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// commonKeys returns the keys of mapping that also exist in other, in order
+func commonKeys(mapping, other *parser.YamNode) []string {
+	otherKeys := make(map[string]bool, len(other.Children))
+	for _, child := range other.Children {
+		otherKeys[child.Key] = true
+	}
+	var keys []string
+	for _, child := range mapping.Children {
+		if otherKeys[child.Key] {
+			keys = append(keys, child.Key)
+		}
+	}
+	return keys
+}
+
+// isSetField reports whether the sequence at path is compared as a set
+func (o Options) isSetField(path string) bool {
+	for _, field := range o.SetFields {
+		if field == path {
+			return true
+		}
+	}
+	return false
+}
+
+// compareSets compares two sequences as multisets. Each left element is
+// paired with an equal, not yet matched right element; unpaired left
+// elements are removed and unpaired right elements added.
+func compareSets(left, right *parser.YamNode, path string, depth int, opts Options) *DiffNode {
+	// Indices of unmatched right elements by canonical value, in order
+	available := make(map[string][]int)
+	for j, child := range right.Children {
+		v := canonicalValue(child)
+		available[v] = append(available[v], j)
+	}
+
+	var children []*DiffNode
+	matched := make(map[int]bool)
+	hasChanges := false
+	for i, leftChild := range left.Children {
+		childPath := fmt.Sprintf("%s[%d]", path, i)
+		v := canonicalValue(leftChild)
+		if idx := available[v]; len(idx) > 0 {
+			available[v] = idx[1:]
+			matched[idx[0]] = true
+			children = append(children, compareNodes(leftChild, right.Children[idx[0]], childPath, depth+1, opts))
+			continue
+		}
+		children = append(children, compareNodes(leftChild, nil, childPath, depth+1, opts))
+		hasChanges = true
+	}
+	for j, rightChild := range right.Children {
+		if matched[j] {
+			continue
+		}
+		childPath := fmt.Sprintf("%s[%d]", path, j)
+		children = append(children, compareNodes(nil, rightChild, childPath, depth+1, opts))
+		hasChanges = true
+	}
+
+	diffType := DiffUnchanged
+	if hasChanges {
+		diffType = DiffModified
+	}
+
+	return &DiffNode{
+		Left:     left,
+		Right:    right,
+		Type:     diffType,
+		Children: children,
+		Path:     path,
+	}
+}
+
+// mappingKeys returns the keys of both mappings, each once, in order
+func mappingKeys(left, right *parser.YamNode, order KeyOrder) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, mapping := range []*parser.YamNode{right, left} {
+		for _, child := range mapping.Children {
+			if !seen[child.Key] {
+				seen[child.Key] = true
+				keys = append(keys, child.Key)
+			}
+		}
+	}
+
+	// Sort keys for consistent output order
+	if order == OrderAlpha {
+		sort.Strings(keys)
+	}
+	return keys
+}
+
+// canonicalValue returns a node's value serialized as JSON, so that trees
+// parsed from YAML and JSON compare alike and key order is ignored
+func canonicalValue(node *parser.YamNode) string {
+	data, err := parser.ToJSON(node, false)
+	if err != nil {
+		// Unserializable content never compares equal to anything else
+		return fmt.Sprintf("%p", node)
+	}
+	return string(data)
+}
+
+// shallowHash returns a hash of a node's serialized form (see canonicalValue)
+func shallowHash(node *parser.YamNode) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(canonicalValue(node)))
+	return h.Sum64()
+}
+
 // calculateSummary walks the DiffNode tree and counts differences.
 func calculateSummary(root *DiffNode) DiffSummary {
 	if root == nil {
@@ -219,7 +437,12 @@ func calculateSummary(root *DiffNode) DiffSummary {
 	var summary DiffSummary
 	walkDiffTree(root, &summary)
 
-	summary.Total = summary.Added + summary.Removed + summary.Modified
+	summary.Total = summary.Added + summary.Removed + summary.Modified + summary.Reordered
+	for _, child := range root.Children {
+		var counts DiffSummary
+		walkDiffTree(child, &counts)
+		summary.addByKey(getNodeKey(child), counts)
+	}
 	return summary
 }
 
@@ -236,6 +459,8 @@ func walkDiffTree(node *DiffNode, summary *DiffSummary) {
 		summary.Removed++
 	case DiffModified:
 		summary.Modified++
+	case DiffReordered:
+		summary.Reordered++
 	}
 
 	// Recursively process children
@@ -1,6 +1,7 @@
 package diff
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/simota/yam/internal/parser"
@@ -704,3 +705,333 @@ func TestCompare_EmptySequence(t *testing.T) {
 		t.Errorf("expected Total=0, got %d", result.Summary.Total)
 	}
 }
+
+// Test type changes
+
+func TestCompare_IntToStringTypeDrift(t *testing.T) {
+	p := parser.New()
+	left, err := p.ParseString("port: 3\nname: app\n")
+	if err != nil {
+		t.Fatalf("failed to parse left: %v", err)
+	}
+	right, err := p.ParseString("port: \"3\"\nname: app\n")
+	if err != nil {
+		t.Fatalf("failed to parse right: %v", err)
+	}
+
+	result := Compare(left, right)
+
+	var portDiff, nameDiff *DiffNode
+	for _, child := range result.Root.Children {
+		switch child.Path {
+		case "$.port":
+			portDiff = child
+		case "$.name":
+			nameDiff = child
+		}
+	}
+	if portDiff == nil || nameDiff == nil {
+		t.Fatal("expected to find port and name diff nodes")
+	}
+	if portDiff.Type != DiffModified {
+		t.Errorf("expected port Type=DiffModified, got %v", portDiff.Type)
+	}
+	if !portDiff.TypeChanged {
+		t.Errorf("expected port to be flagged as a type change")
+	}
+	if nameDiff.TypeChanged {
+		t.Errorf("expected name not to be flagged as a type change")
+	}
+
+	output := Render(result)
+	if !strings.Contains(output, "<int>→<str>") {
+		t.Errorf("expected type change annotation in output, got:\n%s", output)
+	}
+}
+
+func TestCompare_CrossFormatScalars(t *testing.T) {
+	p := parser.New()
+	left, err := p.ParseString("count: 3\nratio: 1.50\nmask: 0x1F\nenabled: True\nnothing: ~\nname: \"3\"\n")
+	if err != nil {
+		t.Fatalf("failed to parse YAML: %v", err)
+	}
+	right, err := p.ParseJSON(strings.NewReader(`{"count": 3.0, "ratio": 1.5, "mask": 31, "enabled": true, "nothing": null, "name": 3}`))
+	if err != nil {
+		t.Fatalf("failed to parse JSON: %v", err)
+	}
+
+	result := Compare(left, right)
+
+	for _, child := range result.Root.Children {
+		expected := DiffUnchanged
+		if child.Path == "$.name" {
+			expected = DiffModified // string vs number
+		}
+		if child.Type != expected {
+			t.Errorf("%s: expected Type=%v, got %v", child.Path, expected, child.Type)
+		}
+	}
+	if len(result.Root.Children) != 6 {
+		t.Errorf("expected 6 children, got %d", len(result.Root.Children))
+	}
+}
+
+func TestCompareWithOptions_RootPath(t *testing.T) {
+	left := makeMappingNode(makeKeyedNode("image", "app:1"))
+	right := makeMappingNode(makeKeyedNode("image", "app:2"))
+
+	opts := DefaultOptions()
+	opts.RootPath = "$.spec"
+	result := CompareWithOptions(left, right, opts)
+
+	if result.Root.Path != "$.spec" {
+		t.Errorf("expected root path '$.spec', got '%s'", result.Root.Path)
+	}
+	if len(result.Root.Children) != 1 || result.Root.Children[0].Path != "$.spec.image" {
+		t.Errorf("expected child path '$.spec.image', got %v", result.Root.Children)
+	}
+}
+
+func TestCompareWithOptions_MaxDepth(t *testing.T) {
+	nested := func(port string) *parser.YamNode {
+		server := makeMappingNode(makeKeyedNode("port", port))
+		server.Key = "server"
+		same := makeMappingNode(makeKeyedNode("level", "info"))
+		same.Key = "logging"
+		return makeMappingNode(server, same)
+	}
+
+	opts := DefaultOptions()
+	opts.MaxDepth = 1
+	result := CompareWithOptions(nested("80"), nested("8080"), opts)
+
+	if result.Summary.Modified != 2 {
+		t.Errorf("expected root and server modified, got %+v", result.Summary)
+	}
+
+	server := result.Root.Children[1]
+	if server.Path != "$.server" || server.Type != DiffModified || !server.Truncated {
+		t.Errorf("expected truncated modified $.server, got %+v", server)
+	}
+	if len(server.Children) != 0 {
+		t.Errorf("expected no children below max depth, got %d", len(server.Children))
+	}
+
+	logging := result.Root.Children[0]
+	if logging.Type != DiffUnchanged || !logging.Truncated {
+		t.Errorf("expected truncated unchanged $.logging, got %+v", logging)
+	}
+}
+
+func TestCompareWithOptions_SetFields(t *testing.T) {
+	tags := func(values ...string) *parser.YamNode {
+		var items []*parser.YamNode
+		for _, v := range values {
+			items = append(items, makeScalarNode(v))
+		}
+		seq := makeSequenceNode(items...)
+		seq.Key = "tags"
+		return makeMappingNode(seq)
+	}
+
+	left := tags("web", "prod", "eu", "eu")
+	right := tags("eu", "web", "us", "prod")
+
+	opts := DefaultOptions()
+	opts.SetFields = []string{"$.tags"}
+	result := CompareWithOptions(left, right, opts)
+
+	seq := result.Root.Children[0]
+	var added, removed []string
+	for _, child := range seq.Children {
+		switch child.Type {
+		case DiffAdded:
+			added = append(added, child.Right.Value()+" "+child.Path)
+		case DiffRemoved:
+			removed = append(removed, child.Left.Value()+" "+child.Path)
+		case DiffModified:
+			t.Errorf("unexpected modified element %s", child.Path)
+		}
+	}
+
+	if len(added) != 1 || added[0] != "us $.tags[2]" {
+		t.Errorf("expected only 'us' added, got %v", added)
+	}
+	if len(removed) != 1 || removed[0] != "eu $.tags[3]" {
+		t.Errorf("expected only the duplicate 'eu' removed, got %v", removed)
+	}
+
+	// Without the option, positions matter
+	if ordered := Compare(left, right); ordered.Summary.Modified < 3 {
+		t.Errorf("expected positional diff to report modifications, got %+v", ordered.Summary)
+	}
+}
+
+func TestCompareWithOptions_DetectReorder(t *testing.T) {
+	left := makeMappingNode(
+		makeKeyedNode("a", "1"),
+		makeKeyedNode("b", "2"),
+		makeKeyedNode("c", "3"),
+	)
+	right := makeMappingNode(
+		makeKeyedNode("b", "2"),
+		makeKeyedNode("a", "1"),
+		makeKeyedNode("d", "4"),
+	)
+
+	// Without the option, order is ignored
+	if result := Compare(left, right); result.Summary.Reordered != 0 || result.Root.Type != DiffModified {
+		t.Errorf("expected no reorder without DetectReorder, got %+v", result.Summary)
+	}
+
+	opts := DefaultOptions()
+	opts.DetectReorder = true
+	result := CompareWithOptions(left, right, opts)
+
+	// Changes below a reordered mapping keep it modified
+	if result.Root.Type != DiffModified {
+		t.Errorf("expected reordered root with changes to be modified, got %v", result.Root.Type)
+	}
+	if result.Summary.Reordered != 0 || result.Summary.Added != 1 || result.Summary.Removed != 1 || result.Summary.Modified != 1 {
+		t.Errorf("unexpected summary %+v", result.Summary)
+	}
+
+	reordered := makeMappingNode(
+		makeKeyedNode("c", "3"),
+		makeKeyedNode("b", "2"),
+		makeKeyedNode("a", "1"),
+	)
+	result = CompareWithOptions(left, reordered, opts)
+	if result.Root.Type != DiffReordered {
+		t.Errorf("expected root to be reordered, got %v", result.Root.Type)
+	}
+	if result.Summary.Reordered != 1 || result.Summary.Total != 1 {
+		t.Errorf("expected reorder to count towards the total, got %+v", result.Summary)
+	}
+
+	// Only keys on both sides are considered
+	same := makeMappingNode(makeKeyedNode("a", "1"), makeKeyedNode("x", "9"), makeKeyedNode("b", "2"))
+	if result := CompareWithOptions(left, same, opts); result.Root.Type == DiffReordered {
+		t.Error("expected added and removed keys not to count as a reorder")
+	}
+}
+
+func TestCompareWithOptions_NullIsAbsent(t *testing.T) {
+	p := parser.New()
+	left, err := p.ParseString("a: null\nb: 1\nc: [1, ~]\ne: ~\n")
+	if err != nil {
+		t.Fatalf("ParseString failed: %v", err)
+	}
+	right, err := p.ParseString("b: 1\nc: [1]\nd:\ne: x\n")
+	if err != nil {
+		t.Fatalf("ParseString failed: %v", err)
+	}
+
+	// Without the option, nulls are values like any other
+	if result := Compare(left, right); result.Summary.Added != 1 || result.Summary.Removed != 2 || result.Summary.Modified != 3 {
+		t.Errorf("expected 1 added, 2 removed and 3 modified without NullIsAbsent, got %+v", result.Summary)
+	}
+
+	opts := DefaultOptions()
+	opts.NullIsAbsent = true
+	result := CompareWithOptions(left, right, opts)
+
+	// Only e changed from null to a value (and with it the root)
+	if result.Summary.Added != 0 || result.Summary.Removed != 0 || result.Summary.Modified != 2 {
+		t.Errorf("expected only e modified, got %+v", result.Summary)
+	}
+	for _, child := range result.Root.Children {
+		if want := child.Path == "$.e"; (child.Type != DiffUnchanged) != want {
+			t.Errorf("%s: unexpected change type %v", child.Path, child.Type)
+		}
+	}
+}
+
+func TestCompareWithOptions_Order(t *testing.T) {
+	left := makeMappingNode(
+		makeKeyedNode("z", "1"),
+		makeKeyedNode("a", "2"),
+		makeKeyedNode("m", "3"),
+	)
+	right := makeMappingNode(
+		makeKeyedNode("z", "1"),
+		makeKeyedNode("b", "5"),
+		makeKeyedNode("a", "3"),
+	)
+
+	tests := []struct {
+		order    KeyOrder
+		expected []string
+	}{
+		{OrderAlpha, []string{"$.a", "$.b", "$.m", "$.z"}},
+		{OrderSource, []string{"$.z", "$.b", "$.a", "$.m"}},
+	}
+
+	for _, tt := range tests {
+		opts := DefaultOptions()
+		opts.Order = tt.order
+		result := CompareWithOptions(left, right, opts)
+
+		var paths []string
+		for _, child := range result.Root.Children {
+			paths = append(paths, child.Path)
+		}
+		if strings.Join(paths, " ") != strings.Join(tt.expected, " ") {
+			t.Errorf("order %d: expected %v, got %v", tt.order, tt.expected, paths)
+		}
+	}
+}
+
+func TestRenderWith_Only(t *testing.T) {
+	p := parser.New()
+	left, err := p.ParseString("a:\n  x: 1\n  y: 2\nb: 3\n")
+	if err != nil {
+		t.Fatalf("failed to parse left: %v", err)
+	}
+	right, err := p.ParseString("a:\n  x: 5\nb: 3\nd: new\n")
+	if err != nil {
+		t.Fatalf("failed to parse right: %v", err)
+	}
+	result := Compare(left, right)
+
+	opts := DefaultRenderOptions()
+	opts.Only = []DiffType{DiffRemoved}
+	out := RenderWith(result, opts)
+
+	for _, want := range []string{"~ a:", "-   y: 2", "(showing only removed)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"x:", "d:"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("expected %q to be filtered out, got:\n%s", unwanted, out)
+		}
+	}
+	if !strings.Contains(out, "1 added, 1 removed") {
+		t.Errorf("expected the summary to keep the full counts, got:\n%s", out)
+	}
+}
+
+func TestRenderWith_NoHeaderNoSummary(t *testing.T) {
+	p := parser.New()
+	left, err := p.ParseString("a: 1\n")
+	if err != nil {
+		t.Fatalf("failed to parse left: %v", err)
+	}
+	right, err := p.ParseString("a: 2\n")
+	if err != nil {
+		t.Fatalf("failed to parse right: %v", err)
+	}
+	result := Compare(left, right)
+	result.LeftFile, result.RightFile = "old.yaml", "new.yaml"
+
+	opts := DefaultRenderOptions()
+	opts.NoHeader = true
+	opts.NoSummary = true
+	out := RenderWith(result, opts)
+
+	if !strings.HasPrefix(out, "~ a: 1 → 2") || strings.Count(out, "\n") != 1 {
+		t.Errorf("expected only the change line, got:\n%s", out)
+	}
+}
@@ -0,0 +1,9 @@
+// Package diff compares two YamNode trees structurally.
+//
+// Compare and CompareWithOptions return a DiffResult: a tree of DiffNode
+// values mirroring the compared documents, where each node records its
+// JSONPath-like Path, its DiffType, and the Left and Right YamNodes, plus a
+// DiffSummary of the counts. These types are the package's API and do not
+// depend on the CLI; Render and RenderWith format a result for terminals,
+// and MarshalJSON serializes it with a stable schema for other tools.
+package diff
@@ -0,0 +1,93 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/simota/yam/internal/parser"
+)
+
+// DocumentPair is a pair of documents to compare. A side is nil when the
+// document exists in only one of the files.
+type DocumentPair struct {
+	Left  *parser.YamNode
+	Right *parser.YamNode
+	Label string // e.g. "document 2" or "kind=Service, metadata.name=web"
+}
+
+// PairDocuments pairs the documents of two multi-document files. Without
+// keys, documents are paired by index. With keys (paths such as
+// ".metadata.name"), documents are paired by the scalar values at those
+// paths; documents with the same values are paired in order, and documents
+// missing a key are paired by index among themselves.
+func PairDocuments(left, right []*parser.YamNode, keys []string) ([]DocumentPair, error) {
+	paths := make([]string, len(keys))
+	for i, key := range keys {
+		if !strings.HasPrefix(key, ".") {
+			key = "." + key
+		}
+		if _, err := parser.ParsePath(key); err != nil {
+			return nil, fmt.Errorf("invalid match key %q: %w", keys[i], err)
+		}
+		paths[i] = key
+	}
+
+	leftIDs := documentIDs(left, paths)
+	rightIDs := documentIDs(right, paths)
+
+	// Queue the right documents by identity so duplicates pair in order
+	queue := make(map[string][]int)
+	for j, id := range rightIDs {
+		queue[id] = append(queue[id], j)
+	}
+
+	var pairs []DocumentPair
+	paired := make([]bool, len(right))
+	for i, id := range leftIDs {
+		pair := DocumentPair{Left: left[i], Label: id}
+		if js := queue[id]; len(js) > 0 {
+			pair.Right = right[js[0]]
+			paired[js[0]] = true
+			queue[id] = js[1:]
+		}
+		pairs = append(pairs, pair)
+	}
+	for j, id := range rightIDs {
+		if !paired[j] {
+			pairs = append(pairs, DocumentPair{Right: right[j], Label: id})
+		}
+	}
+	return pairs, nil
+}
+
+// documentIDs returns the identity of each document: the values at paths,
+// or its position among the documents that lack them
+func documentIDs(docs []*parser.YamNode, paths []string) []string {
+	ids := make([]string, len(docs))
+	unkeyed := 0
+	for i, doc := range docs {
+		if id, ok := documentKey(doc, paths); ok {
+			ids[i] = id
+			continue
+		}
+		ids[i] = fmt.Sprintf("document %d", unkeyed)
+		unkeyed++
+	}
+	return ids
+}
+
+// documentKey joins the scalar values at paths as "path=value" pairs
+func documentKey(doc *parser.YamNode, paths []string) (string, bool) {
+	if len(paths) == 0 {
+		return "", false
+	}
+	parts := make([]string, len(paths))
+	for i, path := range paths {
+		node, err := parser.GetByPath(doc, path)
+		if err != nil || node.Kind() != parser.KindScalar {
+			return "", false
+		}
+		parts[i] = path[1:] + "=" + node.Value()
+	}
+	return strings.Join(parts, ", "), true
+}
@@ -0,0 +1,81 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/simota/yam/internal/parser"
+)
+
+func parseDocuments(t *testing.T, input string) []*parser.YamNode {
+	t.Helper()
+	docs, err := parser.New().ParseAll(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseAll failed: %v", err)
+	}
+	return docs
+}
+
+func TestPairDocuments(t *testing.T) {
+	left := parseDocuments(t, "kind: Service\nname: web\n---\nkind: Deployment\nname: web\n---\nkind: ConfigMap\nname: old\n---\nnote: unkeyed\n")
+	right := parseDocuments(t, "kind: Deployment\nname: web\n---\nkind: Service\nname: web\n---\nnote: unkeyed\n---\nkind: Secret\nname: s\n")
+
+	tests := []struct {
+		name string
+		keys []string
+		same bool     // paired documents are identical
+		want []string // label plus which sides are present
+	}{
+		{"by position", nil, false, []string{"document 0 LR", "document 1 LR", "document 2 LR", "document 3 LR"}},
+		{"by key", []string{"kind", ".name"}, true, []string{
+			"kind=Service, name=web LR",
+			"kind=Deployment, name=web LR",
+			"kind=ConfigMap, name=old L",
+			"document 0 LR",
+			"kind=Secret, name=s R",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pairs, err := PairDocuments(left, right, tt.keys)
+			if err != nil {
+				t.Fatalf("PairDocuments failed: %v", err)
+			}
+			var got []string
+			for _, p := range pairs {
+				sides := ""
+				if p.Left != nil {
+					sides += "L"
+				}
+				if p.Right != nil {
+					sides += "R"
+				}
+				got = append(got, p.Label+" "+sides)
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(tt.want, "\n"), strings.Join(got, "\n"))
+			}
+			for _, p := range pairs {
+				if tt.same && p.Left != nil && p.Right != nil && Compare(p.Left, p.Right).Summary.Total != 0 {
+					t.Errorf("expected %s to pair identical documents", p.Label)
+				}
+			}
+		})
+	}
+
+	if _, err := PairDocuments(left, right, []string{".a[x]"}); err == nil {
+		t.Error("expected an error for an invalid key path")
+	}
+}
+
+func TestRenderWith_WholeDocument(t *testing.T) {
+	docs := parseDocuments(t, "kind: Secret\nname: s\n")
+	result := Compare(nil, docs[0])
+	out := Render(result)
+	for _, want := range []string{"+ kind: Secret", "+ name: s"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+}
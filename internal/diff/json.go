@@ -0,0 +1,161 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/simota/yam/internal/parser"
+)
+
+// JSONResult is the serialized form of a DiffResult produced by MarshalJSON.
+// The schema is stable: fields are only ever added.
+type JSONResult struct {
+	LeftFile  string        `json:"left_file,omitempty"`
+	RightFile string        `json:"right_file,omitempty"`
+	Summary   JSONSummary   `json:"summary"`
+	Changes   []*JSONChange `json:"changes"`
+}
+
+// JSONSummary is the serialized form of a DiffSummary
+type JSONSummary struct {
+	Added     int                    `json:"added"`
+	Removed   int                    `json:"removed"`
+	Modified  int                    `json:"modified"`
+	Reordered int                    `json:"reordered,omitempty"`
+	Total     int                    `json:"total"`
+	ByKey     map[string]JSONSummary `json:"by_key,omitempty"`
+}
+
+// NewJSONSummary converts a DiffSummary to its serializable form
+func NewJSONSummary(summary DiffSummary) JSONSummary {
+	out := JSONSummary{
+		Added:     summary.Added,
+		Removed:   summary.Removed,
+		Modified:  summary.Modified,
+		Reordered: summary.Reordered,
+		Total:     summary.Total,
+	}
+	for key, counts := range summary.ByKey {
+		if out.ByKey == nil {
+			out.ByKey = make(map[string]JSONSummary)
+		}
+		out.ByKey[key] = NewJSONSummary(counts)
+	}
+	return out
+}
+
+// MarshalSummaryJSON serializes a DiffSummary as a JSONSummary
+func MarshalSummaryJSON(summary DiffSummary) ([]byte, error) {
+	return json.Marshal(NewJSONSummary(summary))
+}
+
+// JSONChange describes one change. Left and Right hold the JSON value of
+// each side and are omitted when the node does not exist on that side; for
+// "reordered" mappings they hold the keys of each side in order.
+type JSONChange struct {
+	Path        string          `json:"path"`
+	Type        string          `json:"type"`
+	Left        json.RawMessage `json:"left,omitempty"`
+	Right       json.RawMessage `json:"right,omitempty"`
+	TypeChanged bool            `json:"type_changed,omitempty"`
+}
+
+// String returns the lowercase name of a diff type ("added", "modified", ...)
+func (t DiffType) String() string {
+	switch t {
+	case DiffAdded:
+		return "added"
+	case DiffRemoved:
+		return "removed"
+	case DiffModified:
+		return "modified"
+	case DiffReordered:
+		return "reordered"
+	default:
+		return "unchanged"
+	}
+}
+
+// ParseDiffType parses the name of a change type: added, removed, modified
+// or reordered
+func ParseDiffType(name string) (DiffType, error) {
+	for _, t := range []DiffType{DiffAdded, DiffRemoved, DiffModified, DiffReordered} {
+		if name == t.String() {
+			return t, nil
+		}
+	}
+	return DiffUnchanged, fmt.Errorf("unknown change type %q (want added, removed, modified or reordered)", name)
+}
+
+// MarshalJSON serializes a DiffResult as a JSONResult. Changes lists, in
+// tree order, every node where a change originates: added and removed
+// nodes, modified scalars, and containers that were replaced or compared
+// as a whole, and reordered mappings. Containers that are modified only
+// through their children are represented by those children.
+func MarshalJSON(result *DiffResult) ([]byte, error) {
+	return json.Marshal(NewJSONResult(result))
+}
+
+// NewJSONResult converts a DiffResult to its serializable form
+func NewJSONResult(result *DiffResult) *JSONResult {
+	out := &JSONResult{Changes: []*JSONChange{}}
+	if result == nil {
+		return out
+	}
+
+	out.LeftFile = result.LeftFile
+	out.RightFile = result.RightFile
+	out.Summary = NewJSONSummary(result.Summary)
+	collectJSONChanges(result.Root, &out.Changes)
+	return out
+}
+
+func collectJSONChanges(node *DiffNode, changes *[]*JSONChange) {
+	if node == nil {
+		return
+	}
+
+	if node.Type == DiffReordered {
+		*changes = append(*changes, &JSONChange{
+			Path:  node.Path,
+			Type:  node.Type.String(),
+			Left:  keyOrder(node.Left),
+			Right: keyOrder(node.Right),
+		})
+	} else if node.Type != DiffUnchanged && len(node.Children) == 0 {
+		*changes = append(*changes, &JSONChange{
+			Path:        node.Path,
+			Type:        node.Type.String(),
+			Left:        jsonValue(node.Left),
+			Right:       jsonValue(node.Right),
+			TypeChanged: node.TypeChanged,
+		})
+	}
+
+	for _, child := range node.Children {
+		collectJSONChanges(child, changes)
+	}
+}
+
+// jsonValue returns the JSON encoding of a node, or nil if it is absent
+func jsonValue(node *parser.YamNode) json.RawMessage {
+	if node == nil {
+		return nil
+	}
+	data, err := parser.ToJSON(node, false)
+	if err != nil {
+		// Values JSON cannot represent (e.g. .inf) are kept as strings
+		data, _ = json.Marshal(node.Value())
+	}
+	return data
+}
+
+// keyOrder returns the keys of a mapping, in order, as a JSON array
+func keyOrder(node *parser.YamNode) json.RawMessage {
+	keys := make([]string, len(node.Children))
+	for i, child := range node.Children {
+		keys[i] = child.Key
+	}
+	data, _ := json.Marshal(keys)
+	return data
+}
@@ -0,0 +1,98 @@
+package diff
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/simota/yam/internal/parser"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	left := makeMappingNode(
+		makeKeyedNode("host", "localhost"),
+		makeKeyedNode("port", "5432"),
+	)
+	right := makeMappingNode(
+		makeKeyedNode("host", "db.example.com"),
+		makeKeyedNode("ssl", "true"),
+	)
+	result := Compare(left, right)
+	result.LeftFile = "dev.yaml"
+	result.RightFile = "prod.yaml"
+
+	data, err := MarshalJSON(result)
+	if err != nil {
+		t.Fatalf("MarshalJSON failed: %v", err)
+	}
+
+	var out JSONResult
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, data)
+	}
+
+	if out.LeftFile != "dev.yaml" || out.RightFile != "prod.yaml" {
+		t.Errorf("unexpected file names: %s, %s", out.LeftFile, out.RightFile)
+	}
+	if out.Summary.Total != result.Summary.Total {
+		t.Errorf("expected total %d, got %d", result.Summary.Total, out.Summary.Total)
+	}
+
+	expected := []struct {
+		path, typ, left, right string
+	}{
+		{"$.host", "modified", `"localhost"`, `"db.example.com"`},
+		{"$.port", "removed", "5432", ""},
+		{"$.ssl", "added", "", "true"},
+	}
+	if len(out.Changes) != len(expected) {
+		t.Fatalf("expected %d changes, got %d:\n%s", len(expected), len(out.Changes), data)
+	}
+	for i, want := range expected {
+		got := out.Changes[i]
+		if got.Path != want.path || got.Type != want.typ || string(got.Left) != want.left || string(got.Right) != want.right {
+			t.Errorf("change %d: expected %+v, got path=%s type=%s left=%s right=%s",
+				i, want, got.Path, got.Type, got.Left, got.Right)
+		}
+	}
+}
+
+func TestMarshalJSON_NoChanges(t *testing.T) {
+	data, err := MarshalJSON(Compare(makeScalarNode("a"), makeScalarNode("a")))
+	if err != nil {
+		t.Fatalf("MarshalJSON failed: %v", err)
+	}
+	var out map[string]json.RawMessage
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if string(out["changes"]) != "[]" {
+		t.Errorf("expected empty changes array, got %s", out["changes"])
+	}
+}
+
+func TestMarshalSummaryJSON_ByKey(t *testing.T) {
+	p := parser.New()
+	left := parseSide(t, p, "spec:\n  a: 1\n  b: 2\nmeta:\n  x: 1\nlist: [1]\n")
+	right := parseSide(t, p, "spec:\n  a: 2\n  c: 2\nmeta:\n  x: 1\nlist: [1, 2]\n")
+
+	data, err := MarshalSummaryJSON(Compare(left, right).Summary)
+	if err != nil {
+		t.Fatalf("MarshalSummaryJSON failed: %v", err)
+	}
+	var out JSONSummary
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, data)
+	}
+
+	expected := map[string]JSONSummary{
+		"spec": {Added: 1, Removed: 1, Modified: 2, Total: 4},
+		"list": {Added: 1, Modified: 1, Total: 2},
+	}
+	if !reflect.DeepEqual(out.ByKey, expected) {
+		t.Errorf("expected by_key %+v, got %+v", expected, out.ByKey)
+	}
+	if out.Total != 7 {
+		t.Errorf("expected total 7 (including the root), got %d", out.Total)
+	}
+}
@@ -0,0 +1,55 @@
+package diff
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// listEscaper keeps each list entry on one line and its fields unambiguous
+var listEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// RenderList renders the changes of a result as tab-separated lines of
+// TYPE, PATH, OLD and NEW, one per change (see MarshalJSON for which nodes
+// are listed). Strings are written unquoted, other values as compact JSON,
+// and a side that does not exist is left empty.
+func RenderList(result *DiffResult) string {
+	var sb strings.Builder
+	for _, change := range NewJSONResult(result).Changes {
+		sb.WriteString(change.Type)
+		sb.WriteString("\t")
+		sb.WriteString(listEscaper.Replace(change.Path))
+		sb.WriteString("\t")
+		sb.WriteString(listValue(change.Left))
+		sb.WriteString("\t")
+		sb.WriteString(listValue(change.Right))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// RenderListJSON renders the changes of a result as JSON lines, one
+// JSONChange object per line
+func RenderListJSON(result *DiffResult) (string, error) {
+	var sb strings.Builder
+	for _, change := range NewJSONResult(result).Changes {
+		data, err := json.Marshal(change)
+		if err != nil {
+			return "", err
+		}
+		sb.Write(data)
+		sb.WriteString("\n")
+	}
+	return sb.String(), nil
+}
+
+// listValue formats one side of a change for RenderList
+func listValue(raw json.RawMessage) string {
+	if raw == nil {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return listEscaper.Replace(s)
+	}
+	return listEscaper.Replace(string(raw))
+}
@@ -0,0 +1,58 @@
+package diff
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRenderList(t *testing.T) {
+	left := makeMappingNode(
+		makeKeyedNode("host", "localhost"),
+		makeKeyedNode("port", "5432"),
+		makeKeyedNode("motd", "hello"),
+	)
+	right := makeMappingNode(
+		makeKeyedNode("host", "db.example.com"),
+		makeKeyedNode("motd", "hello\tworld\nbye"),
+		makeKeyedNode("ssl", "true"),
+	)
+	result := Compare(left, right)
+
+	expected := "modified\t$.host\tlocalhost\tdb.example.com\n" +
+		"modified\t$.motd\thello\thello\\tworld\\nbye\n" +
+		"removed\t$.port\t5432\t\n" +
+		"added\t$.ssl\t\ttrue\n"
+	if got := RenderList(result); got != expected {
+		t.Errorf("expected:\n%q\ngot:\n%q", expected, got)
+	}
+}
+
+func TestRenderListJSON(t *testing.T) {
+	left := makeMappingNode(makeKeyedNode("host", "localhost"))
+	right := makeMappingNode(makeKeyedNode("host", "db.example.com"))
+
+	out, err := RenderListJSON(Compare(left, right))
+	if err != nil {
+		t.Fatalf("RenderListJSON failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 line, got %q", out)
+	}
+	var change JSONChange
+	if err := json.Unmarshal([]byte(lines[0]), &change); err != nil {
+		t.Fatalf("invalid JSON line: %v", err)
+	}
+	if change.Path != "$.host" || change.Type != "modified" || string(change.Right) != `"db.example.com"` {
+		t.Errorf("unexpected change %+v", change)
+	}
+}
+
+func TestRenderList_NoChanges(t *testing.T) {
+	node := makeMappingNode(makeKeyedNode("a", "1"))
+	if got := RenderList(Compare(node, node)); got != "" {
+		t.Errorf("expected empty list, got %q", got)
+	}
+}
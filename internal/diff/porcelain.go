@@ -0,0 +1,53 @@
+package diff
+
+import "strings"
+
+// PorcelainVersion is the version of the RenderPorcelain format
+const PorcelainVersion = "v1"
+
+// RenderPorcelain renders the changes of a result in a format for scripts
+// that will not change between releases. Version 1 of the grammar is:
+//
+//	line   = status TAB path TAB old TAB new LF
+//	status = "A" (added) / "D" (removed) / "M" (modified) / "T" (type changed)
+//	         / "R" (key order differs, only with Options.DetectReorder)
+//	path   = JSONPath-like path, with "\", TAB, CR and LF escaped as
+//	         "\\", "\t", "\r" and "\n"
+//	old    = compact JSON of the left value, empty for "A"
+//	new    = compact JSON of the right value, empty for "D"
+//
+// For "R", old and new are JSON arrays of the mapping's keys in order.
+//
+// Lines are listed in tree order, one per change (see MarshalJSON for which
+// nodes are listed), with no color, header or summary. Identical inputs
+// produce no output.
+func RenderPorcelain(result *DiffResult) string {
+	var sb strings.Builder
+	for _, change := range NewJSONResult(result).Changes {
+		sb.WriteString(porcelainStatus(change))
+		sb.WriteString("\t")
+		sb.WriteString(listEscaper.Replace(change.Path))
+		sb.WriteString("\t")
+		sb.Write(change.Left)
+		sb.WriteString("\t")
+		sb.Write(change.Right)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// porcelainStatus returns the one-letter status of a change
+func porcelainStatus(change *JSONChange) string {
+	switch {
+	case change.TypeChanged:
+		return "T"
+	case change.Type == DiffAdded.String():
+		return "A"
+	case change.Type == DiffRemoved.String():
+		return "D"
+	case change.Type == DiffReordered.String():
+		return "R"
+	default:
+		return "M"
+	}
+}
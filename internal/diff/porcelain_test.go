@@ -0,0 +1,44 @@
+package diff
+
+import "testing"
+
+func TestRenderPorcelain(t *testing.T) {
+	left := makeMappingNode(
+		makeKeyedNode("host", "localhost"),
+		makeKeyedNode("port", "5432"),
+		makeKeyedNode("motd", "hi"),
+	)
+	right := makeMappingNode(
+		makeKeyedNode("host", "db.example.com"),
+		makeKeyedNode("motd", "a\tb"),
+		makeKeyedNode("ssl", "true"),
+	)
+
+	expected := "M\t$.host\t\"localhost\"\t\"db.example.com\"\n" +
+		"M\t$.motd\t\"hi\"\t\"a\\tb\"\n" +
+		"D\t$.port\t5432\t\n" +
+		"A\t$.ssl\t\ttrue\n"
+	if got := RenderPorcelain(Compare(left, right)); got != expected {
+		t.Errorf("expected:\n%q\ngot:\n%q", expected, got)
+	}
+}
+
+func TestRenderPorcelain_NoChanges(t *testing.T) {
+	node := makeMappingNode(makeKeyedNode("a", "1"))
+	if got := RenderPorcelain(Compare(node, node)); got != "" {
+		t.Errorf("expected no output, got %q", got)
+	}
+}
+
+func TestRenderPorcelain_Reordered(t *testing.T) {
+	left := makeMappingNode(makeKeyedNode("a", "1"), makeKeyedNode("b", "2"))
+	right := makeMappingNode(makeKeyedNode("b", "2"), makeKeyedNode("a", "1"))
+
+	opts := DefaultOptions()
+	opts.DetectReorder = true
+
+	expected := "R\t$\t[\"a\",\"b\"]\t[\"b\",\"a\"]\n"
+	if got := RenderPorcelain(CompareWithOptions(left, right, opts)); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
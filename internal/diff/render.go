@@ -10,41 +10,118 @@ import (
 
 // Style definitions for diff output
 var (
-	addedStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#A6E3A1")) // Green
-	removedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#F38BA8")) // Red
-	modifiedStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#F9E2AF")) // Yellow
-	unchangedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#6C7086")) // Gray
-	keyStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("#89B4FA")) // Blue
+	addedStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#A6E3A1"))            // Green
+	removedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#F38BA8"))            // Red
+	modifiedStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#F9E2AF"))            // Yellow
+	unchangedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#6C7086"))            // Gray
+	keyStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("#89B4FA"))            // Blue
+	typeStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("#FAB387")).Bold(true) // Orange
+	reorderedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#CBA6F7"))            // Purple
 )
 
+// RenderOptions configures CLI diff rendering
+type RenderOptions struct {
+	WordDiff bool       // Show modified scalars as an intra-line diff instead of "old → new"
+	Only     []DiffType // Show only changes of these types, with their parents for context (nil = all)
+
+	NoHeader  bool // Omit the "--- left" and "+++ right" lines
+	NoSummary bool // Omit the summary line after the changes
+}
+
+// shows reports whether a node is rendered: it is a change of a type
+// selected by Only, or has such a change below it. Containers that are
+// modified only through their children are not changes of their own.
+func (o RenderOptions) shows(node *DiffNode) bool {
+	if len(o.Only) == 0 {
+		return hasChanges(node)
+	}
+	if o.selects(node) {
+		return true
+	}
+	for _, child := range node.Children {
+		if o.shows(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// selects reports whether a node is itself a change selected by Only,
+// regardless of the changes below it
+func (o RenderOptions) selects(node *DiffNode) bool {
+	if len(o.Only) == 0 {
+		return node.Type != DiffUnchanged
+	}
+	if node.Type == DiffModified && isContainerNode(node) && !node.Truncated {
+		return false
+	}
+	for _, t := range o.Only {
+		if node.Type == t {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultRenderOptions returns the default rendering options
+func DefaultRenderOptions() RenderOptions {
+	return RenderOptions{}
+}
+
 // Render converts a DiffResult to a colored string for CLI output
 func Render(result *DiffResult) string {
+	return RenderWith(result, DefaultRenderOptions())
+}
+
+// RenderWith converts a DiffResult to a colored string using opts
+func RenderWith(result *DiffResult, opts RenderOptions) string {
 	if result == nil {
 		return ""
 	}
 
 	var buf strings.Builder
 
-	// Render header with file names if present
-	if result.LeftFile != "" || result.RightFile != "" {
-		buf.WriteString(fmt.Sprintf("--- %s\n", result.LeftFile))
-		buf.WriteString(fmt.Sprintf("+++ %s\n", result.RightFile))
-		buf.WriteString("\n")
+	if !opts.NoHeader {
+		renderFileHeader(&buf, result.LeftFile, result.RightFile)
 	}
 
 	// Render the diff tree
 	if result.Root != nil {
-		renderDiffNode(&buf, result.Root, "")
+		renderDiffNode(&buf, result.Root, "", opts)
 	}
 
-	// Append summary at the end
-	if result.Summary.Total > 0 {
-		buf.WriteString("\n")
-		buf.WriteString(RenderSummary(result.Summary))
+	if !opts.NoSummary {
+		renderSummaryFooter(&buf, result.Summary, opts)
+	}
+	return buf.String()
+}
+
+// renderFileHeader writes the "--- left" and "+++ right" lines, if the
+// files are named
+func renderFileHeader(buf *strings.Builder, leftFile, rightFile string) {
+	if leftFile != "" || rightFile != "" {
+		buf.WriteString(fmt.Sprintf("--- %s\n", leftFile))
+		buf.WriteString(fmt.Sprintf("+++ %s\n", rightFile))
 		buf.WriteString("\n")
 	}
+}
 
-	return buf.String()
+// renderSummaryFooter writes the summary line that ends the output when
+// there are changes
+func renderSummaryFooter(buf *strings.Builder, summary DiffSummary, opts RenderOptions) {
+	if summary.Total == 0 {
+		return
+	}
+	buf.WriteString("\n")
+	buf.WriteString(RenderSummary(summary))
+	if len(opts.Only) > 0 {
+		names := make([]string, len(opts.Only))
+		for i, t := range opts.Only {
+			names[i] = t.String()
+		}
+		buf.WriteString(fmt.Sprintf(" (showing only %s)", strings.Join(names, ", ")))
+	}
+	buf.WriteString("\n")
 }
 
 // hasChanges checks if a DiffNode or any of its children have changes
@@ -64,13 +141,13 @@ func hasChanges(node *DiffNode) bool {
 }
 
 // renderDiffNode recursively renders a DiffNode and its children
-func renderDiffNode(buf *strings.Builder, node *DiffNode, indent string) {
+func renderDiffNode(buf *strings.Builder, node *DiffNode, indent string, opts RenderOptions) {
 	if node == nil {
 		return
 	}
 
-	// Skip unchanged nodes that have no changed children
-	if node.Type == DiffUnchanged && !hasChanges(node) {
+	// Skip nodes without (selected) changes in or below them
+	if !opts.shows(node) {
 		return
 	}
 
@@ -82,17 +159,21 @@ func renderDiffNode(buf *strings.Builder, node *DiffNode, indent string) {
 
 	// Skip rendering the root document node itself, just render children
 	if isDocumentNode(node) {
-		for _, child := range node.Children {
-			renderDiffNode(buf, child, indent)
+		for _, child := range wholeChildren(node) {
+			renderDiffNode(buf, child, indent, opts)
 		}
 		return
 	}
 
 	// Skip rendering if key is empty (root-level container without key)
 	if key == "" && isContainerNode(node) {
-		// Just render children without a header line
-		for _, child := range node.Children {
-			renderDiffNode(buf, child, indent)
+		// Just render children without a header line, unless the root
+		// mapping itself was reordered
+		if node.Type == DiffReordered {
+			renderContainerLine(buf, node, indent)
+		}
+		for _, child := range wholeChildren(node) {
+			renderDiffNode(buf, child, indent, opts)
 		}
 		return
 	}
@@ -102,19 +183,27 @@ func renderDiffNode(buf *strings.Builder, node *DiffNode, indent string) {
 		// Modified scalar: show "oldValue → newValue"
 		oldValue := getScalarValue(node.Left)
 		newValue := getScalarValue(node.Right)
-		line := fmt.Sprintf("%s%s%s: %s → %s", prefix, indent, keyStyle.Render(key), oldValue, newValue)
-		buf.WriteString(style.Render(line))
+		if opts.WordDiff {
+			line := fmt.Sprintf("%s%s%s: ", prefix, indent, keyStyle.Render(key))
+			buf.WriteString(style.Render(line))
+			buf.WriteString(renderWordDiff(oldValue, newValue, style))
+		} else {
+			line := fmt.Sprintf("%s%s%s: %s → %s", prefix, indent, keyStyle.Render(key), oldValue, newValue)
+			buf.WriteString(style.Render(line))
+		}
+		if node.TypeChanged {
+			buf.WriteString(" ")
+			buf.WriteString(typeStyle.Render(typeChangeLabel(node)))
+		}
 		buf.WriteString("\n")
 	} else if isContainerNode(node) {
 		// Container node (mapping or sequence)
-		line := fmt.Sprintf("%s%s%s:", prefix, indent, keyStyle.Render(key))
-		buf.WriteString(style.Render(line))
-		buf.WriteString("\n")
+		renderContainerLine(buf, node, indent)
 
 		// Render children with increased indent
 		childIndent := indent + "  "
 		for _, child := range node.Children {
-			renderDiffNode(buf, child, childIndent)
+			renderDiffNode(buf, child, childIndent, opts)
 		}
 	} else {
 		// Scalar node
@@ -125,6 +214,78 @@ func renderDiffNode(buf *strings.Builder, node *DiffNode, indent string) {
 	}
 }
 
+// renderContainerLine writes the line of a container above its children.
+// A root container has no key and only gets a line when its keys were
+// reordered.
+func renderContainerLine(buf *strings.Builder, node *DiffNode, indent string) {
+	prefix, style := getDiffPrefixAndStyle(node.Type)
+	key := getNodeKey(node)
+	if key == "" {
+		buf.WriteString(style.Render(fmt.Sprintf("%s%s%s: (key order differs)", prefix, indent, keyStyle.Render(node.Path))))
+		buf.WriteString("\n")
+		return
+	}
+
+	line := fmt.Sprintf("%s%s%s:", prefix, indent, keyStyle.Render(key))
+	if node.Truncated {
+		line += " " + formatNodeValue(node.Right) + " (contents differ)"
+	}
+	if node.Type == DiffReordered {
+		line += " (key order differs)"
+	}
+	buf.WriteString(style.Render(line))
+	buf.WriteString("\n")
+}
+
+// wholeChildren returns the children to render below a root without a key.
+// A root added or removed as a whole (a document that exists in only one
+// file) has no child diff nodes, so its entries are listed instead.
+func wholeChildren(node *DiffNode) []*DiffNode {
+	if len(node.Children) > 0 || (node.Type != DiffAdded && node.Type != DiffRemoved) {
+		return node.Children
+	}
+	side := node.Right
+	if node.Type == DiffRemoved {
+		side = node.Left
+	}
+	if side.Kind() == parser.KindDocument && len(side.Children) > 0 {
+		side = side.Children[0]
+	}
+
+	children := make([]*DiffNode, 0, len(side.Children))
+	for i, child := range side.Children {
+		path := node.Path + "." + child.Key
+		if side.Kind() == parser.KindSequence {
+			path = fmt.Sprintf("%s[%d]", node.Path, i)
+		}
+		entry := &DiffNode{Type: node.Type, Path: path}
+		if node.Type == DiffAdded {
+			entry.Right = child
+		} else {
+			entry.Left = child
+		}
+		children = append(children, entry)
+	}
+	return children
+}
+
+// renderWordDiff renders the changed runs between two values using git's
+// word-diff markers: [-deleted-] in red and {+inserted+} in green
+func renderWordDiff(oldValue, newValue string, style lipgloss.Style) string {
+	var buf strings.Builder
+	for _, seg := range WordDiff(oldValue, newValue) {
+		switch seg.Op {
+		case SegmentDelete:
+			buf.WriteString(removedStyle.Render("[-" + seg.Text + "-]"))
+		case SegmentInsert:
+			buf.WriteString(addedStyle.Render("{+" + seg.Text + "+}"))
+		default:
+			buf.WriteString(style.Render(seg.Text))
+		}
+	}
+	return buf.String()
+}
+
 // RenderSummary returns a summary string like "Summary: 3 added, 0 removed, 2 modified"
 func RenderSummary(summary DiffSummary) string {
 	if summary.Total == 0 {
@@ -138,9 +299,18 @@ func RenderSummary(summary DiffSummary) string {
 		modifiedStyle.Render(fmt.Sprintf("%d modified", summary.Modified)),
 	}
 
+	if summary.Reordered > 0 {
+		parts = append(parts, reorderedStyle.Render(fmt.Sprintf("%d reordered", summary.Reordered)))
+	}
+
 	return "Summary: " + strings.Join(parts, ", ")
 }
 
+// typeChangeLabel returns an annotation like "<int>→<str>" for a type change
+func typeChangeLabel(node *DiffNode) string {
+	return fmt.Sprintf("<%s>→<%s>", node.Left.InferType(), node.Right.InferType())
+}
+
 // getDiffPrefixAndStyle returns the prefix string and lipgloss style for a diff type
 func getDiffPrefixAndStyle(diffType DiffType) (string, lipgloss.Style) {
 	switch diffType {
@@ -150,6 +320,8 @@ func getDiffPrefixAndStyle(diffType DiffType) (string, lipgloss.Style) {
 		return "- ", removedStyle
 	case DiffModified:
 		return "~ ", modifiedStyle
+	case DiffReordered:
+		return "↕ ", reorderedStyle
 	default:
 		return "  ", unchangedStyle
 	}
@@ -2,10 +2,46 @@ package diff
 
 // DiffSummary holds statistics about the differences between two YAML files
 type DiffSummary struct {
-	Added    int // Count of added nodes
-	Removed  int // Count of removed nodes
-	Modified int // Count of modified nodes
-	Total    int // Total count of changes
+	Added     int // Count of added nodes
+	Removed   int // Count of removed nodes
+	Modified  int // Count of modified nodes
+	Reordered int // Count of mappings whose key order differs (Options.DetectReorder)
+	Total     int // Total count of changes
+
+	// ByKey counts the changes below each top-level key (or "[i]" item) of
+	// the compared nodes that has any; the root's own change is not
+	// included. Nil when the roots were not compared key by key.
+	ByKey map[string]DiffSummary
+}
+
+// Add adds the counts of other to s, key by key for ByKey
+func (s *DiffSummary) Add(other DiffSummary) {
+	s.Added += other.Added
+	s.Removed += other.Removed
+	s.Modified += other.Modified
+	s.Reordered += other.Reordered
+	s.Total += other.Total
+	for key, counts := range other.ByKey {
+		if s.ByKey == nil {
+			s.ByKey = make(map[string]DiffSummary)
+		}
+		sum := s.ByKey[key]
+		sum.Add(counts)
+		s.ByKey[key] = sum
+	}
+}
+
+// addByKey totals counts and records them under key in ByKey, unless
+// there are none
+func (s *DiffSummary) addByKey(key string, counts DiffSummary) {
+	counts.Total = counts.Added + counts.Removed + counts.Modified + counts.Reordered
+	if counts.Total == 0 {
+		return
+	}
+	if s.ByKey == nil {
+		s.ByKey = make(map[string]DiffSummary)
+	}
+	s.ByKey[key] = counts
 }
 
 // DiffResult represents the complete result of comparing two YAML files
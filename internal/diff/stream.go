@@ -0,0 +1,247 @@
+package diff
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/simota/yam/internal/parser"
+)
+
+// Stream compares left and right like CompareWithOptions and writes what
+// RenderWith would print for the result, without building the diff tree
+// first: each change is written to w as soon as it is found, preceded by
+// the lines of the containers above it. Mappings and sequences are walked
+// directly; subtrees the tree-based comparison handles as a whole (added,
+// removed, set fields, containers at MaxDepth) are compared with it and
+// written at once.
+//
+// Nothing is written when there are no changes. The returned summary
+// counts the changes like DiffResult.Summary.
+func Stream(w io.Writer, left, right *parser.YamNode, leftFile, rightFile string, opts Options, ropts RenderOptions) (DiffSummary, error) {
+	if opts.RootPath == "" {
+		opts.RootPath = "$"
+	}
+
+	s := &streamer{w: w, opts: opts, ropts: ropts}
+	if (leftFile != "" || rightFile != "") && !ropts.NoHeader {
+		s.fileHeader = &[2]string{leftFile, rightFile}
+	}
+	if left != nil || right != nil {
+		s.compare(left, right, opts.RootPath, 0, "")
+	}
+	s.summary.Total = s.summary.Added + s.summary.Removed + s.summary.Modified + s.summary.Reordered
+
+	if s.summary.Total > 0 {
+		s.flush()
+		if !ropts.NoSummary {
+			var buf strings.Builder
+			renderSummaryFooter(&buf, s.summary, ropts)
+			s.write(buf.String())
+		}
+	}
+	return s.summary, s.err
+}
+
+// streamer holds the state of a Stream call
+type streamer struct {
+	w       io.Writer
+	opts    Options
+	ropts   RenderOptions
+	summary DiffSummary
+	err     error // First write error; later writes are skipped
+
+	// fileHeader holds the file names until the first change is written
+	fileHeader *[2]string
+
+	// pending holds the containers entered but not written yet, outermost
+	// first; they are written when a change below them is
+	pending []pendingLine
+}
+
+// pendingLine is a container line waiting for a change to be written
+type pendingLine struct {
+	node   *DiffNode
+	indent string
+}
+
+// compare compares two nodes, writes their changes and returns the diff
+// type of the pair
+func (s *streamer) compare(left, right *parser.YamNode, path string, depth int, indent string) DiffType {
+	if left != nil && right != nil {
+		if left.Kind() == parser.KindDocument && right.Kind() == parser.KindDocument {
+			return s.compare(firstChild(left), firstChild(right), path, depth, indent)
+		}
+		truncated := s.opts.MaxDepth > 0 && depth >= s.opts.MaxDepth && left.IsContainer() && right.IsContainer()
+		switch {
+		case truncated:
+		case left.Kind() == parser.KindMapping && right.Kind() == parser.KindMapping:
+			return s.compareMappings(left, right, path, depth, indent)
+		case left.Kind() == parser.KindSequence && right.Kind() == parser.KindSequence && !s.opts.isSetField(path):
+			return s.compareSequences(left, right, path, depth, indent)
+		}
+	}
+
+	node := compareNodes(left, right, path, depth, s.opts)
+	if node == nil {
+		return DiffUnchanged
+	}
+	walkDiffTree(node, &s.summary)
+	if depth == 0 {
+		for _, child := range node.Children {
+			var counts DiffSummary
+			walkDiffTree(child, &counts)
+			s.summary.addByKey(getNodeKey(child), counts)
+		}
+	}
+	if s.ropts.shows(node) {
+		s.flush()
+		var buf strings.Builder
+		renderDiffNode(&buf, node, indent, s.ropts)
+		s.write(buf.String())
+	}
+	return node.Type
+}
+
+// compareMappings compares two mappings entry by entry in the order of
+// Options.Order
+func (s *streamer) compareMappings(left, right *parser.YamNode, path string, depth int, indent string) DiffType {
+	leftByKey := make(map[string]*parser.YamNode)
+	for _, child := range left.Children {
+		leftByKey[child.Key] = child
+	}
+	rightByKey := make(map[string]*parser.YamNode)
+	for _, child := range right.Children {
+		rightByKey[child.Key] = child
+	}
+
+	reordered := s.opts.DetectReorder && keyOrderDiffers(left, right)
+	return s.compareContainer(left, right, path, indent, reordered, func(childIndent string) bool {
+		changed := false
+		for _, key := range mappingKeys(left, right, s.opts.Order) {
+			before := s.summary
+			if s.compare(leftByKey[key], rightByKey[key], path+"."+key, depth+1, childIndent) != DiffUnchanged {
+				changed = true
+			}
+			if depth == 0 {
+				s.addTopLevel(key, before)
+			}
+		}
+		return changed
+	})
+}
+
+// compareSequences compares two sequences item by item
+func (s *streamer) compareSequences(left, right *parser.YamNode, path string, depth int, indent string) DiffType {
+	return s.compareContainer(left, right, path, indent, false, func(childIndent string) bool {
+		changed := false
+		for i := 0; i < len(left.Children) || i < len(right.Children); i++ {
+			var leftChild, rightChild *parser.YamNode
+			if i < len(left.Children) {
+				leftChild = left.Children[i]
+			}
+			if i < len(right.Children) {
+				rightChild = right.Children[i]
+			}
+			before := s.summary
+			if s.compare(leftChild, rightChild, fmt.Sprintf("%s[%d]", path, i), depth+1, childIndent) != DiffUnchanged {
+				changed = true
+			}
+			if depth == 0 {
+				s.addTopLevel(fmt.Sprintf("[%d]", i), before)
+			}
+		}
+		return changed
+	})
+}
+
+// compareContainer writes the line of a container before the first change
+// below it, or once its children are compared when only its own reordering
+// is shown, and compares its children with compareChildren, which reports
+// whether any changed. A reordered container with changes below it is
+// modified.
+func (s *streamer) compareContainer(left, right *parser.YamNode, path, indent string, reordered bool, compareChildren func(childIndent string) bool) DiffType {
+	node := &DiffNode{Left: left, Right: right, Type: DiffModified, Path: path}
+
+	// A root container has no line of its own unless only its key order
+	// changed, and its children are not indented
+	keyed := getNodeKey(node) != ""
+	childIndent := indent
+	if keyed {
+		childIndent = indent + "  "
+	}
+	mark := -1
+	if keyed {
+		s.pending = append(s.pending, pendingLine{node, indent})
+		mark = len(s.pending) - 1
+	}
+
+	changed := compareChildren(childIndent)
+
+	// Nothing below an unchanged container was written, so its line is
+	// still pending if it has one
+	if reordered && !changed {
+		node.Type = DiffReordered
+		if s.ropts.selects(node) {
+			if mark < 0 {
+				s.pending = append(s.pending, pendingLine{node, indent})
+			}
+			s.flush()
+		}
+	}
+
+	// Drop the line if nothing below it was written
+	if mark >= 0 && len(s.pending) > mark {
+		s.pending = s.pending[:mark]
+	}
+
+	switch {
+	case changed:
+		s.summary.Modified++
+		return DiffModified
+	case reordered:
+		s.summary.Reordered++
+		return DiffReordered
+	}
+	return DiffUnchanged
+}
+
+// addTopLevel records the changes counted since before under key in the
+// summary's ByKey, for a child of the root
+func (s *streamer) addTopLevel(key string, before DiffSummary) {
+	s.summary.addByKey(key, DiffSummary{
+		Added:     s.summary.Added - before.Added,
+		Removed:   s.summary.Removed - before.Removed,
+		Modified:  s.summary.Modified - before.Modified,
+		Reordered: s.summary.Reordered - before.Reordered,
+	})
+}
+
+// flush writes the file header and the pending container lines
+func (s *streamer) flush() {
+	var buf strings.Builder
+	if s.fileHeader != nil {
+		renderFileHeader(&buf, s.fileHeader[0], s.fileHeader[1])
+		s.fileHeader = nil
+	}
+	for _, p := range s.pending {
+		renderContainerLine(&buf, p.node, p.indent)
+	}
+	s.pending = s.pending[:0]
+	s.write(buf.String())
+}
+
+func (s *streamer) write(text string) {
+	if s.err != nil || text == "" {
+		return
+	}
+	_, s.err = io.WriteString(s.w, text)
+}
+
+// firstChild returns the content of a document node, or nil when empty
+func firstChild(node *parser.YamNode) *parser.YamNode {
+	if len(node.Children) > 0 {
+		return node.Children[0]
+	}
+	return nil
+}
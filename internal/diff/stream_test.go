@@ -0,0 +1,163 @@
+package diff
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/simota/yam/internal/parser"
+)
+
+func TestStream_MatchesRenderWith(t *testing.T) {
+	pairs := []struct {
+		name, left, right string
+	}{
+		{"identical", "a: 1\nb: [x, y]\n", "a: 1\nb: [x, y]\n"},
+		{"scalars", "a: 1\nb: two\nc: 3\n", "a: 1\nb: 2\nc: 4\n"},
+		{"nested", "a:\n  x: 1\n  y: {p: 1, q: 2}\nb: 3\n", "a:\n  x: 5\n  y: {p: 1, q: 3}\nb: 3\nd: new\n"},
+		{"sequences", "items:\n  - {name: a, v: 1}\n  - {name: b, v: 2}\n  - c\n", "items:\n  - {name: a, v: 1}\n  - {name: b, v: 3}\n"},
+		{"kind change", "a: {x: 1}\nb: [1]\n", "a: [1]\nb: {x: 1}\n"},
+		{"reordered", "a: 1\nb: 2\nc: {x: 1, y: 2}\n", "b: 2\na: 1\nc: {y: 2, x: 1}\ne: 5\n"},
+		{"reordered and changed", "a: 1\nb: {x: 1, y: 2}\n", "b: {y: 3, x: 1}\na: 1\n"},
+		{"tags", "tags: [a, b, c]\nn: 1\n", "tags: [c, a, d]\nn: 1\n"},
+		{"deep", "a:\n  b:\n    c:\n      d: 1\n", "a:\n  b:\n    c:\n      d: 2\n"},
+		{"root sequence", "- 1\n- {a: 1}\n", "- 2\n- {a: 1}\n- 3\n"},
+		{"added document", "", "a: 1\nb: [1, 2]\n"},
+		{"nulls", "a: null\nb: 1\nc: [1, ~]\n", "b: ~\nc: [1]\nd:\n"},
+	}
+
+	optionSets := []struct {
+		name  string
+		opts  func() Options
+		ropts RenderOptions
+	}{
+		{"default", DefaultOptions, RenderOptions{}},
+		{"word diff", DefaultOptions, RenderOptions{WordDiff: true}},
+		{"only removed", DefaultOptions, RenderOptions{Only: []DiffType{DiffRemoved}}},
+		{"only reordered", func() Options { o := DefaultOptions(); o.DetectReorder = true; return o }, RenderOptions{Only: []DiffType{DiffReordered}}},
+		{"detect reorder", func() Options { o := DefaultOptions(); o.DetectReorder = true; return o }, RenderOptions{}},
+		{"source order", func() Options { o := DefaultOptions(); o.Order = OrderSource; return o }, RenderOptions{}},
+		{"no header or summary", DefaultOptions, RenderOptions{NoHeader: true, NoSummary: true}},
+		{"max depth", func() Options { o := DefaultOptions(); o.MaxDepth = 2; return o }, RenderOptions{}},
+		{"set fields", func() Options { o := DefaultOptions(); o.SetFields = []string{"$.tags"}; return o }, RenderOptions{}},
+		{"null is absent", func() Options { o := DefaultOptions(); o.NullIsAbsent = true; return o }, RenderOptions{}},
+	}
+
+	p := parser.New()
+	for _, pair := range pairs {
+		left, right := parseSide(t, p, pair.left), parseSide(t, p, pair.right)
+		for _, set := range optionSets {
+			t.Run(pair.name+"/"+set.name, func(t *testing.T) {
+				result := CompareWithOptions(left, right, set.opts())
+				result.LeftFile, result.RightFile = "old.yaml", "new.yaml"
+
+				var buf strings.Builder
+				summary, err := Stream(&buf, left, right, "old.yaml", "new.yaml", set.opts(), set.ropts)
+				if err != nil {
+					t.Fatalf("Stream failed: %v", err)
+				}
+				if !reflect.DeepEqual(summary, result.Summary) {
+					t.Errorf("expected summary %+v, got %+v", result.Summary, summary)
+				}
+
+				want := ""
+				if result.Summary.Total > 0 {
+					want = RenderWith(result, set.ropts)
+				}
+				if buf.String() != want {
+					t.Errorf("output differs from RenderWith\nwant:\n%s\ngot:\n%s", want, buf.String())
+				}
+			})
+		}
+	}
+}
+
+// parseSide parses one side of a diff; empty input is a missing file
+func parseSide(t *testing.T, p *parser.Parser, input string) *parser.YamNode {
+	t.Helper()
+	if input == "" {
+		return nil
+	}
+	node, err := p.ParseString(input)
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	return node
+}
+
+// largeFixture returns two versions of a document with n services of a
+// dozen settings each, differing in every tenth service
+func largeFixture(b *testing.B, n int) (*parser.YamNode, *parser.YamNode) {
+	b.Helper()
+	var left, right strings.Builder
+	for _, buf := range []*strings.Builder{&left, &right} {
+		buf.WriteString("services:\n")
+	}
+	for i := 0; i < n; i++ {
+		for side, buf := range []*strings.Builder{&left, &right} {
+			replicas := 2
+			if side == 1 && i%10 == 0 {
+				replicas = 3
+			}
+			fmt.Fprintf(buf, "  svc%05d:\n    image: registry.example.com/svc%05d:1.0\n    replicas: %d\n", i, i, replicas)
+			fmt.Fprintf(buf, "    ports: [8080, 8443]\n    env:\n      LOG_LEVEL: info\n      REGION: eu-west-1\n")
+			fmt.Fprintf(buf, "    resources: {cpu: 500m, memory: 256Mi}\n    labels: {team: core, tier: backend}\n")
+		}
+	}
+
+	p := parser.New()
+	l, err := p.ParseString(left.String())
+	if err != nil {
+		b.Fatalf("failed to parse: %v", err)
+	}
+	r, err := p.ParseString(right.String())
+	if err != nil {
+		b.Fatalf("failed to parse: %v", err)
+	}
+	return l, r
+}
+
+// firstWrite records how long after start the first byte was written
+type firstWrite struct {
+	start time.Time
+	first time.Duration
+}
+
+func (f *firstWrite) Write(p []byte) (int, error) {
+	if f.first == 0 && len(p) > 0 {
+		f.first = time.Since(f.start)
+	}
+	return len(p), nil
+}
+
+// The benchmarks report ns/first-byte, the time until output starts, next
+// to the total time
+func BenchmarkCompareAndRender(b *testing.B) {
+	left, right := largeFixture(b, 20000)
+	var first time.Duration
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w := &firstWrite{start: time.Now()}
+		result := CompareWithOptions(left, right, DefaultOptions())
+		io.WriteString(w, RenderWith(result, DefaultRenderOptions()))
+		first += w.first
+	}
+	b.ReportMetric(float64(first.Nanoseconds())/float64(b.N), "ns/first-byte")
+}
+
+func BenchmarkStream(b *testing.B) {
+	left, right := largeFixture(b, 20000)
+	var first time.Duration
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w := &firstWrite{start: time.Now()}
+		if _, err := Stream(w, left, right, "", "", DefaultOptions(), DefaultRenderOptions()); err != nil {
+			b.Fatal(err)
+		}
+		first += w.first
+	}
+	b.ReportMetric(float64(first.Nanoseconds())/float64(b.N), "ns/first-byte")
+}
@@ -10,6 +10,7 @@ const (
 	DiffAdded
 	DiffRemoved
 	DiffModified
+	DiffReordered // Mapping whose keys changed order and nothing else (Options.DetectReorder)
 )
 
 // DiffNode represents a node in the diff tree structure
@@ -19,4 +20,12 @@ type DiffNode struct {
 	Type     DiffType
 	Children []*DiffNode
 	Path     string // JSONPath-like path
+
+	// TypeChanged is set on modified scalars whose inferred type differs
+	// between the two sides (e.g. 3 → "3")
+	TypeChanged bool
+
+	// Truncated is set on containers that were compared as a whole because
+	// they reached Options.MaxDepth; Children is empty
+	Truncated bool
 }
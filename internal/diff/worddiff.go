@@ -0,0 +1,103 @@
+package diff
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SegmentOp identifies the kind of a word diff segment
+type SegmentOp int
+
+const (
+	SegmentEqual SegmentOp = iota
+	SegmentDelete
+	SegmentInsert
+)
+
+// Segment is a run of text that is shared, deleted, or inserted
+type Segment struct {
+	Op   SegmentOp
+	Text string
+}
+
+// WordDiff computes an intra-line diff between two strings. The strings are
+// split into words (runs of letters and digits) and single separator
+// characters, aligned with a longest common subsequence, and adjacent tokens
+// of the same kind are merged into segments.
+func WordDiff(oldValue, newValue string) []Segment {
+	a := tokenizeWords(oldValue)
+	b := tokenizeWords(newValue)
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var segments []Segment
+	add := func(op SegmentOp, text string) {
+		if n := len(segments); n > 0 && segments[n-1].Op == op {
+			segments[n-1].Text += text
+			return
+		}
+		segments = append(segments, Segment{Op: op, Text: text})
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			add(SegmentEqual, a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			add(SegmentDelete, a[i])
+			i++
+		default:
+			add(SegmentInsert, b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		add(SegmentDelete, a[i])
+	}
+	for ; j < len(b); j++ {
+		add(SegmentInsert, b[j])
+	}
+
+	return segments
+}
+
+// tokenizeWords splits s into runs of letters/digits and single other runes
+func tokenizeWords(s string) []string {
+	var tokens []string
+	var word strings.Builder
+
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			word.WriteRune(r)
+			continue
+		}
+		flush()
+		tokens = append(tokens, string(r))
+	}
+	flush()
+
+	return tokens
+}
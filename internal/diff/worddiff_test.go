@@ -0,0 +1,74 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWordDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		expected []Segment
+	}{
+		{
+			name: "single word changed",
+			old:  "postgres://db.internal:5432/app",
+			new:  "postgres://db.internal:6432/app",
+			expected: []Segment{
+				{SegmentEqual, "postgres://db.internal:"},
+				{SegmentDelete, "5432"},
+				{SegmentInsert, "6432"},
+				{SegmentEqual, "/app"},
+			},
+		},
+		{
+			name: "word inserted",
+			old:  "run --fast",
+			new:  "run --fast --verbose",
+			expected: []Segment{
+				{SegmentEqual, "run --fast"},
+				{SegmentInsert, " --verbose"},
+			},
+		},
+		{
+			name:     "identical",
+			old:      "same",
+			new:      "same",
+			expected: []Segment{{SegmentEqual, "same"}},
+		},
+		{
+			name:     "empty old",
+			old:      "",
+			new:      "new",
+			expected: []Segment{{SegmentInsert, "new"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WordDiff(tt.old, tt.new)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("WordDiff(%q, %q) = %+v, expected %+v", tt.old, tt.new, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderWith_WordDiff(t *testing.T) {
+	left := makeMappingNode(makeKeyedNode("cmd", "run --fast"))
+	right := makeMappingNode(makeKeyedNode("cmd", "run --slow"))
+	result := Compare(left, right)
+
+	opts := DefaultRenderOptions()
+	opts.WordDiff = true
+	out := RenderWith(result, opts)
+
+	if !strings.Contains(out, "run --[-fast-]{+slow+}") {
+		t.Errorf("expected word diff markers, got:\n%s", out)
+	}
+	if strings.Contains(out, "→") {
+		t.Errorf("expected no arrow in word diff mode, got:\n%s", out)
+	}
+}
@@ -0,0 +1,244 @@
+// Package lint checks YAML documents for problems that parse successfully
+// (or fail to parse) without pointing at their cause, such as broken
+// anchor/alias references.
+package lint
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/simota/yam/internal/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// Severity classifies an issue
+type Severity int
+
+const (
+	SeverityWarning Severity = iota
+	SeverityError
+)
+
+// String returns "warning" or "error"
+func (s Severity) String() string {
+	if s == SeverityError {
+		return "error"
+	}
+	return "warning"
+}
+
+// MarshalText encodes the severity by name, e.g. in JSON
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Rule names
+const (
+	RuleDanglingAlias   = "dangling-alias"
+	RuleUnusedAnchor    = "unused-anchor"
+	RuleDuplicateAnchor = "duplicate-anchor"
+)
+
+// Issue is a single lint finding
+type Issue struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Path     string   `json:"path"` // JSONPath of the node, e.g. $.list[0]
+	Line     int      `json:"line"`
+	Column   int      `json:"column"`
+	Message  string   `json:"message"`
+}
+
+// unknownAnchor matches yaml.v3's error for an alias without an anchor
+var unknownAnchor = regexp.MustCompile(`unknown anchor '([^']*)' referenced`)
+
+// Lint parses YAML source and returns its issues ordered by position.
+// Every document of a multi-document stream is checked; anchors are
+// scoped to their document. An error is returned only when the source
+// cannot be parsed for a reason other than the issues reported.
+func Lint(src []byte) ([]Issue, error) {
+	// yaml.v3 rejects aliases without a preceding anchor and does not say
+	// where they are. Each such alias is blanked out (keeping columns
+	// intact) and the source parsed again, so all of them can be reported.
+	// Only the document that failed is blanked, since another document may
+	// define an anchor of the same name.
+	var dangling []danglingAlias
+	for {
+		doc, err := decodeAll(src)
+		if err == nil {
+			break
+		}
+		m := unknownAnchor.FindStringSubmatch(err.Error())
+		if m == nil {
+			return nil, fmt.Errorf("failed to parse YAML: %w", err)
+		}
+		var found []danglingAlias
+		first, last := documentLines(src, doc)
+		src, found = blankAliases(src, m[1], first, last)
+		if len(found) == 0 {
+			return nil, fmt.Errorf("failed to parse YAML: %w", err)
+		}
+		dangling = append(dangling, found...)
+	}
+
+	docs, err := parser.New().ParseAll(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	for _, d := range dangling {
+		path, ok := pathAt(docs, d.line, d.column)
+		if !ok {
+			continue // the text matched inside a quoted scalar or comment
+		}
+		issues = append(issues, Issue{
+			Rule:     RuleDanglingAlias,
+			Severity: SeverityError,
+			Path:     path,
+			Line:     d.line,
+			Column:   d.column,
+			Message:  fmt.Sprintf("alias *%s has no preceding anchor &%s", d.name, d.name),
+		})
+	}
+
+	for _, doc := range docs {
+		for _, n := range parser.UnusedAnchors(doc.Raw) {
+			path, _ := pathAt(docs, n.Line, n.Column)
+			issues = append(issues, Issue{
+				Rule:     RuleUnusedAnchor,
+				Severity: SeverityWarning,
+				Path:     path,
+				Line:     n.Line,
+				Column:   n.Column,
+				Message:  fmt.Sprintf("anchor &%s is never referenced", n.Anchor),
+			})
+		}
+		for _, r := range parser.RedefinedAnchors(doc.Raw) {
+			path, _ := pathAt(docs, r.Node.Line, r.Node.Column)
+			issues = append(issues, Issue{
+				Rule:     RuleDuplicateAnchor,
+				Severity: SeverityWarning,
+				Path:     path,
+				Line:     r.Node.Line,
+				Column:   r.Node.Column,
+				Message: fmt.Sprintf("anchor &%s is already defined on line %d; later aliases refer to this one",
+					r.Node.Anchor, r.Previous.Line),
+			})
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Line != issues[j].Line {
+			return issues[i].Line < issues[j].Line
+		}
+		return issues[i].Column < issues[j].Column
+	})
+	return issues, nil
+}
+
+// decodeAll decodes every document of src, returning the first error and
+// the index of the document that caused it
+func decodeAll(src []byte) (int, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(src))
+	for i := 0; ; i++ {
+		var doc yaml.Node
+		err := decoder.Decode(&doc)
+		if err == io.EOF {
+			return 0, nil
+		}
+		if err != nil {
+			return i, err
+		}
+	}
+}
+
+// documentLines returns the first and last line of document doc in src,
+// found by its "---" start marker; the first document may have none
+func documentLines(src []byte, doc int) (first, last int) {
+	lines := strings.Split(string(src), "\n")
+	starts := []int{1}
+	marker, content := false, false
+	for i, line := range lines {
+		if line == "---" || strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "---\t") {
+			// The first marker only starts a new document after content
+			if marker || content {
+				starts = append(starts, i+1)
+			}
+			marker = true
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") && !strings.HasPrefix(trimmed, "%") {
+			content = true
+		}
+	}
+	if doc >= len(starts) {
+		return 1, len(lines)
+	}
+	last = len(lines)
+	if doc+1 < len(starts) {
+		last = starts[doc+1] - 1
+	}
+	return starts[doc], last
+}
+
+// danglingAlias is the source position of an alias without an anchor
+type danglingAlias struct {
+	name         string
+	line, column int
+}
+
+// blankAliases replaces every "*name" token on lines first to last of src
+// with "~" padded to the same width and returns the positions replaced
+func blankAliases(src []byte, name string, first, last int) ([]byte, []danglingAlias) {
+	token := regexp.MustCompile(`(^|[\s\[\]{},:-])\*` + regexp.QuoteMeta(name) + `($|[\s\[\]{},])`)
+	lines := strings.Split(string(src), "\n")
+
+	var found []danglingAlias
+	for i, line := range lines {
+		if i+1 < first || i+1 > last {
+			continue
+		}
+		for {
+			loc := token.FindStringSubmatchIndex(line)
+			if loc == nil {
+				break
+			}
+			start := loc[3] // end of the leading delimiter = position of '*'
+			found = append(found, danglingAlias{name: name, line: i + 1, column: start + 1})
+			line = line[:start] + "~" + strings.Repeat(" ", len(name)) + line[start+1+len(name):]
+		}
+		lines[i] = line
+	}
+	return []byte(strings.Join(lines, "\n")), found
+}
+
+// pathAt returns the path of the node (or mapping key) at line and column
+// in any of the documents
+func pathAt(docs []*parser.YamNode, line, column int) (string, bool) {
+	for _, root := range docs {
+		var path string
+		found := false
+		parser.Walk(root, func(n *parser.YamNode) bool {
+			if found {
+				return false
+			}
+			if (n.Raw != nil && n.Raw.Line == line && n.Raw.Column == column) ||
+				(n.KeyRaw != nil && n.KeyRaw.Line == line && n.KeyRaw.Column == column) {
+				path = n.JSONPath()
+				found = true
+				return false
+			}
+			return true
+		})
+		if found {
+			return path, true
+		}
+	}
+	return "", false
+}
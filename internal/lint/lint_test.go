@@ -0,0 +1,133 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLint_Clean(t *testing.T) {
+	issues, err := Lint([]byte("base: &base\n  a: 1\nx:\n  <<: *base\n"))
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("expected no issues, got %+v", issues)
+	}
+}
+
+func TestLint_UnusedAnchor(t *testing.T) {
+	issues, err := Lint([]byte("a: 1\nb: &unused 2\n"))
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	if len(issues) != 1 {
+		t.Fatalf("expected 1 issue, got %+v", issues)
+	}
+	got := issues[0]
+	if got.Rule != RuleUnusedAnchor || got.Severity != SeverityWarning {
+		t.Errorf("unexpected issue %+v", got)
+	}
+	if got.Path != "$.b" || got.Line != 2 || got.Column != 4 {
+		t.Errorf("unexpected position %s %d:%d", got.Path, got.Line, got.Column)
+	}
+}
+
+func TestLint_DanglingAliases(t *testing.T) {
+	input := `a:
+  b: *missing
+list: [*missing, *other]
+note: "not *missing an alias"
+`
+	issues, err := Lint([]byte(input))
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+
+	want := []struct {
+		path         string
+		line, column int
+		name         string
+	}{
+		{"$.a.b", 2, 6, "missing"},
+		{"$.list[0]", 3, 8, "missing"},
+		{"$.list[1]", 3, 18, "other"},
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("expected %d issues, got %+v", len(want), issues)
+	}
+	for i, w := range want {
+		got := issues[i]
+		if got.Rule != RuleDanglingAlias || got.Severity != SeverityError {
+			t.Errorf("issue %d: unexpected rule %+v", i, got)
+		}
+		if got.Path != w.path || got.Line != w.line || got.Column != w.column {
+			t.Errorf("issue %d: expected %s %d:%d, got %s %d:%d",
+				i, w.path, w.line, w.column, got.Path, got.Line, got.Column)
+		}
+		if !strings.Contains(got.Message, "*"+w.name) {
+			t.Errorf("issue %d: message %q does not name *%s", i, got.Message, w.name)
+		}
+	}
+}
+
+func TestLint_ForwardReference(t *testing.T) {
+	issues, err := Lint([]byte("a: *later\nb: &later 1\n"))
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("expected 2 issues, got %+v", issues)
+	}
+	if issues[0].Rule != RuleDanglingAlias || issues[1].Rule != RuleUnusedAnchor {
+		t.Errorf("unexpected issues %+v", issues)
+	}
+}
+
+func TestLint_DuplicateAnchor(t *testing.T) {
+	issues, err := Lint([]byte("a: &x 1\nb: *x\nc: &x 2\nd: *x\n"))
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	if len(issues) != 1 {
+		t.Fatalf("expected 1 issue, got %+v", issues)
+	}
+	got := issues[0]
+	if got.Rule != RuleDuplicateAnchor || got.Severity != SeverityWarning {
+		t.Errorf("unexpected issue %+v", got)
+	}
+	if got.Path != "$.c" || got.Line != 3 || !strings.Contains(got.Message, "line 1") {
+		t.Errorf("expected the redefinition on line 3 naming line 1, got %+v", got)
+	}
+}
+
+func TestLint_Documents(t *testing.T) {
+	// The same anchor name in two documents is not a redefinition, and a
+	// dangling alias leaves aliases of that name in other documents alone
+	input := `a: &x 1
+b: *x
+---
+c: &x 2
+d: *x
+---
+e: *y
+---
+f: &y 3
+g: *y
+`
+	issues, err := Lint([]byte(input))
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	if len(issues) != 1 {
+		t.Fatalf("expected 1 issue, got %+v", issues)
+	}
+	if got := issues[0]; got.Rule != RuleDanglingAlias || got.Path != "$.e" || got.Line != 7 {
+		t.Errorf("expected the dangling alias in the last document, got %+v", got)
+	}
+}
+
+func TestLint_SyntaxError(t *testing.T) {
+	if _, err := Lint([]byte("a: [1, 2\n")); err == nil {
+		t.Error("expected a parse error")
+	}
+}
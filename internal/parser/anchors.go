@@ -0,0 +1,260 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// UnusedAnchors returns the nodes under node that define an anchor no
+// alias refers to, in document order. Mapping keys are included.
+func UnusedAnchors(node *yaml.Node) []*yaml.Node {
+	referenced := make(map[*yaml.Node]bool)
+	var anchored []*yaml.Node
+
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		if n == nil {
+			return
+		}
+		if n.Kind == yaml.AliasNode {
+			referenced[n.Alias] = true
+		}
+		if n.Anchor != "" {
+			anchored = append(anchored, n)
+		}
+		for _, child := range n.Content {
+			walk(child)
+		}
+	}
+	walk(node)
+
+	var unused []*yaml.Node
+	for _, n := range anchored {
+		if !referenced[n] {
+			unused = append(unused, n)
+		}
+	}
+	return unused
+}
+
+// AnchorRedefinition is an anchor whose name was already defined earlier
+// in the same document. Aliases after Node refer to Node, not Previous.
+type AnchorRedefinition struct {
+	Node     *yaml.Node
+	Previous *yaml.Node
+}
+
+// RedefinedAnchors returns the anchors under node that reuse the name of
+// an earlier anchor, in document order. Anchor names are scoped to a
+// document, so node should be a single document (or a node within one).
+func RedefinedAnchors(node *yaml.Node) []AnchorRedefinition {
+	defined := make(map[string]*yaml.Node)
+	var redefined []AnchorRedefinition
+
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		if n == nil {
+			return
+		}
+		if n.Anchor != "" {
+			if prev, ok := defined[n.Anchor]; ok {
+				redefined = append(redefined, AnchorRedefinition{Node: n, Previous: prev})
+			}
+			defined[n.Anchor] = n
+		}
+		for _, child := range n.Content {
+			walk(child)
+		}
+	}
+	walk(node)
+	return redefined
+}
+
+// removeUnusedAnchors strips anchors that no alias refers to
+func removeUnusedAnchors(node *yaml.Node) {
+	for _, n := range UnusedAnchors(node) {
+		n.Anchor = ""
+	}
+}
+
+// AnchorRef is the position of an anchor definition or of an alias
+type AnchorRef struct {
+	Path   string `json:"path"` // JSONPath, e.g. $.list[0]
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// AnchorUsage is an anchor definition and the aliases that refer to it
+type AnchorUsage struct {
+	Name string `json:"name"`
+	AnchorRef
+	References int         `json:"references"`
+	Aliases    []AnchorRef `json:"aliases"`
+	Redefines  int         `json:"redefines,omitempty"` // Line of the earlier anchor with the same name (0 = none)
+}
+
+// Anchors returns every anchor defined under root in document order, each
+// with the aliases referring to it. Aliases are matched to the definition
+// they resolve to, so a name that is redefined is reported once per
+// definition.
+func Anchors(root *YamNode) []AnchorUsage {
+	var usages []*AnchorUsage
+	byNode := make(map[*yaml.Node]*AnchorUsage)
+	byName := make(map[string]*AnchorUsage)
+	var aliases []struct {
+		target *yaml.Node
+		ref    AnchorRef
+	}
+
+	visit := func(raw *yaml.Node, path string) {
+		if raw == nil {
+			return
+		}
+		ref := AnchorRef{Path: path, Line: raw.Line, Column: raw.Column}
+		if raw.Anchor != "" {
+			u := &AnchorUsage{Name: raw.Anchor, AnchorRef: ref, Aliases: []AnchorRef{}}
+			if prev, ok := byName[raw.Anchor]; ok {
+				u.Redefines = prev.Line
+			}
+			usages = append(usages, u)
+			byNode[raw] = u
+			byName[raw.Anchor] = u
+		}
+		if raw.Kind == yaml.AliasNode {
+			aliases = append(aliases, struct {
+				target *yaml.Node
+				ref    AnchorRef
+			}{raw.Alias, ref})
+		}
+	}
+
+	Walk(root, func(n *YamNode) bool {
+		visit(n.KeyRaw, n.JSONPath())
+		visit(n.Raw, n.JSONPath())
+		return true
+	})
+
+	for _, a := range aliases {
+		if u, ok := byNode[a.target]; ok {
+			u.Aliases = append(u.Aliases, a.ref)
+			u.References++
+		}
+	}
+
+	result := make([]AnchorUsage, len(usages))
+	for i, u := range usages {
+		result[i] = *u
+	}
+	return result
+}
+
+// ExtractAnchor defines the anchor &name on target and replaces every other
+// subtree under root with the same content (by Hash) with an alias *name,
+// in both the YamNode tree and the yaml.Nodes below it. It returns how many
+// subtrees were replaced, and how many identical ones were skipped because
+// they come before target, where an alias to it is not allowed. Subtrees
+// that define anchors of their own, and existing aliases, are left alone.
+// The comments around a replaced subtree are kept on the alias; comments
+// inside it are dropped.
+func ExtractAnchor(root, target *YamNode, name string) (replaced, skipped int, err error) {
+	if !validAnchorName(name) {
+		return 0, 0, fmt.Errorf("invalid anchor name %q", name)
+	}
+	switch target.Kind() {
+	case KindDocument, KindAlias:
+		return 0, 0, fmt.Errorf("cannot anchor %s: it is a %s", target.PathString(), target.Kind())
+	}
+	if target.Anchor() != "" && target.Anchor() != name {
+		return 0, 0, fmt.Errorf("%s already has the anchor &%s", target.PathString(), target.Anchor())
+	}
+	if other := findAnchor(root.Raw, name, target.Raw); other != nil {
+		return 0, 0, fmt.Errorf("anchor &%s is already defined at line %d", name, other.Line)
+	}
+
+	hash := Hash(target)
+	var matches []*YamNode
+	seen := false
+	Walk(root, func(n *YamNode) bool {
+		if n == target {
+			seen = true
+			return false
+		}
+		// Cheap checks first; hashing every subtree is quadratic
+		if n.Kind() != target.Kind() || len(n.Children) != len(target.Children) || n.Parent == nil {
+			return true
+		}
+		if Hash(n) != hash || definesAnchor(n.Raw) {
+			return true
+		}
+		if !seen {
+			skipped++
+		} else {
+			matches = append(matches, n)
+		}
+		return false
+	})
+
+	target.Raw.Anchor = name
+	for _, n := range matches {
+		alias := &yaml.Node{
+			Kind:        yaml.AliasNode,
+			Value:       name,
+			Alias:       target.Raw,
+			HeadComment: n.Raw.HeadComment,
+			LineComment: n.Raw.LineComment,
+			FootComment: n.Raw.FootComment,
+		}
+		if n.KeyRaw != nil && alias.LineComment == "" {
+			// A comment after "key:" of a block value belongs to the key;
+			// after "key: *name" it has to be the alias's own
+			alias.LineComment, n.KeyRaw.LineComment = n.KeyRaw.LineComment, ""
+		}
+		content := n.Parent.Raw.Content
+		if n.Parent.Kind() == KindMapping {
+			content[2*n.Index+1] = alias
+		} else {
+			content[n.Index] = alias
+		}
+		n.Raw = alias
+		n.Children = nil
+	}
+	return len(matches), skipped, nil
+}
+
+// findAnchor returns the first node under node other than except that
+// defines the anchor name, or nil
+func findAnchor(node *yaml.Node, name string, except *yaml.Node) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+	if node.Anchor == name && node != except {
+		return node
+	}
+	for _, child := range node.Content {
+		if found := findAnchor(child, name, except); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// definesAnchor reports whether node or any node below it has an anchor
+func definesAnchor(node *yaml.Node) bool {
+	if node.Anchor != "" {
+		return true
+	}
+	for _, child := range node.Content {
+		if definesAnchor(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// validAnchorName reports whether name can be written after & and *: not
+// empty, without whitespace or flow indicators
+func validAnchorName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, " \t\r\n,[]{}")
+}
@@ -0,0 +1,185 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAnchors(t *testing.T) {
+	input := `defaults: &defaults
+  image: app
+unused: &u 1
+dev:
+  <<: *defaults
+list:
+  - &item x
+  - *item
+  - *defaults
+`
+
+	root, err := New().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	expected := []AnchorUsage{
+		{
+			Name:       "defaults",
+			AnchorRef:  AnchorRef{Path: "$.defaults", Line: 1, Column: 11},
+			References: 2,
+			Aliases: []AnchorRef{
+				{Path: "$.dev['<<']", Line: 5, Column: 7},
+				{Path: "$.list[2]", Line: 9, Column: 5},
+			},
+		},
+		{
+			Name:      "u",
+			AnchorRef: AnchorRef{Path: "$.unused", Line: 3, Column: 9},
+			Aliases:   []AnchorRef{},
+		},
+		{
+			Name:       "item",
+			AnchorRef:  AnchorRef{Path: "$.list[0]", Line: 7, Column: 5},
+			References: 1,
+			Aliases:    []AnchorRef{{Path: "$.list[1]", Line: 8, Column: 5}},
+		},
+	}
+
+	if got := Anchors(root); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+}
+
+func TestAnchors_Redefined(t *testing.T) {
+	input := `a: &x 1
+b: *x
+c: &x 2
+d: *x
+e: *x
+`
+
+	root, err := New().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	anchors := Anchors(root)
+	if len(anchors) != 2 {
+		t.Fatalf("expected 2 definitions, got %d", len(anchors))
+	}
+	if anchors[0].References != 1 || anchors[1].References != 2 {
+		t.Errorf("expected 1 and 2 references, got %d and %d", anchors[0].References, anchors[1].References)
+	}
+	if anchors[0].Redefines != 0 || anchors[1].Redefines != 1 {
+		t.Errorf("expected the second definition to redefine line 1, got %d and %d", anchors[0].Redefines, anchors[1].Redefines)
+	}
+
+	redefined := RedefinedAnchors(root.Raw)
+	if len(redefined) != 1 || redefined[0].Node.Line != 3 || redefined[0].Previous.Line != 1 {
+		t.Errorf("expected the anchor on line 3 to redefine line 1, got %+v", redefined)
+	}
+}
+
+func TestExtractAnchor(t *testing.T) {
+	input := `early: {a: 1, b: 2}
+defaults:
+  a: 1
+  b: 2
+jobs:
+  build:
+    env: # build env
+      b: 2
+      a: 1
+  test:
+    env: {a: 1, b: 2}
+  deploy:
+    env: {a: 1, b: 3}
+  own:
+    env: &own {a: 1, b: 2}
+  alias:
+    env: *own
+`
+	expected := `early: {a: 1, b: 2}
+defaults: &base
+  a: 1
+  b: 2
+jobs:
+  build:
+    env: *base # build env
+  test:
+    env: *base
+  deploy:
+    env: {a: 1, b: 3}
+  own:
+    env: &own {a: 1, b: 2}
+  alias:
+    env: *own
+`
+
+	root, err := New().ParseString(input)
+	if err != nil {
+		t.Fatalf("ParseString failed: %v", err)
+	}
+	target, err := GetByPath(root, ".defaults")
+	if err != nil {
+		t.Fatalf("GetByPath failed: %v", err)
+	}
+
+	replaced, skipped, err := ExtractAnchor(root, target, "base")
+	if err != nil {
+		t.Fatalf("ExtractAnchor failed: %v", err)
+	}
+	if replaced != 2 || skipped != 1 {
+		t.Errorf("expected 2 replaced and 1 skipped, got %d and %d", replaced, skipped)
+	}
+
+	var out strings.Builder
+	if err := FormatTo(root.Raw, &out, DefaultFormatOptions()); err != nil {
+		t.Fatalf("FormatTo failed: %v", err)
+	}
+	if out.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	env, err := GetByPath(root, ".jobs.test.env")
+	if err != nil {
+		t.Fatalf("GetByPath failed: %v", err)
+	}
+	if env.Kind() != KindAlias || env.HasChildren() {
+		t.Errorf("expected the tree to hold an alias at .jobs.test.env, got %s", env.Kind())
+	}
+}
+
+func TestExtractAnchor_Errors(t *testing.T) {
+	input := `a: &taken {x: 1}
+b: {x: 2}
+c: *taken
+`
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"invalid name", ".b", "a b"},
+		{"name in use", ".b", "taken"},
+		{"alias", ".c", "base"},
+		{"other anchor", ".a", "base"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := New().ParseString(input)
+			if err != nil {
+				t.Fatalf("ParseString failed: %v", err)
+			}
+			node, err := GetByPath(root, tt.path)
+			if err != nil {
+				t.Fatalf("GetByPath failed: %v", err)
+			}
+			if _, _, err := ExtractAnchor(root, node, tt.want); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"strings"
+)
+
+// minBinaryLength is the length from which an untagged string that
+// decodes as base64 is treated as binary
+const minBinaryLength = 64
+
+// BinaryLabel returns a short placeholder such as "<binary 1234 bytes>"
+// for scalars holding binary data: values tagged !!binary, long strings
+// that look like base64, and PEM blocks ("<pem CERTIFICATE 1234 bytes>").
+// The second result is false for other values.
+func BinaryLabel(node *YamNode) (string, bool) {
+	if node.Kind() != KindScalar {
+		return "", false
+	}
+	value := node.Value()
+
+	typ := node.InferType()
+	if typ == TypeBinary {
+		if data, ok := decodeBase64(value); ok {
+			return fmt.Sprintf("<binary %d bytes>", len(data)), true
+		}
+		return "", false
+	}
+	if typ != TypeString {
+		return "", false
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(value), "-----BEGIN ") {
+		if block, _ := pem.Decode([]byte(value)); block != nil {
+			return fmt.Sprintf("<pem %s %d bytes>", block.Type, len(block.Bytes)), true
+		}
+	}
+
+	if looksLikeBase64(value) {
+		if data, ok := decodeBase64(value); ok {
+			return fmt.Sprintf("<binary %d bytes>", len(data)), true
+		}
+	}
+	return "", false
+}
+
+// looksLikeBase64 reports whether a string is long enough and varied
+// enough to be base64 rather than, say, a hex digest or an identifier
+func looksLikeBase64(value string) bool {
+	value = strings.NewReplacer("\n", "", "\r", "").Replace(value)
+	if len(value) < minBinaryLength || len(value)%4 != 0 {
+		return false
+	}
+	hasDigit, hasNonHex := false, false
+	for _, c := range value {
+		switch {
+		case c >= '0' && c <= '9':
+			hasDigit = true
+		case c >= 'a' && c <= 'f', c >= 'A' && c <= 'F':
+		case c >= 'g' && c <= 'z', c >= 'G' && c <= 'Z', c == '+', c == '/', c == '=':
+			hasNonHex = true
+		default:
+			return false
+		}
+	}
+	return hasDigit && hasNonHex
+}
+
+// decodeBase64 decodes standard base64, ignoring line breaks and spaces
+// (as the YAML !!binary type allows)
+func decodeBase64(value string) ([]byte, bool) {
+	data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value), ""))
+	return data, err == nil
+}
+
+// binaryEqual compares two base64 values by their decoded bytes
+func binaryEqual(a, b string) bool {
+	ad, aok := decodeBase64(a)
+	bd, bok := decodeBase64(b)
+	return aok && bok && bytes.Equal(ad, bd)
+}
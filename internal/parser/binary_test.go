@@ -0,0 +1,51 @@
+package parser
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestBinaryLabel(t *testing.T) {
+	blob := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("\x00\x01binary\xff", 10)))
+
+	input := "tagged: !!binary |\n  " + blob[:60] + "\n  " + blob[60:] + "\n" +
+		"untagged: " + blob + "\n" +
+		"short: aGVsbG8=\n" +
+		"digest: " + strings.Repeat("0123456789abcdef", 4) + "\n" +
+		"words: " + strings.Repeat("Lorem", 16) + "\n" +
+		"cert: |\n  -----BEGIN CERTIFICATE-----\n  aGVsbG8gd29ybGQ=\n  -----END CERTIFICATE-----\n"
+
+	root, err := New().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{".tagged", "<binary 90 bytes>"},
+		{".untagged", "<binary 90 bytes>"},
+		{".short", ""},
+		{".digest", ""},
+		{".words", ""},
+		{".cert", "<pem CERTIFICATE 11 bytes>"},
+	}
+
+	for _, tt := range tests {
+		node, err := GetByPath(root, tt.path)
+		if err != nil {
+			t.Fatalf("GetByPath(%s) failed: %v", tt.path, err)
+		}
+		label, ok := BinaryLabel(node)
+		if label != tt.expected || ok != (tt.expected != "") {
+			t.Errorf("%s: expected %q, got %q (%v)", tt.path, tt.expected, label, ok)
+		}
+	}
+
+	tagged, _ := GetByPath(root, ".tagged")
+	if tagged.InferType() != TypeBinary {
+		t.Errorf("expected !!binary to infer TypeBinary, got %v", tagged.InferType())
+	}
+}
@@ -0,0 +1,107 @@
+package parser
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Comment is a comment attached to a node, as extracted by Comments
+type Comment struct {
+	Path string `json:"path"` // JSONPath of the node, e.g. $.hosts[0]
+	Kind string `json:"kind"` // "head", "line" or "foot"
+	Line int    `json:"line"` // line of the node (or its key) the comment belongs to
+	Text string `json:"text"` // comment text without the leading "#"
+}
+
+// Comments returns every head, line and foot comment under root in
+// tree order, together with the path of the node it belongs to
+func Comments(root *YamNode) []Comment {
+	var comments []Comment
+	Walk(root, func(n *YamNode) bool {
+		line := n.Line()
+		if n.KeyRaw != nil {
+			line = n.KeyRaw.Line
+		}
+		for _, c := range []struct{ kind, text string }{
+			{"head", n.HeadComment()},
+			{"line", n.LineComment()},
+			{"foot", n.FootComment()},
+		} {
+			if c.text == "" {
+				continue
+			}
+			comments = append(comments, Comment{
+				Path: n.JSONPath(),
+				Kind: c.kind,
+				Line: line,
+				Text: stripCommentMarkers(c.text),
+			})
+		}
+		return true
+	})
+	return comments
+}
+
+// stripCommentMarkers removes the "#" (and one following space) from each
+// line of a comment
+func stripCommentMarkers(comment string) string {
+	lines := strings.Split(comment, "\n")
+	for i, line := range lines {
+		line = strings.TrimPrefix(strings.TrimSpace(line), "#")
+		lines[i] = strings.TrimPrefix(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// StripComments removes the comments from YAML source text: lines holding
+// only a comment are dropped and line comments are cut from the end of
+// their line, along with the whitespace before them. Everything else,
+// including lines of block scalars that start with "#", is left as is.
+func StripComments(src []byte) ([]byte, error) {
+	lines := strings.Split(string(src), "\n")
+	blockIndent := make(map[int]int)
+	lineComments := make(map[int][]string) // 0-based line -> line comments on it
+
+	decoder := yaml.NewDecoder(bytes.NewReader(src))
+	for {
+		var doc yaml.Node
+		if err := decoder.Decode(&doc); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, yamlSyntaxError(src, err)
+		}
+		collectBlockScalarLines(&doc, lines, blockIndent)
+		collectLineComments(&doc, lineComments)
+	}
+
+	kept := lines[:0]
+	for i, line := range lines {
+		if _, ok := blockIndent[i]; !ok {
+			if strings.HasPrefix(strings.TrimSpace(line), "#") {
+				continue
+			}
+			for _, comment := range lineComments[i] {
+				if rest, ok := strings.CutSuffix(line, comment); ok && strings.TrimRight(rest, " \t") != rest {
+					line = strings.TrimRight(rest, " \t")
+					break
+				}
+			}
+		}
+		kept = append(kept, line)
+	}
+	return []byte(strings.Join(kept, "\n")), nil
+}
+
+// collectLineComments maps the 0-based line of each node with a one-line
+// line comment to the comment
+func collectLineComments(node *yaml.Node, comments map[int][]string) {
+	if c := node.LineComment; c != "" && !strings.Contains(c, "\n") && node.Line > 0 {
+		comments[node.Line-1] = append(comments[node.Line-1], c)
+	}
+	for _, child := range node.Content {
+		collectLineComments(child, comments)
+	}
+}
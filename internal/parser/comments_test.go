@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestComments(t *testing.T) {
+	input := `# Service configuration
+app:
+  # Listening port
+  port: 8080 # default
+  hosts:
+    - a # primary
+    - b
+`
+
+	root, err := New().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	expected := []Comment{
+		{Path: "$.app", Kind: "head", Line: 2, Text: "Service configuration"},
+		{Path: "$.app.port", Kind: "head", Line: 4, Text: "Listening port"},
+		{Path: "$.app.port", Kind: "line", Line: 4, Text: "default"},
+		{Path: "$.app.hosts[0]", Kind: "line", Line: 6, Text: "primary"},
+	}
+
+	got := Comments(root)
+	if len(got) != len(expected) {
+		t.Fatalf("expected %d comments, got %+v", len(expected), got)
+	}
+	for i, want := range expected {
+		if got[i] != want {
+			t.Errorf("comment %d: expected %+v, got %+v", i, want, got[i])
+		}
+	}
+}
+
+func TestComments_MultiLine(t *testing.T) {
+	input := "# first line\n#second line\nkey: value\n"
+
+	root, err := New().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	got := Comments(root)
+	if len(got) != 1 || got[0].Text != "first line\nsecond line" {
+		t.Errorf("unexpected comments %+v", got)
+	}
+}
+
+func TestStripComments(t *testing.T) {
+	input := `# Service configuration
+app:
+  port: 8080   # default
+  url: "http://example.com/#top" # site
+  script: |
+    # kept: part of the block
+    run
+  hosts: # list
+    - a#b
+    # foot
+`
+	expected := `app:
+  port: 8080
+  url: "http://example.com/#top"
+  script: |
+    # kept: part of the block
+    run
+  hosts:
+    - a#b
+`
+
+	got, err := StripComments([]byte(input))
+	if err != nil {
+		t.Fatalf("StripComments failed: %v", err)
+	}
+	if string(got) != expected {
+		t.Errorf("StripComments() =\n%s\nexpected:\n%s", got, expected)
+	}
+}
@@ -0,0 +1,66 @@
+package parser
+
+// Counts summarizes the size and shape of a tree
+type Counts struct {
+	Keys     int `json:"keys"`   // Mapping entries below the root
+	Leaves   int `json:"leaves"` // Nodes without children (scalars, aliases, empty containers)
+	MaxDepth int `json:"depth"`  // Deepest nesting level below the root (0 for a lone scalar)
+}
+
+// Count walks the tree once and returns its counts
+func Count(root *YamNode) Counts {
+	var c Counts
+	Walk(root, func(n *YamNode) bool {
+		if n.Kind() == KindDocument {
+			return true
+		}
+		if n != root && n.Parent != nil && n.Parent.Kind() == KindMapping {
+			c.Keys++
+		}
+		if !n.HasChildren() {
+			c.Leaves++
+		}
+		if d := n.Depth - root.Depth; d > c.MaxDepth {
+			c.MaxDepth = d
+		}
+		return true
+	})
+	return c
+}
+
+// TypeCount is the number of scalars of one inferred type, with the paths
+// of the first few
+type TypeCount struct {
+	Type     string   `json:"type"` // ScalarType.String, e.g. "str" or "bool"
+	Count    int      `json:"count"`
+	Examples []string `json:"examples"`
+}
+
+// CountTypes buckets the scalars of the tree by InferType and returns the
+// types that occur, in ScalarType order, each with the paths of up to
+// examples scalars in document order. Aliases and empty containers are
+// leaves without a scalar type and are not included.
+func CountTypes(root *YamNode, examples int) []TypeCount {
+	var buckets [TypeBinary + 1]TypeCount
+	Walk(root, func(n *YamNode) bool {
+		if n.Kind() != KindScalar {
+			return true
+		}
+		b := &buckets[n.InferType()]
+		b.Count++
+		if len(b.Examples) < examples {
+			b.Examples = append(b.Examples, n.PathString())
+		}
+		return true
+	})
+
+	var counts []TypeCount
+	for t, b := range buckets {
+		if b.Count == 0 {
+			continue
+		}
+		b.Type = ScalarType(t).String()
+		counts = append(counts, b)
+	}
+	return counts
+}
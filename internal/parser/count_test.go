@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCount(t *testing.T) {
+	input := `name: app
+db:
+  host: localhost
+  ports: [5432, 5433]
+tags: []
+`
+
+	root, err := New().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	expected := Counts{Keys: 5, Leaves: 5, MaxDepth: 3}
+	if got := Count(root); got != expected {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+
+	db, err := GetByPath(root, ".db")
+	if err != nil {
+		t.Fatalf("GetByPath failed: %v", err)
+	}
+	expected = Counts{Keys: 2, Leaves: 3, MaxDepth: 2}
+	if got := Count(db); got != expected {
+		t.Errorf("subtree: expected %+v, got %+v", expected, got)
+	}
+}
+
+func TestCountTypes(t *testing.T) {
+	input := `name: app
+debug: false
+verbose: yes
+db:
+  host: localhost
+  user: admin
+  pass: ~
+  ports: [5432, 5433, 5434]
+base: &base {}
+copy: *base
+`
+
+	root, err := New().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	expected := []TypeCount{
+		{Type: "str", Count: 4, Examples: []string{"$.name", "$.verbose"}},
+		{Type: "int", Count: 3, Examples: []string{"$.db.ports.0", "$.db.ports.1"}},
+		{Type: "bool", Count: 1, Examples: []string{"$.debug"}},
+		{Type: "null", Count: 1, Examples: []string{"$.db.pass"}},
+	}
+	if got := CountTypes(root, 2); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %+v, got %+v", expected, got)
+	}
+}
@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// ToCSV writes a sequence of mappings as CSV.
+// The header row is the union of all keys in first-seen order; missing keys
+// become empty cells and nested containers are JSON-encoded. Aliases stand
+// for the rows and values they refer to, and merge keys (<<) in a row add
+// the merged fields (see mergedEntries).
+func ToCSV(node *YamNode, w io.Writer) error {
+	// Skip document wrapper
+	if node.Kind() == KindDocument && len(node.Children) > 0 {
+		node = node.Children[0]
+	}
+	node = resolveAlias(node)
+
+	if node.Kind() != KindSequence {
+		return fmt.Errorf("CSV export requires a sequence of mappings, got %s", node.Kind())
+	}
+
+	// Collect the union of keys, preserving first-seen order
+	var header []string
+	seen := make(map[string]bool)
+	rows := make([][]*YamNode, len(node.Children))
+	for i, item := range node.Children {
+		item = resolveAlias(item)
+		if item.Kind() != KindMapping {
+			return fmt.Errorf("CSV export requires a sequence of mappings, item %d is %s", item.Index, item.Kind())
+		}
+		rows[i] = mergedEntries(item)
+		for _, field := range rows[i] {
+			if !seen[field.Key] {
+				seen[field.Key] = true
+				header = append(header, field.Key)
+			}
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, fields := range rows {
+		byKey := make(map[string]*YamNode, len(fields))
+		for _, field := range fields {
+			byKey[field.Key] = field
+		}
+
+		record := make([]string, len(header))
+		for i, key := range header {
+			field, ok := byKey[key]
+			if !ok {
+				continue
+			}
+			cell, err := csvCell(field)
+			if err != nil {
+				return err
+			}
+			record[i] = cell
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvCell formats a field value for a CSV cell
+func csvCell(field *YamNode) (string, error) {
+	field = resolveAlias(field)
+	if field.IsContainer() {
+		jsonBytes, err := ToJSON(field, false)
+		if err != nil {
+			return "", err
+		}
+		return string(jsonBytes), nil
+	}
+	return leafValue(field), nil
+}
@@ -0,0 +1,124 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToCSV(t *testing.T) {
+	input := `users:
+  - name: alice
+    age: 30
+  - name: bob
+    email: "bob@example.com"
+    roles: [admin, dev]
+  - name: "carol, jr"
+    age: null`
+
+	root, err := New().ParseString(input)
+	if err != nil {
+		t.Fatalf("ParseString failed: %v", err)
+	}
+	users, err := GetByPath(root, ".users")
+	if err != nil {
+		t.Fatalf("GetByPath failed: %v", err)
+	}
+
+	var buf strings.Builder
+	if err := ToCSV(users, &buf); err != nil {
+		t.Fatalf("ToCSV failed: %v", err)
+	}
+
+	expected := `name,age,email,roles
+alice,30,,
+bob,,bob@example.com,"[""admin"",""dev""]"
+"carol, jr",,,
+`
+	if buf.String() != expected {
+		t.Errorf("ToCSV mismatch\ngot:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestToCSV_Aliases(t *testing.T) {
+	input := `admin: &admin
+  name: root
+  roles: &roles [admin, dev]
+team: &team ops
+users:
+  - *admin
+  - name: alice
+    team: *team
+    roles: *roles`
+
+	root, err := New().ParseString(input)
+	if err != nil {
+		t.Fatalf("ParseString failed: %v", err)
+	}
+	users, err := GetByPath(root, ".users")
+	if err != nil {
+		t.Fatalf("GetByPath failed: %v", err)
+	}
+
+	var buf strings.Builder
+	if err := ToCSV(users, &buf); err != nil {
+		t.Fatalf("ToCSV failed: %v", err)
+	}
+
+	expected := `name,roles,team
+root,"[""admin"",""dev""]",
+alice,"[""admin"",""dev""]",ops
+`
+	if buf.String() != expected {
+		t.Errorf("ToCSV mismatch\ngot:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestToCSV_MergeKeys(t *testing.T) {
+	input := `defaults: &defaults {role: dev, team: ops}
+users:
+  - name: alice
+    <<: *defaults
+  - <<: *defaults
+    name: bob
+    role: admin`
+
+	root, err := New().ParseString(input)
+	if err != nil {
+		t.Fatalf("ParseString failed: %v", err)
+	}
+	users, err := GetByPath(root, ".users")
+	if err != nil {
+		t.Fatalf("GetByPath failed: %v", err)
+	}
+
+	var buf strings.Builder
+	if err := ToCSV(users, &buf); err != nil {
+		t.Fatalf("ToCSV failed: %v", err)
+	}
+
+	expected := `name,role,team
+alice,dev,ops
+bob,admin,ops
+`
+	if buf.String() != expected {
+		t.Errorf("ToCSV mismatch\ngot:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestToCSV_RequiresSequenceOfMappings(t *testing.T) {
+	tests := []string{
+		"name: alice",
+		"- one\n- two",
+	}
+
+	for _, input := range tests {
+		root, err := New().ParseString(input)
+		if err != nil {
+			t.Fatalf("ParseString failed: %v", err)
+		}
+		var buf strings.Builder
+		if err := ToCSV(root, &buf); err == nil {
+			t.Errorf("expected error for input %q", input)
+		}
+	}
+}
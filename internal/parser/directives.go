@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Directives records the prologue and document markers of the source,
+// which yaml.Node does not keep
+type Directives struct {
+	Lines []string // %YAML and %TAG directives, as written
+	Start bool     // The document starts with an explicit "---"
+	End   bool     // The document ends with an explicit "..."
+}
+
+// StartMarker reports whether "---" must be written before the document:
+// when the source had one, or when directives precede it
+func (d *Directives) StartMarker() bool {
+	return d != nil && (d.Start || len(d.Lines) > 0)
+}
+
+// scanDirectives reads the directives and markers of the first document in
+// src. It returns nil when there are none. %YAML lines are blanked in the
+// returned source (keeping line numbers) because yaml.v3 rejects any
+// version other than 1.1.
+func scanDirectives(src []byte) (*Directives, []byte) {
+	var d Directives
+	out := src
+	cloned := false
+	inBody := false
+
+	offset := 0
+	for offset < len(src) {
+		end := bytes.IndexByte(src[offset:], '\n')
+		if end < 0 {
+			end = len(src) - offset
+		}
+		line := strings.TrimRight(string(src[offset:offset+end]), " \t\r")
+		lineStart := offset
+		offset += end + 1
+
+		if !inBody {
+			switch {
+			case line == "" || strings.HasPrefix(line, "#"):
+				continue
+			case strings.HasPrefix(line, "%"):
+				d.Lines = append(d.Lines, line)
+				if strings.HasPrefix(line, "%YAML") {
+					if !cloned {
+						out, cloned = bytes.Clone(src), true
+					}
+					for i := lineStart; i < lineStart+len(line); i++ {
+						out[i] = ' '
+					}
+				}
+				continue
+			case isDocumentMarker(line, "---"):
+				d.Start = true
+				inBody = true
+				continue
+			}
+			inBody = true
+		}
+
+		// Only the first document is parsed
+		if isDocumentMarker(line, "---") {
+			break
+		}
+		if isDocumentMarker(line, "...") {
+			d.End = true
+			break
+		}
+	}
+
+	if len(d.Lines) == 0 && !d.Start && !d.End {
+		return nil, out
+	}
+	return &d, out
+}
+
+// isDocumentMarker reports whether line starts with the marker ("---" or
+// "...") followed by nothing, whitespace or content
+func isDocumentMarker(line, marker string) bool {
+	if !strings.HasPrefix(line, marker) {
+		return false
+	}
+	rest := line[len(marker):]
+	return rest == "" || rest[0] == ' ' || rest[0] == '\t'
+}
@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// parseDocument parses input with the yam parser, keeping the directives
+func parseDocument(t *testing.T, input string) *YamNode {
+	t.Helper()
+	root, err := New().ParseString(input)
+	if err != nil {
+		t.Fatalf("ParseString failed: %v", err)
+	}
+	return root
+}
+
+func TestParse_Directives(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  *Directives
+	}{
+		{"none", "a: 1\n", nil},
+		{"start marker", "# head\n---\na: 1\n", &Directives{Start: true}},
+		{"version and tag", "%YAML 1.2\n%TAG !e! tag:example.com,2000:\n---\na: !e!x 1\n...\n",
+			&Directives{Lines: []string{"%YAML 1.2", "%TAG !e! tag:example.com,2000:"}, Start: true, End: true}},
+		{"end of first document only", "a: 1\n---\nb: 2\n...\n", nil},
+		{"marker with content", "--- {a: 1}\n", &Directives{Start: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := parseDocument(t, tt.input)
+			if !reflect.DeepEqual(root.Directives, tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, root.Directives)
+			}
+		})
+	}
+}
+
+func TestParse_YAML12DirectiveKeepsLines(t *testing.T) {
+	root := parseDocument(t, "%YAML 1.2\n---\na: 1\nb: 2\n")
+	b, err := GetByPath(root, ".b")
+	if err != nil {
+		t.Fatalf("GetByPath failed: %v", err)
+	}
+	if b.Line() != 4 {
+		t.Errorf("expected .b on line 4, got %d", b.Line())
+	}
+}
+
+func TestFormatTo_Directives(t *testing.T) {
+	input := "%YAML 1.2\n---\na:   1\n...\n"
+	root := parseDocument(t, input)
+
+	opts := DefaultFormatOptions()
+	opts.Directives = root.Directives
+	got, err := FormatString(root.Raw, opts)
+	if err != nil {
+		t.Fatalf("FormatString failed: %v", err)
+	}
+	if want := "%YAML 1.2\n---\na: 1\n...\n"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestFormatTo_DocumentMarkers(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{"a: 1\n", "---\na: 1\n...\n"},
+		{"---\na: 1\n", "---\na: 1\n...\n"},
+		{"%YAML 1.2\n---\na: 1\n", "%YAML 1.2\n---\na: 1\n...\n"},
+	}
+
+	for _, tt := range tests {
+		root := parseDocument(t, tt.input)
+		opts := DefaultFormatOptions()
+		opts.Directives = root.Directives
+		opts.DocumentMarkers = true
+		got, err := FormatString(root.Raw, opts)
+		if err != nil {
+			t.Fatalf("FormatString failed: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("FormatString(%q) = %q, expected %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseAll(t *testing.T) {
+	docs, err := New().ParseAll(strings.NewReader("%YAML 1.2\n---\na: 1\n---\nb: 2\n...\n---\n- c\n"))
+	if err != nil {
+		t.Fatalf("ParseAll failed: %v", err)
+	}
+	if len(docs) != 3 {
+		t.Fatalf("expected 3 documents, got %d", len(docs))
+	}
+	if docs[0].Directives == nil || docs[0].Directives.Lines[0] != "%YAML 1.2" {
+		t.Errorf("expected the first document to keep the directives, got %+v", docs[0].Directives)
+	}
+	if v, err := GetByPath(docs[1], ".b"); err != nil || v.Value() != "2" {
+		t.Errorf("expected .b = 2 in the second document, got %v (%v)", v, err)
+	}
+	if docs[2].Children[0].Kind() != KindSequence {
+		t.Errorf("expected the third document to be a sequence")
+	}
+
+	if _, err := New().ParseAll(strings.NewReader("")); err == nil {
+		t.Error("expected an error for empty input")
+	}
+}
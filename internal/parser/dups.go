@@ -0,0 +1,93 @@
+package parser
+
+import "sort"
+
+// DupLocation is one place a duplicated value appears, with its path in
+// JSONPath notation (e.g. $.hosts[0])
+type DupLocation struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+}
+
+// Duplicate is a value that appears at several paths
+type Duplicate struct {
+	Value     string        `json:"value"`          // The scalar, or a container as compact JSON
+	Kind      string        `json:"kind"`           // "scalar", "mapping" or "sequence"
+	Type      string        `json:"type,omitempty"` // Inferred type of a scalar (see ScalarType.String)
+	Count     int           `json:"count"`
+	Locations []DupLocation `json:"locations"`
+}
+
+// FindDuplicates returns the values under root that appear at least
+// minCount times (minimum 2), most repeated first and then in document
+// order. Scalars are compared by value and resolved type, so 3 and "3"
+// differ; nulls, booleans and empty strings are not reported. With
+// subtrees, mappings and sequences with the same content (by Hash) are
+// reported too, and the values inside a repeated subtree are not reported
+// again. Aliases are intended reuse and are skipped.
+func FindDuplicates(root *YamNode, minCount int, subtrees bool) []Duplicate {
+	if minCount < 2 {
+		minCount = 2
+	}
+
+	// Count the candidates first, so that a repeated subtree can stop the
+	// walk from reporting its contents
+	hashes := make(map[*YamNode]string)
+	counts := make(map[string]int)
+	Walk(root, func(n *YamNode) bool {
+		if isDupCandidate(n, subtrees) {
+			h := Hash(n)
+			hashes[n] = h
+			counts[h]++
+		}
+		return true
+	})
+
+	var dups []*Duplicate
+	byHash := make(map[string]*Duplicate)
+	Walk(root, func(n *YamNode) bool {
+		h, ok := hashes[n]
+		if !ok || counts[h] < minCount {
+			return true
+		}
+		d := byHash[h]
+		if d == nil {
+			d = &Duplicate{Value: ToRawValue(n), Kind: n.Kind().String()}
+			if n.Kind() == KindScalar {
+				d.Type = n.InferType().String()
+			}
+			byHash[h] = d
+			dups = append(dups, d)
+		}
+		d.Count++
+		d.Locations = append(d.Locations, DupLocation{Path: n.JSONPath(), Line: n.Line()})
+		return false
+	})
+
+	// A value counted inside repeated subtrees may repeat too few times
+	// outside them
+	var result []Duplicate
+	for _, d := range dups {
+		if d.Count >= minCount {
+			result = append(result, *d)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Count > result[j].Count })
+	return result
+}
+
+// isDupCandidate reports whether a node's value is worth reporting when
+// it repeats
+func isDupCandidate(n *YamNode, subtrees bool) bool {
+	switch n.Kind() {
+	case KindScalar:
+		switch n.InferType() {
+		case TypeNull, TypeBoolean:
+			return false
+		}
+		return n.Value() != ""
+	case KindMapping, KindSequence:
+		return subtrees && n.HasChildren() && n.Parent != nil && n.Parent.Kind() != KindDocument
+	}
+	return false
+}
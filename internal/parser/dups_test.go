@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	input := `api:
+  url: https://api.example.com
+  port: 8080
+  enabled: true
+worker:
+  url: https://api.example.com
+  port: "8080"
+  enabled: true
+  limits: {cpu: 2, memory: 1Gi}
+batch:
+  url: https://api.example.com
+  port: 8080
+  limits: {cpu: 2, memory: 1Gi}
+`
+	root := parseDocument(t, input)
+
+	dups := FindDuplicates(root, 2, false)
+	want := []Duplicate{
+		{Value: "https://api.example.com", Kind: "scalar", Type: "str", Count: 3, Locations: []DupLocation{
+			{Path: "$.api.url", Line: 2}, {Path: "$.worker.url", Line: 6}, {Path: "$.batch.url", Line: 11},
+		}},
+		{Value: "8080", Kind: "scalar", Type: "int", Count: 2, Locations: []DupLocation{
+			{Path: "$.api.port", Line: 3}, {Path: "$.batch.port", Line: 12},
+		}},
+		{Value: "2", Kind: "scalar", Type: "int", Count: 2, Locations: []DupLocation{
+			{Path: "$.worker.limits.cpu", Line: 9}, {Path: "$.batch.limits.cpu", Line: 13},
+		}},
+		{Value: "1Gi", Kind: "scalar", Type: "str", Count: 2, Locations: []DupLocation{
+			{Path: "$.worker.limits.memory", Line: 9}, {Path: "$.batch.limits.memory", Line: 13},
+		}},
+	}
+	if !reflect.DeepEqual(dups, want) {
+		t.Errorf("expected %+v, got %+v", want, dups)
+	}
+
+	// A repeated subtree is reported once, without its contents
+	dups = FindDuplicates(root, 2, true)
+	if len(dups) != 3 || dups[2].Kind != "mapping" || dups[2].Value != `{"cpu":2,"memory":"1Gi"}` {
+		t.Errorf("expected the limits mapping as the last duplicate, got %+v", dups)
+	}
+
+	// --min-count drops values repeated fewer times
+	dups = FindDuplicates(root, 3, false)
+	if len(dups) != 1 || dups[0].Value != "https://api.example.com" {
+		t.Errorf("expected only the URL with min count 3, got %+v", dups)
+	}
+
+	// Sequence items are written with brackets
+	dups = FindDuplicates(parseDocument(t, "hosts:\n  - db.local\n  - db.local\n"), 2, false)
+	wantLocations := []DupLocation{{Path: "$.hosts[0]", Line: 2}, {Path: "$.hosts[1]", Line: 3}}
+	if len(dups) != 1 || !reflect.DeepEqual(dups[0].Locations, wantLocations) {
+		t.Errorf("expected locations %+v, got %+v", wantLocations, dups)
+	}
+}
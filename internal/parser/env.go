@@ -0,0 +1,196 @@
+package parser
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envKeySeparator joins path segments in environment variable names
+const envKeySeparator = "__"
+
+// ToEnv converts a YamNode tree to dotenv format.
+// Each scalar leaf becomes one KEY=value line, where KEY is the path below
+// node in UPPER_SNAKE case joined by "__" (e.g. DATABASE__HOST, ITEMS__0).
+// Aliases are exported as the values they refer to (see exportLeaves).
+func ToEnv(node *YamNode) string {
+	var buf strings.Builder
+	for _, leaf := range exportLeaves(node) {
+		segments := RelativePath(node, leaf)
+		if len(segments) == 0 {
+			segments = leaf.Path
+		}
+
+		buf.WriteString(EnvKey(segments))
+		buf.WriteString("=")
+		buf.WriteString(quoteEnvValue(leafValue(leaf)))
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
+
+// EnvKey builds an environment variable name from path segments
+func EnvKey(segments []string) string {
+	parts := make([]string, len(segments))
+	for i, segment := range segments {
+		parts[i] = envSegment(segment)
+	}
+	return strings.Join(parts, envKeySeparator)
+}
+
+// envSegment upper-cases a path segment and replaces characters that are
+// not valid in environment variable names with '_'
+func envSegment(segment string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(segment) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// exportLeaves returns the scalar leaves below node like Leaves, with each
+// alias replaced by what it refers to and merge keys applied (see
+// walkExpanded)
+func exportLeaves(node *YamNode) []*YamNode {
+	var leaves []*YamNode
+	walkExpanded(node, func(n *YamNode) {
+		if kind := n.Kind(); kind == KindScalar || kind == KindAlias {
+			leaves = append(leaves, n)
+		}
+	})
+	return leaves
+}
+
+// walkExpanded calls fn for node and each node below it in document order,
+// with aliases replaced by what they refer to, paths continuing from the
+// alias. Merge keys (<<) are applied, so merged entries appear in the
+// mapping that merges them (see mergedEntries). An alias of a node that
+// encloses it is visited as it is, so recursive anchors are expanded once.
+func walkExpanded(node *YamNode, fn func(*YamNode)) {
+	if node.Kind() == KindAlias {
+		target := resolveAlias(node)
+		if target == node || enclosedBy(node, target.Raw) {
+			fn(node)
+			return
+		}
+		node = target
+	}
+	fn(node)
+
+	children := node.Children
+	if node.Kind() == KindMapping {
+		children = mergedEntries(node)
+	}
+	for _, child := range children {
+		walkExpanded(child, fn)
+	}
+}
+
+// mergedEntries returns the entries of a mapping with its merge keys (<<)
+// applied: each is replaced by the entries of the mappings it merges, with
+// paths in this mapping, leaving out keys that are set explicitly or by an
+// earlier merged mapping
+func mergedEntries(mapping *YamNode) []*YamNode {
+	seen := make(map[string]bool)
+	for _, child := range mapping.Children {
+		if !isMergeKey(child) {
+			seen[child.Key] = true
+		}
+	}
+
+	var entries []*YamNode
+	for _, child := range mapping.Children {
+		if !isMergeKey(child) {
+			entries = append(entries, child)
+			continue
+		}
+		for _, source := range mergeSources(child.Raw) {
+			if source == mapping.Raw || enclosedBy(mapping, source) {
+				continue
+			}
+			p := &Parser{YAMLVersion: mapping.YAMLVersion}
+			merged, err := p.convertNode(source, mapping.Parent, mapping.Path, mapping.Depth)
+			if err != nil {
+				continue
+			}
+			merged.Key, merged.KeyRaw, merged.Index = mapping.Key, mapping.KeyRaw, mapping.Index
+			for _, entry := range mergedEntries(merged) {
+				if !seen[entry.Key] {
+					seen[entry.Key] = true
+					entries = append(entries, entry)
+				}
+			}
+		}
+	}
+	return entries
+}
+
+// isMergeKey reports whether a mapping entry has the merge key <<
+func isMergeKey(entry *YamNode) bool {
+	return entry.KeyRaw != nil && entry.KeyRaw.ShortTag() == "!!merge"
+}
+
+// mergeSources returns the mappings a merge key's value merges: a mapping,
+// an alias of one, or a sequence of those
+func mergeSources(value *yaml.Node) []*yaml.Node {
+	switch value.Kind {
+	case yaml.MappingNode:
+		return []*yaml.Node{value}
+	case yaml.AliasNode:
+		if value.Alias != nil {
+			return mergeSources(value.Alias)
+		}
+	case yaml.SequenceNode:
+		var sources []*yaml.Node
+		for _, item := range value.Content {
+			if item.Kind != yaml.SequenceNode {
+				sources = append(sources, mergeSources(item)...)
+			}
+		}
+		return sources
+	}
+	return nil
+}
+
+// enclosedBy reports whether raw is the node of one of node's ancestors
+func enclosedBy(node *YamNode, raw *yaml.Node) bool {
+	for p := node.Parent; p != nil; p = p.Parent {
+		if p.Raw == raw {
+			return true
+		}
+	}
+	return false
+}
+
+// leafValue returns the exported value of a leaf; nulls become empty and
+// aliases left by exportLeaves are written as *name
+func leafValue(leaf *YamNode) string {
+	switch {
+	case leaf.Kind() == KindAlias:
+		return "*" + leaf.Value()
+	case leaf.Kind() == KindScalar && leaf.InferType() == TypeNull:
+		return ""
+	}
+	return leaf.Value()
+}
+
+// quoteEnvValue double-quotes values that a shell or dotenv parser would
+// otherwise split or interpret
+func quoteEnvValue(value string) string {
+	if !strings.ContainsAny(value, " \t\n\r\"'#$\\`") {
+		return value
+	}
+	r := strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"$", `\$`,
+		"`", "\\`",
+		"\n", `\n`,
+		"\r", `\r`,
+	)
+	return `"` + r.Replace(value) + `"`
+}
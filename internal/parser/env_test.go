@@ -0,0 +1,130 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestToEnv(t *testing.T) {
+	input := `database:
+  host: localhost
+  port: 5432
+  password: "p@ss word"
+items:
+  - one
+  - two
+app-name: demo
+empty: null`
+
+	root, err := New().ParseString(input)
+	if err != nil {
+		t.Fatalf("ParseString failed: %v", err)
+	}
+
+	expected := `DATABASE__HOST=localhost
+DATABASE__PORT=5432
+DATABASE__PASSWORD="p@ss word"
+ITEMS__0=one
+ITEMS__1=two
+APP_NAME=demo
+EMPTY=
+`
+	if got := ToEnv(root); got != expected {
+		t.Errorf("ToEnv mismatch\ngot:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestToEnv_Subtree(t *testing.T) {
+	root, err := New().ParseString("database:\n  host: localhost\n")
+	if err != nil {
+		t.Fatalf("ParseString failed: %v", err)
+	}
+
+	sub, err := GetByPath(root, ".database")
+	if err != nil {
+		t.Fatalf("GetByPath failed: %v", err)
+	}
+
+	if got := ToEnv(sub); got != "HOST=localhost\n" {
+		t.Errorf("expected keys relative to subtree, got %q", got)
+	}
+}
+
+func TestToEnv_Aliases(t *testing.T) {
+	input := `base: &base
+  host: localhost
+  port: 5432
+version: &v "1.0"
+copy: *v
+db: *base
+list: &list
+  - a
+  - *list`
+
+	root, err := New().ParseString(input)
+	if err != nil {
+		t.Fatalf("ParseString failed: %v", err)
+	}
+
+	expected := `BASE__HOST=localhost
+BASE__PORT=5432
+VERSION=1.0
+COPY=1.0
+DB__HOST=localhost
+DB__PORT=5432
+LIST__0=a
+LIST__1=*list
+`
+	if got := ToEnv(root); got != expected {
+		t.Errorf("ToEnv mismatch\ngot:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestToEnv_MergeKeys(t *testing.T) {
+	input := `b: &b {x: 1, y: 0}
+c: &c {y: 3, z: 4}
+m: {<<: *b, y: 2}
+n:
+  <<: [*b, *c]
+  w: 5`
+
+	root, err := New().ParseString(input)
+	if err != nil {
+		t.Fatalf("ParseString failed: %v", err)
+	}
+
+	// Explicit keys win over merged ones, and earlier merges over later
+	expected := `B__X=1
+B__Y=0
+C__Y=3
+C__Z=4
+M__X=1
+M__Y=2
+N__X=1
+N__Y=0
+N__Z=4
+N__W=5
+`
+	if got := ToEnv(root); got != expected {
+		t.Errorf("ToEnv mismatch\ngot:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestQuoteEnvValue(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"plain", "plain"},
+		{"", ""},
+		{"with space", `"with space"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{"$HOME", `"\$HOME"`},
+		{"line1\nline2", `"line1\nline2"`},
+	}
+
+	for _, tt := range tests {
+		if got := quoteEnvValue(tt.value); got != tt.expected {
+			t.Errorf("quoteEnvValue(%q) = %q, expected %q", tt.value, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package parser
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EqualOptions selects which differences Equal ignores. The zero value
+// compares strictly: mapping keys in order, comments, and scalars by tag,
+// inferred type and exact text.
+type EqualOptions struct {
+	// IgnoreComments skips the head, line and foot comments of every node
+	// and of mapping keys
+	IgnoreComments bool
+
+	// IgnoreKeyOrder matches mapping entries by key instead of position.
+	// Sequence order always matters.
+	IgnoreKeyOrder bool
+
+	// NormalizeScalars compares scalars by value as ScalarsEqual does, so
+	// 3 and 0x3, true and True, or ~ and null are equal. Scalars of
+	// different types are never equal.
+	NormalizeScalars bool
+}
+
+// Equal reports whether two subtrees have the same structure and values
+// under opts. Flow or block style, indentation and positions never matter,
+// nor does quoting unless it changes the type a scalar is read as.
+// Documents are compared by their content, and aliases by the content they
+// refer to; the comments of an anchor belong to the anchored node and are
+// not compared again at each alias. A recursive alias is equal to another
+// one when the anchors they refer to are equal apart from the recursion.
+// Two nil nodes are equal.
+func Equal(a, b *YamNode, opts EqualOptions) bool {
+	e := &equaler{
+		opts:      opts,
+		targets:   make(map[*yaml.Node]*YamNode),
+		indexed:   make(map[*YamNode]bool),
+		comparing: make(map[[2]*yaml.Node]bool),
+	}
+	return e.equal(a, b, opts.IgnoreComments)
+}
+
+// equaler holds the state of one Equal call
+type equaler struct {
+	opts      EqualOptions
+	targets   map[*yaml.Node]*YamNode // anchored nodes by their yaml.Node
+	indexed   map[*YamNode]bool       // trees whose anchored nodes are in targets
+	comparing map[[2]*yaml.Node]bool  // pairs of anchored nodes being compared
+}
+
+func (e *equaler) equal(a, b *YamNode, ignoreComments bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if !ignoreComments && !commentsEqual(a, b) {
+		return false
+	}
+	if a.Kind() == KindAlias || b.Kind() == KindAlias {
+		a, b = e.resolve(a), e.resolve(b)
+		if a == nil || b == nil {
+			return a == b
+		}
+		// Comparing the same pair again means following a recursion;
+		// if the rest of the pair differs, the first comparison says so
+		pair := [2]*yaml.Node{a.Raw, b.Raw}
+		if e.comparing[pair] {
+			return true
+		}
+		e.comparing[pair] = true
+		defer delete(e.comparing, pair)
+		return e.equal(a, b, true)
+	}
+	if a.Kind() != b.Kind() {
+		return false
+	}
+
+	switch a.Kind() {
+	case KindDocument:
+		return e.equal(firstChild(a), firstChild(b), ignoreComments)
+
+	case KindMapping:
+		if len(a.Children) != len(b.Children) {
+			return false
+		}
+		for i, child := range a.Children {
+			other := b.Children[i]
+			if e.opts.IgnoreKeyOrder {
+				other = childByKey(b, child.Key)
+			}
+			if other == nil || other.Key != child.Key || !e.equal(child, other, ignoreComments) {
+				return false
+			}
+		}
+		return true
+
+	case KindSequence:
+		if len(a.Children) != len(b.Children) {
+			return false
+		}
+		for i, child := range a.Children {
+			if !e.equal(child, b.Children[i], ignoreComments) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if e.opts.NormalizeScalars {
+		return ScalarsEqual(a, b)
+	}
+	// Under YAML 1.1 quoting decides whether yes or on is a boolean
+	return a.Tag() == b.Tag() && a.InferType() == b.InferType() && a.Value() == b.Value()
+}
+
+// resolve returns the node an alias refers to, or node itself if it is
+// not an alias. The anchored node is looked up in the tree the alias
+// belongs to; one outside of it is converted once and reused.
+func (e *equaler) resolve(node *YamNode) *YamNode {
+	for node != nil && node.Kind() == KindAlias {
+		target := node.Raw.Alias
+		if target == nil {
+			return nil
+		}
+		top := node
+		for top.Parent != nil {
+			top = top.Parent
+		}
+		if !e.indexed[top] {
+			e.indexed[top] = true
+			Walk(top, func(n *YamNode) bool {
+				if n.Raw != nil && n.Raw.Anchor != "" {
+					e.targets[n.Raw] = n
+				}
+				return true
+			})
+		}
+		if _, ok := e.targets[target]; !ok {
+			converted, err := New().convertNode(target, nil, node.Path, 0)
+			if err != nil {
+				return nil
+			}
+			e.targets[target] = converted
+		}
+		node = e.targets[target]
+	}
+	return node
+}
+
+// childByKey returns the first entry of mapping with key, or nil
+func childByKey(mapping *YamNode, key string) *YamNode {
+	for _, child := range mapping.Children {
+		if child.Key == key {
+			return child
+		}
+	}
+	return nil
+}
+
+// commentsEqual reports whether two nodes carry the same comments
+func commentsEqual(a, b *YamNode) bool {
+	return a.HeadComment() == b.HeadComment() &&
+		a.LineComment() == b.LineComment() &&
+		a.FootComment() == b.FootComment()
+}
+
+// firstChild returns the content of a document, or nil if it is empty
+func firstChild(doc *YamNode) *YamNode {
+	if len(doc.Children) == 0 {
+		return nil
+	}
+	return doc.Children[0]
+}
+
+// ScalarsEqual reports whether two scalars hold the same value. When both
+// infer the same type the parsed values are compared, so 3, 3.0 and 0x3
+// are equal numbers, True and true are equal booleans, null, ~ and an
+// empty value are all the same null, and !!binary values are compared by
+// their decoded bytes. Otherwise the raw strings must match
+// and both sides must have the same type.
+func ScalarsEqual(a, b *YamNode) bool {
+	typ := a.InferType()
+	if typ != b.InferType() {
+		return false
+	}
+	av, bv := a.Value(), b.Value()
+	if av == bv {
+		return true
+	}
+
+	switch typ {
+	case TypeNull:
+		return true
+	case TypeBoolean:
+		return parseBool(av) == parseBool(bv)
+	case TypeNumber:
+		ar, aok := parseNumber(av)
+		br, bok := parseNumber(bv)
+		return aok && bok && ar.Cmp(br) == 0
+	case TypeTimestamp:
+		at, _, aok := ParseTimestamp(av)
+		bt, _, bok := ParseTimestamp(bv)
+		return aok && bok && at.Equal(bt)
+	case TypeBinary:
+		return binaryEqual(av, bv)
+	}
+	return false
+}
+
+// parseBool returns the value of a boolean scalar in any YAML spelling
+func parseBool(value string) bool {
+	switch strings.ToLower(value) {
+	case "true", "yes", "on", "y":
+		return true
+	}
+	return false
+}
+
+// parseNumber parses a YAML or JSON number exactly, including hex, octal
+// and binary integers, underscores and arbitrary precision decimals.
+// Infinities and NaN are not numbers here; they only equal themselves.
+func parseNumber(value string) (*big.Rat, bool) {
+	if i, err := strconv.ParseInt(value, 0, 64); err == nil {
+		return new(big.Rat).SetInt64(i), true
+	}
+	if strings.HasPrefix(value, "0o") || strings.HasPrefix(value, "0x") || strings.HasPrefix(value, "0b") {
+		i, ok := new(big.Int).SetString(value, 0)
+		if !ok {
+			return nil, false
+		}
+		return new(big.Rat).SetInt(i), true
+	}
+	r, ok := new(big.Rat).SetString(strings.ReplaceAll(value, "_", ""))
+	return r, ok
+}
@@ -0,0 +1,113 @@
+package parser
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestScalarsEqual(t *testing.T) {
+	scalar := func(value, tag string) *YamNode {
+		return &YamNode{Raw: &yaml.Node{Kind: yaml.ScalarNode, Value: value, Tag: tag}}
+	}
+
+	tests := []struct {
+		name     string
+		a, b     *YamNode
+		expected bool
+	}{
+		{"same int", scalar("3", "!!int"), scalar("3", "!!int"), true},
+		{"int and float", scalar("3", "!!int"), scalar("3.0", "!!float"), true},
+		{"hex", scalar("0x1F", "!!int"), scalar("31", "!!int"), true},
+		{"octal", scalar("0o17", "!!int"), scalar("15", "!!int"), true},
+		{"exponent", scalar("1e3", "!!float"), scalar("1000", "!!int"), true},
+		{"big decimals", scalar("0.30000000000000000001", "!!float"), scalar("0.3", "!!float"), false},
+		{"different numbers", scalar("3", "!!int"), scalar("4", "!!int"), false},
+		{"bool case", scalar("True", "!!bool"), scalar("true", "!!bool"), true},
+		{"different bools", scalar("true", "!!bool"), scalar("false", "!!bool"), false},
+		{"null spellings", scalar("~", "!!null"), scalar("null", "!!null"), true},
+		{"timestamps", scalar("2024-01-02T03:04:05Z", "!!timestamp"), scalar("2024-01-02 12:04:05 +09:00", "!!timestamp"), true},
+		{"binary line breaks", scalar("aGVs\nbG8=", "!!binary"), scalar("aGVsbG8=", "!!binary"), true},
+		{"string vs number", scalar("3", "!!str"), scalar("3", "!!int"), false},
+		{"strings compare raw", scalar("3.0", "!!str"), scalar("3", "!!str"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ScalarsEqual(tt.a, tt.b); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestEqual(t *testing.T) {
+	strict := EqualOptions{}
+	comments := EqualOptions{IgnoreComments: true}
+	order := EqualOptions{IgnoreKeyOrder: true}
+	scalars := EqualOptions{NormalizeScalars: true}
+	all := EqualOptions{IgnoreComments: true, IgnoreKeyOrder: true, NormalizeScalars: true}
+
+	tests := []struct {
+		name string
+		a, b string
+		// expected result for strict, comments, order, scalars and all
+		expected [5]bool
+	}{
+		{"identical", "a: 1\nb: [x, y]\n", "a: 1\nb: [x, y]\n", [5]bool{true, true, true, true, true}},
+		{"formatting", "a: 'x'\nb: [1, 2]\n", "a: \"x\"\nb:\n  - 1\n  - 2\n", [5]bool{true, true, true, true, true}},
+		{"head comment", "# top\na: 1\n", "a: 1\n", [5]bool{false, true, false, false, true}},
+		{"line comment", "a: 1 # one\n", "a: 1 # uno\n", [5]bool{false, true, false, false, true}},
+		{"key order", "a: 1\nb: 2\n", "b: 2\na: 1\n", [5]bool{false, false, true, false, true}},
+		{"nested key order", "x:\n  a: 1\n  b: 2\n", "x:\n  b: 2\n  a: 1\n", [5]bool{false, false, true, false, true}},
+		{"sequence order", "[1, 2]", "[2, 1]", [5]bool{false, false, false, false, false}},
+		{"number spelling", "a: 0x10\n", "a: 16\n", [5]bool{false, false, false, true, true}},
+		{"int and float", "a: 1\n", "a: 1.0\n", [5]bool{false, false, false, true, true}},
+		{"bool spelling", "a: True\n", "a: true\n", [5]bool{false, false, false, true, true}},
+		{"null spelling", "a: ~\n", "a:\n", [5]bool{false, false, false, true, true}},
+		{"string and number", "a: '1'\n", "a: 1\n", [5]bool{false, false, false, false, false}},
+		{"different value", "a: 1\n", "a: 2\n", [5]bool{false, false, false, false, false}},
+		{"missing key", "a: 1\nb: 2\n", "a: 1\n", [5]bool{false, false, false, false, false}},
+		{"renamed key", "a: 1\n", "b: 1\n", [5]bool{false, false, false, false, false}},
+		{"kind", "a: [1]\n", "a: {x: 1}\n", [5]bool{false, false, false, false, false}},
+		{"alias", "x: &v {a: 1}\ny: *v\n", "x: {a: 1}\ny: {a: 1}\n", [5]bool{true, true, true, true, true}},
+		{"recursive alias", "a: &x [1, *x]\n", "a: &y [1, *y]\n", [5]bool{true, true, true, true, true}},
+		{"recursive alias differs", "a: &x [1, *x]\n", "a: &y [2, *y]\n", [5]bool{false, false, false, false, false}},
+		{"recursive alias and copy", "a: &x [1, *x]\nb: *x\n", "a: &x [1, *x]\nb: [1, *x]\n", [5]bool{true, true, true, true, true}},
+		{"alias target differs", "x: &v {a: 1}\ny: *v\n", "x: {a: 1}\ny: {a: 2}\n", [5]bool{false, false, false, false, false}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := New().ParseString(tt.a)
+			if err != nil {
+				t.Fatalf("ParseString failed: %v", err)
+			}
+			b, err := New().ParseString(tt.b)
+			if err != nil {
+				t.Fatalf("ParseString failed: %v", err)
+			}
+			for i, opts := range []EqualOptions{strict, comments, order, scalars, all} {
+				if got := Equal(a, b, opts); got != tt.expected[i] {
+					t.Errorf("%+v: expected %v, got %v", opts, tt.expected[i], got)
+				}
+				if got := Equal(b, a, opts); got != tt.expected[i] {
+					t.Errorf("%+v reversed: expected %v, got %v", opts, tt.expected[i], got)
+				}
+			}
+		})
+	}
+}
+
+func TestEqualNil(t *testing.T) {
+	root, err := New().ParseString("a: 1\n")
+	if err != nil {
+		t.Fatalf("ParseString failed: %v", err)
+	}
+	if !Equal(nil, nil, EqualOptions{}) {
+		t.Error("expected two nil nodes to be equal")
+	}
+	if Equal(root, nil, EqualOptions{}) || Equal(nil, root, EqualOptions{}) {
+		t.Error("expected a node and nil to differ")
+	}
+}
@@ -0,0 +1,56 @@
+package parser
+
+import "regexp"
+
+// envPattern matches ${VAR} and $VAR placeholders
+var envPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}|\$([A-Za-z_][A-Za-z0-9_]*)`)
+
+// EnvSegment is a piece of a scalar value after environment expansion.
+// Placeholders whose variable is unset keep their original text and have
+// Unset set.
+type EnvSegment struct {
+	Text  string
+	Unset bool
+}
+
+// ExpandEnv splits value into literal text and substituted ${VAR} or $VAR
+// placeholders, looking variables up with lookup (e.g. os.LookupEnv).
+// It returns nil when value contains no placeholders.
+func ExpandEnv(value string, lookup func(string) (string, bool)) []EnvSegment {
+	matches := envPattern.FindAllStringSubmatchIndex(value, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	var segments []EnvSegment
+	literal := func(s string) {
+		if s == "" {
+			return
+		}
+		// Merge with a preceding literal or resolved piece
+		if n := len(segments); n > 0 && !segments[n-1].Unset {
+			segments[n-1].Text += s
+			return
+		}
+		segments = append(segments, EnvSegment{Text: s})
+	}
+
+	last := 0
+	for _, m := range matches {
+		literal(value[last:m[0]])
+		var name string
+		if m[2] >= 0 {
+			name = value[m[2]:m[3]]
+		} else {
+			name = value[m[4]:m[5]]
+		}
+		if resolved, ok := lookup(name); ok {
+			literal(resolved)
+		} else {
+			segments = append(segments, EnvSegment{Text: value[m[0]:m[1]], Unset: true})
+		}
+		last = m[1]
+	}
+	literal(value[last:])
+	return segments
+}
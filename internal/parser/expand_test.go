@@ -0,0 +1,43 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandEnv(t *testing.T) {
+	env := map[string]string{"HOST": "db.local", "PORT": "5432", "EMPTY": ""}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+
+	tests := []struct {
+		value string
+		want  []EnvSegment
+	}{
+		{"plain", nil},
+		{"cost: 5$", nil},
+		{"${HOST}", []EnvSegment{{Text: "db.local"}}},
+		{"$HOST:$PORT", []EnvSegment{{Text: "db.local:5432"}}},
+		{"x${EMPTY}y", []EnvSegment{{Text: "xy"}}},
+		{"http://${HOST}:${MISSING}/db", []EnvSegment{
+			{Text: "http://db.local:"},
+			{Text: "${MISSING}", Unset: true},
+			{Text: "/db"},
+		}},
+		{"$MISSING$HOST", []EnvSegment{
+			{Text: "$MISSING", Unset: true},
+			{Text: "db.local"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got := ExpandEnv(tt.value, lookup)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExpandEnv(%q) = %+v, want %+v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Explanation describes how yam interprets a node: its source
+// representation, resolved tag, inferred type, and the reason for it
+type Explanation struct {
+	Path     string  `json:"path"`
+	Kind     string  `json:"kind"`
+	Value    *string `json:"value,omitempty"`
+	Tag      string  `json:"tag,omitempty"`
+	Style    string  `json:"style,omitempty"`
+	Type     string  `json:"type,omitempty"`
+	Line     int     `json:"line"`
+	Column   int     `json:"column"`
+	Unquoted *bool   `json:"safe_unquoted,omitempty"`
+	Reason   string  `json:"reason"`
+}
+
+// Explain returns the interpretation of a node
+func Explain(node *YamNode) Explanation {
+	e := Explanation{
+		Path:   node.PathString(),
+		Kind:   node.Kind().String(),
+		Tag:    node.Tag(),
+		Line:   node.Line(),
+		Column: node.Column(),
+	}
+
+	switch node.Kind() {
+	case KindScalar:
+		value := node.Value()
+		safe := !ScalarNeedsQuoting(value, node.Tag())
+		e.Value = &value
+		e.Style = scalarStyleName(node.Raw)
+		e.Type = node.InferType().String()
+		e.Unquoted = &safe
+		e.Reason = scalarReason(node)
+	case KindAlias:
+		value := node.Value()
+		e.Value = &value
+		e.Reason = fmt.Sprintf("alias of anchor &%s", value)
+	default:
+		e.Reason = fmt.Sprintf("%s with %d entries", e.Kind, len(node.Children))
+	}
+
+	return e
+}
+
+// scalarStyleName returns the source style of a scalar
+func scalarStyleName(raw *yaml.Node) string {
+	if raw == nil {
+		return ""
+	}
+	switch {
+	case raw.Style&yaml.DoubleQuotedStyle != 0:
+		return "double-quoted"
+	case raw.Style&yaml.SingleQuotedStyle != 0:
+		return "single-quoted"
+	case raw.Style&yaml.LiteralStyle != 0:
+		return "literal"
+	case raw.Style&yaml.FoldedStyle != 0:
+		return "folded"
+	default:
+		return "plain"
+	}
+}
+
+// scalarReason explains why a scalar has its inferred type
+func scalarReason(node *YamNode) string {
+	raw := node.Raw
+	typ := node.InferType()
+
+	switch {
+	case raw == nil || raw.Tag == "" || raw.Tag == "!":
+		return fmt.Sprintf("no tag; type %s inferred from the value", typ)
+	case raw.Style&yaml.TaggedStyle != 0:
+		return fmt.Sprintf("explicit tag %s in the source", raw.Tag)
+	case raw.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0:
+		return "quoted scalars are always strings"
+	case raw.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0:
+		return "block scalars are always strings"
+	case raw.Tag == "!!str" && typ == TypeBoolean:
+		return "plain scalar read as a boolean under YAML 1.1 (--yaml-version 1.1)"
+	case raw.Tag == "!!str" && resolvesToNonString(raw.Value):
+		return "plain scalar kept as a string by the YAML 1.2 core schema (it would need quoting to stay a string in YAML 1.1)"
+	default:
+		return fmt.Sprintf("plain scalar resolved to %s by the YAML parser", raw.Tag)
+	}
+}
@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	input := `port: 8080
+quoted: "8080"
+flag: yes
+ref: !Ref other
+items: [a, b]`
+
+	root, err := New().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	tests := []struct {
+		path     string
+		tag      string
+		typ      string
+		unquoted bool
+		reason   string
+	}{
+		{".port", "!!int", "int", true, "resolved to !!int"},
+		{".quoted", "!!str", "str", false, "quoted scalars"},
+		{".flag", "!!str", "str", false, "YAML 1.2"},
+		{".ref", "!Ref", "str", true, "explicit tag !Ref"},
+	}
+
+	for _, tt := range tests {
+		node, err := GetByPath(root, tt.path)
+		if err != nil {
+			t.Fatalf("GetByPath(%q) failed: %v", tt.path, err)
+		}
+		e := Explain(node)
+		if e.Tag != tt.tag || e.Type != tt.typ {
+			t.Errorf("%s: expected tag %s type %s, got tag %s type %s", tt.path, tt.tag, tt.typ, e.Tag, e.Type)
+		}
+		if e.Unquoted == nil || *e.Unquoted != tt.unquoted {
+			t.Errorf("%s: expected safe_unquoted %v", tt.path, tt.unquoted)
+		}
+		if !strings.Contains(e.Reason, tt.reason) {
+			t.Errorf("%s: expected reason containing %q, got %q", tt.path, tt.reason, e.Reason)
+		}
+	}
+
+	items, _ := GetByPath(root, ".items")
+	if e := Explain(items); e.Kind != "sequence" || e.Value != nil || e.Line != 5 {
+		t.Errorf("unexpected explanation for sequence: %+v", e)
+	}
+}
@@ -0,0 +1,136 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// FlatLeaves returns the scalar leaves below node, and its empty mappings
+// and sequences, in document order. Aliases are replaced by what they
+// refer to and merge keys are applied (see walkExpanded); only an alias of
+// a node that encloses it is returned as it is. Given types, only scalars
+// of those inferred types are returned.
+func FlatLeaves(node *YamNode, types ...ScalarType) []*YamNode {
+	var leaves []*YamNode
+	walkExpanded(node, func(n *YamNode) {
+		switch n.Kind() {
+		case KindScalar, KindAlias:
+			if len(types) == 0 || hasScalarType(n, types) {
+				leaves = append(leaves, n)
+			}
+		case KindMapping, KindSequence:
+			if len(n.Children) == 0 && len(types) == 0 {
+				leaves = append(leaves, n)
+			}
+		}
+	})
+	return leaves
+}
+
+// hasScalarType reports whether n is a scalar of one of types
+func hasScalarType(n *YamNode, types []ScalarType) bool {
+	if n.Kind() != KindScalar {
+		return false
+	}
+	t := n.InferType()
+	for _, want := range types {
+		if t == want {
+			return true
+		}
+	}
+	return false
+}
+
+// ToFlat converts a YamNode tree to one "path: value" line per leaf, with
+// paths in JSONPath notation from the document root (e.g. $.a.b[0]: x).
+// Values that would break the line format are written as JSON strings, and
+// recursive aliases as *name. Given types, only the leaves of those types are written (see FlatLeaves).
+func ToFlat(node *YamNode, types ...ScalarType) string {
+	var buf strings.Builder
+	for _, leaf := range FlatLeaves(node, types...) {
+		buf.WriteString(leaf.JSONPath())
+		buf.WriteString(": ")
+		buf.WriteString(flatValue(leaf))
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
+
+// flatValue returns the text of a leaf for ToFlat
+func flatValue(leaf *YamNode) string {
+	switch leaf.Kind() {
+	case KindMapping:
+		return "{}"
+	case KindSequence:
+		return "[]"
+	case KindAlias:
+		return "*" + leaf.Value()
+	}
+
+	value := leaf.Value()
+	switch leaf.InferType() {
+	case TypeNull:
+		return "null"
+	case TypeString:
+		if value == "" || strings.ContainsAny(value, "\n\r\t") || strings.TrimSpace(value) != value {
+			quoted, _ := json.Marshal(value)
+			return string(quoted)
+		}
+	}
+	return value
+}
+
+// resolveAlias returns the node an alias refers to, converted in place of
+// the alias so that its path, depth, key and parent are the alias's. Other
+// nodes, and aliases that cannot be converted, are returned as they are.
+func resolveAlias(n *YamNode) *YamNode {
+	if n.Kind() != KindAlias || n.Raw.Alias == nil {
+		return n
+	}
+	p := &Parser{YAMLVersion: n.YAMLVersion}
+	target, err := p.convertNode(n.Raw.Alias, n.Parent, n.Path, n.Depth)
+	if err != nil {
+		return n
+	}
+	target.Key, target.KeyRaw, target.Index = n.Key, n.KeyRaw, n.Index
+	return target
+}
+
+// ToFlatJSON converts a YamNode tree to a JSON object mapping the JSONPath
+// of each leaf to its value, in document order. Given types, only the
+// leaves of those types are included (see FlatLeaves).
+func ToFlatJSON(node *YamNode, indent bool, types ...ScalarType) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for i, leaf := range FlatLeaves(node, types...) {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		key, err := json.Marshal(leaf.JSONPath())
+		if err != nil {
+			return nil, err
+		}
+		var v interface{} = "*" + leaf.Value()
+		if leaf.Kind() != KindAlias {
+			v = nodeToInterface(leaf)
+		}
+		value, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteString(":")
+		buf.Write(value)
+	}
+	buf.WriteString("}")
+
+	if !indent {
+		return buf.Bytes(), nil
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
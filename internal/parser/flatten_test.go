@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+const flattenInput = `a:
+  b:
+    - x
+    - "  padded"
+  empty: {}
+"dotted.key": ~
+port: &p 8080
+alias: *p
+base: &b {x: 1}
+use: *b
+m: {<<: *b, y: 2}
+loop: &l [*l]
+multi: |
+  one
+  two
+`
+
+func TestToFlat(t *testing.T) {
+	root, err := New().ParseString(flattenInput)
+	if err != nil {
+		t.Fatalf("ParseString failed: %v", err)
+	}
+
+	want := `$.a.b[0]: x
+$.a.b[1]: "  padded"
+$.a.empty: {}
+$['dotted.key']: null
+$.port: 8080
+$.alias: 8080
+$.base.x: 1
+$.use.x: 1
+$.m.x: 1
+$.m.y: 2
+$.loop[0]: *l
+$.multi: "one\ntwo\n"
+`
+	if got := ToFlat(root); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+
+	// Paths stay absolute below a path query
+	sub, err := GetByPath(root, ".a.b")
+	if err != nil {
+		t.Fatalf("GetByPath failed: %v", err)
+	}
+	if got := ToFlat(sub); !strings.HasPrefix(got, "$.a.b[0]: x\n") {
+		t.Errorf("expected absolute paths, got:\n%s", got)
+	}
+}
+
+func TestToFlat_Types(t *testing.T) {
+	root, err := New().ParseString(flattenInput)
+	if err != nil {
+		t.Fatalf("ParseString failed: %v", err)
+	}
+
+	tests := []struct {
+		types []ScalarType
+		want  string
+	}{
+		{[]ScalarType{TypeNumber}, "$.port: 8080\n$.alias: 8080\n$.base.x: 1\n$.use.x: 1\n$.m.x: 1\n$.m.y: 2\n"},
+		{[]ScalarType{TypeNull, TypeNumber}, "$['dotted.key']: null\n$.port: 8080\n$.alias: 8080\n$.base.x: 1\n$.use.x: 1\n$.m.x: 1\n$.m.y: 2\n"},
+		{[]ScalarType{TypeBoolean}, ""},
+	}
+	for _, tt := range tests {
+		if got := ToFlat(root, tt.types...); got != tt.want {
+			t.Errorf("ToFlat(%v): expected:\n%s\ngot:\n%s", tt.types, tt.want, got)
+		}
+	}
+}
+
+func TestToFlatJSON(t *testing.T) {
+	root, err := New().ParseString(flattenInput)
+	if err != nil {
+		t.Fatalf("ParseString failed: %v", err)
+	}
+
+	got, err := ToFlatJSON(root, false)
+	if err != nil {
+		t.Fatalf("ToFlatJSON failed: %v", err)
+	}
+	want := `{"$.a.b[0]":"x","$.a.b[1]":"  padded","$.a.empty":{},"$['dotted.key']":null,"$.port":8080,"$.alias":8080,"$.base.x":1,"$.use.x":1,"$.m.x":1,"$.m.y":2,"$.loop[0]":"*l","$.multi":"one\ntwo\n"}`
+	if string(got) != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"bytes"
 	"io"
 	"sort"
 	"strings"
@@ -8,10 +9,34 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// IndentStyle selects the characters used for indentation
+type IndentStyle int
+
+const (
+	IndentSpaces IndentStyle = iota // Indent with spaces (default)
+	IndentTabs                      // Replace each indentation level with a tab
+)
+
 // FormatOptions configures YAML formatting behavior
 type FormatOptions struct {
-	Indent   int  // Indentation width (default: 2)
-	SortKeys bool // Sort mapping keys alphabetically
+	Indent              int             // Indentation width (default: 2)
+	IndentStyle         IndentStyle     // Spaces or tabs
+	SortKeys            bool            // Sort mapping keys alphabetically
+	FlowThreshold       int             // Emit containers in flow style when shorter than this (0 = disabled)
+	PreserveBlankLines  bool            // Keep blank lines that separate mapping entries in the source
+	NormalizeTimestamps bool            // Rewrite plain timestamps to canonical RFC 3339
+	NormalizeBools      bool            // Rewrite booleans to true/false; under 1.2 quote YAML 1.1 boolean words in strings
+	RemoveUnusedAnchors bool            // Drop anchors that no alias refers to
+	NullStyle           NullStyle       // Rewrite nulls in this form (default: as written)
+	Transforms          []TransformFunc // Applied in order before any other rewriting
+	BlockStyle          bool            // Emit flow containers from the source (e.g. JSON input) in block style
+	AlignComments       bool            // Line up the line comments of sibling entries in a column
+	Directives          *Directives     // Directives and document markers to write around the output (nil = none)
+	CompactSequences    bool            // Write the dashes of block sequences under a key at the key's indentation
+	DocumentMarkers     bool            // Always start the document with "---" and end it with "..."
+	WrapWidth           int             // Re-wrap folded (>) block scalars at this column (0 = one line per paragraph)
+	PreserveQuotes      bool            // Keep quoted scalars quoted as written instead of unquoting them when safe
+	YAMLVersion         YAMLVersion     // Schema the document is read with; decides what NormalizeBools treats as booleans
 }
 
 // DefaultFormatOptions returns sensible defaults
@@ -24,18 +49,134 @@ func DefaultFormatOptions() FormatOptions {
 
 // FormatTo formats a yaml.Node and writes to the given writer
 func FormatTo(node *yaml.Node, w io.Writer, opts FormatOptions) error {
+	// Blank lines must be located before sorting changes the entry order
+	var gaps map[*yaml.Node]bool
+	if opts.PreserveBlankLines {
+		gaps = findBlankLineGaps(node)
+	}
+
+	for _, fn := range opts.Transforms {
+		if err := Transform(node, fn); err != nil {
+			return err
+		}
+	}
+
 	// Pre-process: normalize the node
-	normalizeNode(node)
+	normalizeNode(node, opts.PreserveQuotes)
+
+	if opts.NormalizeTimestamps {
+		normalizeTimestamps(node)
+	}
+
+	if opts.NormalizeBools {
+		normalizeBools(node, opts.YAMLVersion)
+	}
+
+	if opts.RemoveUnusedAnchors {
+		removeUnusedAnchors(node)
+	}
 
 	if opts.SortKeys {
 		SortMappingKeys(node)
 	}
 
-	encoder := yaml.NewEncoder(w)
+	if opts.BlockStyle {
+		clearFlowStyle(node)
+	}
+
+	if opts.FlowThreshold > 0 {
+		applyFlowStyle(node, opts.FlowThreshold)
+	}
+
+	// After flow styling, which decides where empty nulls are allowed
+	if opts.NullStyle != NullOriginal {
+		normalizeNulls(node, opts.NullStyle, false)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
 	encoder.SetIndent(opts.Indent)
-	defer encoder.Close()
+	if err := encoder.Encode(node); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+
+	// Post-process: text-level passes on the encoded output
+	out := buf.Bytes()
+	if opts.CompactSequences {
+		var err error
+		out, err = compactSequences(out)
+		if err != nil {
+			return err
+		}
+	}
 
-	return encoder.Encode(node)
+	if len(gaps) > 0 {
+		var err error
+		out, err = restoreBlankLines(node, out, gaps)
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.AlignComments {
+		var err error
+		out, err = alignComments(out)
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.WrapWidth > 0 {
+		var err error
+		out, err = wrapFoldedScalars(out, opts.WrapWidth)
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.IndentStyle == IndentTabs {
+		var err error
+		out, err = indentWithTabs(out, opts.Indent)
+		if err != nil {
+			return err
+		}
+	}
+
+	directives := opts.Directives
+	if opts.DocumentMarkers {
+		markers := Directives{Start: true, End: true}
+		if directives != nil {
+			markers.Lines = directives.Lines
+		}
+		directives = &markers
+	}
+	out = writeDirectives(out, directives)
+
+	_, err := w.Write(out)
+	return err
+}
+
+// writeDirectives surrounds formatted output with the directives and
+// document markers of the source
+func writeDirectives(out []byte, d *Directives) []byte {
+	if d == nil {
+		return out
+	}
+	var buf bytes.Buffer
+	for _, line := range d.Lines {
+		buf.WriteString(line + "\n")
+	}
+	if d.StartMarker() {
+		buf.WriteString("---\n")
+	}
+	buf.Write(out)
+	if d.End {
+		buf.WriteString("...\n")
+	}
+	return buf.Bytes()
 }
 
 // FormatString formats a yaml.Node and returns as string
@@ -50,7 +191,8 @@ func FormatString(node *yaml.Node, opts FormatOptions) (string, error) {
 // normalizeNode recursively normalizes a yaml.Node
 // - Removes trailing whitespace from values
 // - Normalizes quote style where safe
-func normalizeNode(node *yaml.Node) {
+// With preserveQuotes, quoted values are kept exactly as written.
+func normalizeNode(node *yaml.Node, preserveQuotes bool) {
 	if node == nil {
 		return
 	}
@@ -58,20 +200,24 @@ func normalizeNode(node *yaml.Node) {
 	switch node.Kind {
 	case yaml.DocumentNode:
 		for _, child := range node.Content {
-			normalizeNode(child)
+			normalizeNode(child, preserveQuotes)
 		}
 
 	case yaml.MappingNode:
 		for _, child := range node.Content {
-			normalizeNode(child)
+			normalizeNode(child, preserveQuotes)
 		}
 
 	case yaml.SequenceNode:
 		for _, child := range node.Content {
-			normalizeNode(child)
+			normalizeNode(child, preserveQuotes)
 		}
 
 	case yaml.ScalarNode:
+		if preserveQuotes && node.Style&(yaml.SingleQuotedStyle|yaml.DoubleQuotedStyle) != 0 {
+			break // Trailing spaces inside quotes are part of the value
+		}
+
 		// Remove trailing whitespace from value
 		node.Value = strings.TrimRight(node.Value, " \t")
 
@@ -104,43 +250,56 @@ func normalizeComment(comment string) string {
 
 // canBeUnquoted determines if a value can safely be unquoted
 func canBeUnquoted(value, tag string) bool {
-	if value == "" {
-		return false // Empty string needs quotes
-	}
+	return !ScalarNeedsQuoting(value, tag)
+}
 
-	// If explicitly tagged as string, check for special values
-	if tag == "!!str" {
-		lower := strings.ToLower(value)
-		switch lower {
-		case "true", "false", "yes", "no", "on", "off", "null", "~":
-			return false // These would be parsed as non-string
-		}
+// normalizeBools rewrites boolean scalars to canonical true/false. Plain
+// yes/no/on/off/y/n in any case are booleans under YAML 1.1 and rewritten
+// too; under 1.2 they are strings (e.g. the country code NO) and are
+// double-quoted so that YAML 1.1 parsers keep them as strings.
+func normalizeBools(node *yaml.Node, version YAMLVersion) {
+	if node == nil {
+		return
 	}
 
-	// Check for characters that require quoting
-	for _, r := range value {
-		switch r {
-		case ':', '#', '[', ']', '{', '}', ',', '&', '*', '!', '|', '>', '\'', '"', '%', '@', '`', ' ':
-			return false
-		case '\n', '\r', '\t':
-			return false
+	if node.Kind == yaml.ScalarNode {
+		lower := strings.ToLower(node.Value)
+		switch {
+		case node.Tag == "!!bool":
+			switch lower {
+			case "true", "yes", "on", "y":
+				node.Value = "true"
+			case "false", "no", "off", "n":
+				node.Value = "false"
+			}
+			node.Style = 0
+		case node.Tag == "!!str" && node.Style == 0 && isYAML11Bool(lower):
+			if version != YAML11 {
+				node.Style = yaml.DoubleQuotedStyle
+				break
+			}
+			node.Tag = "!!bool"
+			node.Value = "false"
+			switch lower {
+			case "yes", "on", "y":
+				node.Value = "true"
+			}
 		}
 	}
 
-	// Leading/trailing whitespace requires quotes
-	if value != strings.TrimSpace(value) {
-		return false
+	for _, child := range node.Content {
+		normalizeBools(child, version)
 	}
+}
 
-	// Starts with special characters
-	if len(value) > 0 {
-		first := value[0]
-		if first == '-' || first == '?' || first == ':' || first == ' ' {
-			return false
-		}
+// isYAML11Bool reports whether a lowercased plain scalar is a YAML 1.1
+// boolean that YAML 1.2 reads as a string
+func isYAML11Bool(lower string) bool {
+	switch lower {
+	case "yes", "no", "on", "off", "y", "n":
+		return true
 	}
-
-	return true
+	return false
 }
 
 // SortMappingKeys recursively sorts all mapping keys alphabetically
@@ -201,3 +360,99 @@ func sortMappingContent(mapping *yaml.Node) {
 		mapping.Content[i*2+1] = pair.value
 	}
 }
+
+// applyFlowStyle switches nested containers to flow style when their
+// single-line representation is shorter than threshold.
+// The top-level container of a document is always left in block style.
+func applyFlowStyle(node *yaml.Node, threshold int) {
+	if node == nil {
+		return
+	}
+
+	if node.Kind == yaml.DocumentNode {
+		for _, child := range node.Content {
+			for _, grandchild := range child.Content {
+				applyFlowStyle(grandchild, threshold)
+			}
+		}
+		return
+	}
+
+	if node.Kind != yaml.MappingNode && node.Kind != yaml.SequenceNode {
+		return
+	}
+
+	if width, ok := flowWidth(node); ok && width < threshold {
+		setFlowStyle(node)
+		return
+	}
+
+	for _, child := range node.Content {
+		applyFlowStyle(child, threshold)
+	}
+}
+
+// clearFlowStyle switches all containers to block style
+func clearFlowStyle(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode || node.Kind == yaml.SequenceNode {
+		node.Style &^= yaml.FlowStyle
+	}
+	for _, child := range node.Content {
+		clearFlowStyle(child)
+	}
+}
+
+// setFlowStyle marks a container and all nested containers as flow style
+func setFlowStyle(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode || node.Kind == yaml.SequenceNode {
+		node.Style |= yaml.FlowStyle
+	}
+	for _, child := range node.Content {
+		setFlowStyle(child)
+	}
+}
+
+// flowWidth returns the length of a node rendered on a single line in flow style.
+// The second result is false when the node cannot be represented on one line
+// (multi-line scalars, block scalars, or nodes carrying comments).
+func flowWidth(node *yaml.Node) (int, bool) {
+	if node.HeadComment != "" || node.LineComment != "" || node.FootComment != "" {
+		return 0, false
+	}
+
+	width := 0
+	if node.Anchor != "" {
+		width += len(node.Anchor) + 2 // "&name "
+	}
+
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 || strings.Contains(node.Value, "\n") {
+			return 0, false
+		}
+		width += len(node.Value)
+		if node.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 || !canBeUnquoted(node.Value, node.Tag) {
+			width += 2
+		}
+		return width, true
+
+	case yaml.AliasNode:
+		return width + len(node.Value) + 1, true
+
+	case yaml.MappingNode, yaml.SequenceNode:
+		width += 2 // brackets
+		for i, child := range node.Content {
+			w, ok := flowWidth(child)
+			if !ok {
+				return 0, false
+			}
+			width += w
+			if i > 0 {
+				width += 2 // ": " after keys, ", " between entries
+			}
+		}
+		return width, true
+	}
+
+	return 0, false
+}
@@ -127,19 +127,22 @@ func TestCanBeUnquoted(t *testing.T) {
 		expected bool
 	}{
 		{"simple", "", true},
-		{"with space", "", false},
-		{"with:colon", "", false},
-		{"with#hash", "", false},
-		{"", "", false},                   // empty string
-		{"true", "!!str", false},          // boolean-like
-		{"false", "!!str", false},         // boolean-like
-		{"null", "!!str", false},          // null-like
-		{"yes", "!!str", false},           // boolean-like
-		{"no", "!!str", false},            // boolean-like
-		{"hello world", "", false},        // contains space
-		{"-dash", "", false},              // starts with dash
-		{"normal_value", "", true},        // normal identifier
-		{"123", "", true},                 // numbers are ok
+		{"with space", "", true},   // plain scalars may contain spaces
+		{"with: colon", "", false}, // mapping separator
+		{"with #hash", "", false},  // comment indicator
+		{"with:colon", "", true},   // colon without space is plain
+		{"with#hash", "", true},    // hash without space is plain
+		{"", "", false},            // empty string
+		{"true", "!!str", false},   // boolean-like
+		{"false", "!!str", false},  // boolean-like
+		{"null", "!!str", false},   // null-like
+		{"yes", "!!str", false},    // boolean-like
+		{"no", "!!str", false},     // boolean-like
+		{"hello world", "", true},  // contains space
+		{"-dash", "", true},        // dash followed by non-space
+		{"- item", "", false},      // sequence indicator
+		{"normal_value", "", true}, // normal identifier
+		{"123", "", true},          // numbers are ok
 		{"value\nwith\nnewlines", "", false},
 	}
 
@@ -198,3 +201,471 @@ func TestDefaultFormatOptions(t *testing.T) {
 		t.Errorf("expected default SortKeys false")
 	}
 }
+
+func TestFormatTo_FlowThreshold(t *testing.T) {
+	input := `app:
+  ports:
+    - 80
+    - 443
+  labels:
+    tier: web
+    team: core
+  description:
+    text: this mapping is much too long to fit under the configured threshold`
+
+	node := parseYAML(t, input)
+	opts := FormatOptions{Indent: 2, FlowThreshold: 30}
+
+	result, err := FormatString(node, opts)
+	if err != nil {
+		t.Fatalf("FormatString failed: %v", err)
+	}
+
+	if !strings.Contains(result, "ports: [80, 443]") {
+		t.Errorf("expected short sequence in flow style, got:\n%s", result)
+	}
+	if !strings.Contains(result, "labels: {tier: web, team: core}") {
+		t.Errorf("expected short mapping in flow style, got:\n%s", result)
+	}
+	if !strings.Contains(result, "  description:\n    text:") {
+		t.Errorf("expected long mapping to stay in block style, got:\n%s", result)
+	}
+	if strings.HasPrefix(result, "{") {
+		t.Errorf("expected document root to stay in block style, got:\n%s", result)
+	}
+}
+
+func TestFormatTo_FlowThresholdSkipsComments(t *testing.T) {
+	input := `app:
+  ports:
+    - 80 # http
+    - 443`
+
+	node := parseYAML(t, input)
+	opts := FormatOptions{Indent: 2, FlowThreshold: 80}
+
+	result, err := FormatString(node, opts)
+	if err != nil {
+		t.Fatalf("FormatString failed: %v", err)
+	}
+
+	if !strings.Contains(result, "# http") || strings.Contains(result, "[80") {
+		t.Errorf("expected commented sequence to stay in block style, got:\n%s", result)
+	}
+}
+
+func TestFormatTo_PreserveBlankLines(t *testing.T) {
+	input := `app:
+  name: test
+
+  # Nested section
+  nested: true
+script: |
+  line1
+  line2
+
+database:
+  host: localhost
+  port: 5432
+`
+
+	node := parseYAML(t, input)
+	opts := FormatOptions{Indent: 2, PreserveBlankLines: true}
+
+	result, err := FormatString(node, opts)
+	if err != nil {
+		t.Fatalf("FormatString failed: %v", err)
+	}
+
+	if !strings.Contains(result, "name: test\n\n  # Nested section\n  nested: true") {
+		t.Errorf("expected blank line before nested comment, got:\n%s", result)
+	}
+	if !strings.Contains(result, "  line2\n\ndatabase:") {
+		t.Errorf("expected blank line after block scalar, got:\n%s", result)
+	}
+	if strings.Contains(result, "host: localhost\n\n") {
+		t.Errorf("expected no blank line between adjacent keys, got:\n%s", result)
+	}
+}
+
+func TestFormatTo_BlankLinesDroppedByDefault(t *testing.T) {
+	input := "a: 1\n\nb: 2\n"
+
+	node := parseYAML(t, input)
+	result, err := FormatString(node, DefaultFormatOptions())
+	if err != nil {
+		t.Fatalf("FormatString failed: %v", err)
+	}
+
+	if result != "a: 1\nb: 2\n" {
+		t.Errorf("expected blank lines to be removed, got:\n%q", result)
+	}
+}
+
+func TestFormatTo_IndentTabs(t *testing.T) {
+	input := `app:
+  name: test
+  items:
+    - key: a
+      value: b
+  script: |
+    if true; then
+      echo "  spaced  "
+    fi
+`
+
+	node := parseYAML(t, input)
+	opts := FormatOptions{Indent: 2, IndentStyle: IndentTabs}
+
+	result, err := FormatString(node, opts)
+	if err != nil {
+		t.Fatalf("FormatString failed: %v", err)
+	}
+
+	expected := "app:\n" +
+		"\tname: test\n" +
+		"\titems:\n" +
+		"\t\t- key: a\n" +
+		"\t\t\tvalue: b\n" +
+		"\tscript: |\n" +
+		"\t\tif true; then\n" +
+		"\t\t  echo \"  spaced  \"\n" +
+		"\t\tfi\n"
+	if result != expected {
+		t.Errorf("expected:\n%q\ngot:\n%q", expected, result)
+	}
+}
+
+func TestFormatTo_CompactSequences(t *testing.T) {
+	input := `app:
+  # servers
+  hosts:
+    - name: a
+      ports:
+        - 80
+        - 443
+    - script: |
+        - not a sequence
+  tags: [x, y]
+list:
+  - - nested
+`
+
+	tests := []struct {
+		indent   int
+		expected string
+	}{
+		{2, "app:\n" +
+			"  # servers\n" +
+			"  hosts:\n" +
+			"  - name: a\n" +
+			"    ports:\n" +
+			"    - 80\n" +
+			"    - 443\n" +
+			"  - script: |\n" +
+			"      - not a sequence\n" +
+			"  tags: [x, y]\n" +
+			"list:\n" +
+			"- - nested\n"},
+		{4, "app:\n" +
+			"    # servers\n" +
+			"    hosts:\n" +
+			"    - name: a\n" +
+			"      ports:\n" +
+			"      - 80\n" +
+			"      - 443\n" +
+			"    - script: |\n" +
+			"        - not a sequence\n" +
+			"    tags: [x, y]\n" +
+			"list:\n" +
+			"- - nested\n"},
+	}
+
+	for _, tt := range tests {
+		node := parseYAML(t, input)
+		result, err := FormatString(node, FormatOptions{Indent: tt.indent, CompactSequences: true})
+		if err != nil {
+			t.Fatalf("FormatString failed: %v", err)
+		}
+		if result != tt.expected {
+			t.Errorf("indent %d: expected:\n%s\ngot:\n%s", tt.indent, tt.expected, result)
+		}
+		if err := VerifyFormat(parseYAML(t, input), []byte(result), FormatOptions{Indent: tt.indent}); err != nil {
+			t.Errorf("indent %d: output changes the document: %v", tt.indent, err)
+		}
+	}
+}
+
+func TestFormatTo_NormalizeBools(t *testing.T) {
+	input := `a: True
+b: FALSE
+c: !!bool yes
+country: NO
+answer: yes
+quoted: "on"
+name: norway
+`
+
+	tests := []struct {
+		version  YAMLVersion
+		expected string
+	}{
+		// Plain yes/no/on/off are strings, quoted for YAML 1.1 readers
+		{YAML12, `a: true
+b: false
+c: true
+country: "NO"
+answer: "yes"
+quoted: "on"
+name: norway
+`},
+		// Plain yes/no/on/off are booleans, quoted ones stay strings
+		{YAML11, `a: true
+b: false
+c: true
+country: false
+answer: true
+quoted: "on"
+name: norway
+`},
+	}
+
+	for _, tt := range tests {
+		node := parseYAML(t, input)
+		opts := FormatOptions{Indent: 2, NormalizeBools: true, YAMLVersion: tt.version}
+
+		result, err := FormatString(node, opts)
+		if err != nil {
+			t.Fatalf("FormatString failed: %v", err)
+		}
+		if result != tt.expected {
+			t.Errorf("YAML %s: expected:\n%s\ngot:\n%s", tt.version, tt.expected, result)
+		}
+	}
+}
+
+func TestFormatTo_RemoveUnusedAnchors(t *testing.T) {
+	input := `base: &base
+  a: 1
+unused: &u 2
+keep: &k 3
+x:
+  z: *base
+  y: *k
+`
+
+	node := parseYAML(t, input)
+	opts := FormatOptions{Indent: 2, RemoveUnusedAnchors: true}
+
+	result, err := FormatString(node, opts)
+	if err != nil {
+		t.Fatalf("FormatString failed: %v", err)
+	}
+
+	expected := `base: &base
+  a: 1
+unused: 2
+keep: &k 3
+x:
+  z: *base
+  y: *k
+`
+	if result != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result)
+	}
+}
+
+func TestFormatTo_NullStyle(t *testing.T) {
+	input := `a: ~
+b:
+c: Null
+d: [~, x]
+e:
+  - null
+f: !!null ""
+`
+
+	tests := []struct {
+		style    NullStyle
+		expected string
+	}{
+		{NullOriginal, "a: ~\nb:\nc: Null\nd: [~, x]\ne:\n  - null\nf: !!null \"\"\n"},
+		{NullCanonical, "a: null\nb: null\nc: null\nd: [null, x]\ne:\n  - null\nf: !!null \"\"\n"},
+		{NullTilde, "a: ~\nb: ~\nc: ~\nd: [~, x]\ne:\n  - ~\nf: !!null \"\"\n"},
+		// Empty nulls are not allowed in flow collections
+		{NullEmpty, "a:\nb:\nc:\nd: [null, x]\ne:\n  -\nf: !!null \"\"\n"},
+	}
+
+	for _, tt := range tests {
+		node := parseYAML(t, input)
+		result, err := FormatString(node, FormatOptions{Indent: 2, NullStyle: tt.style})
+		if err != nil {
+			t.Fatalf("FormatString failed: %v", err)
+		}
+		if result != tt.expected {
+			t.Errorf("style %d: expected:\n%s\ngot:\n%s", tt.style, tt.expected, result)
+		}
+	}
+}
+
+func TestFormatTo_AlignComments(t *testing.T) {
+	input := `name: app # the name
+replicas: 3     # how many
+image:
+  repo: nginx # repo
+  pullPolicy: IfNotPresent  # policy
+script: | # runs
+  echo hi # not a comment
+ports:
+  - 80 # http
+  - 443 # https
+`
+
+	expected := `name: app   # the name
+replicas: 3 # how many
+image:
+  repo: nginx              # repo
+  pullPolicy: IfNotPresent # policy
+script: |   # runs
+  echo hi # not a comment
+ports:
+  - 80  # http
+  - 443 # https
+`
+
+	node := parseYAML(t, input)
+	result, err := FormatString(node, FormatOptions{Indent: 2, AlignComments: true})
+	if err != nil {
+		t.Fatalf("FormatString failed: %v", err)
+	}
+	if result != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result)
+	}
+}
+
+func TestFormatTo_Transforms(t *testing.T) {
+	input := `Name: "  padded  "
+DB:
+  Host: " h "
+  Port: 5432
+`
+
+	lower, _ := LookupTransform("lowercase-keys")
+	trim, _ := LookupTransform("trim-values")
+
+	node := parseYAML(t, input)
+	opts := FormatOptions{Indent: 2, SortKeys: true, Transforms: []TransformFunc{lower, trim}}
+
+	result, err := FormatString(node, opts)
+	if err != nil {
+		t.Fatalf("FormatString failed: %v", err)
+	}
+
+	expected := `db:
+  host: h
+  port: 5432
+name: padded
+`
+	if result != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result)
+	}
+}
+
+func TestFormatTo_LowercaseKeysCollision(t *testing.T) {
+	lower, _ := LookupTransform("lowercase-keys")
+	node := parseYAML(t, "Name: a\nname: b\n")
+
+	if _, err := FormatString(node, FormatOptions{Indent: 2, Transforms: []TransformFunc{lower}}); err == nil {
+		t.Error("expected an error for keys that collide after lowercasing")
+	}
+}
+
+func TestFormatTo_BlockStyle(t *testing.T) {
+	node := parseYAML(t, `{"name": "app", "tags": ["a", "b"], "db": {"port": 5432}}`)
+
+	result, err := FormatString(node, FormatOptions{Indent: 2, BlockStyle: true})
+	if err != nil {
+		t.Fatalf("FormatString failed: %v", err)
+	}
+
+	expected := `name: app
+tags:
+  - a
+  - b
+db:
+  port: 5432
+`
+	if result != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result)
+	}
+}
+
+func TestFormatTo_WrapFolded(t *testing.T) {
+	input := `docs:
+  - summary: >
+      The quick brown fox jumps over the lazy dog and keeps running.
+
+      Second paragraph with a_very_long_unbreakable_word_that_exceeds
+    notes: |
+      A literal line that is longer than the wrap width stays as is.
+`
+	expected := `docs:
+  - summary: >
+      The quick brown fox jumps over
+      the lazy dog and keeps running.
+
+      Second paragraph with
+      a_very_long_unbreakable_word_that_exceeds
+
+    notes: |
+      A literal line that is longer than the wrap width stays as is.
+`
+
+	node := parseYAML(t, input)
+	opts := DefaultFormatOptions()
+	opts.WrapWidth = 37
+	out, err := FormatString(node, opts)
+	if err != nil {
+		t.Fatalf("FormatString failed: %v", err)
+	}
+	if out != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out)
+	}
+
+	// Re-wrapping folds back to the same value
+	var before, after yaml.Node
+	if err := yaml.Unmarshal([]byte(input), &before); err != nil {
+		t.Fatalf("failed to parse input: %v", err)
+	}
+	if err := yaml.Unmarshal([]byte(out), &after); err != nil {
+		t.Fatalf("failed to parse output: %v", err)
+	}
+	want := before.Content[0].Content[1].Content[0].Content[1].Value
+	if got := after.Content[0].Content[1].Content[0].Content[1].Value; got != want {
+		t.Errorf("expected value %q, got %q", want, got)
+	}
+}
+
+func TestFormatTo_PreserveQuotes(t *testing.T) {
+	input := "name: \"app\"\nowner: 'ops'\nport: \"8080\"\nplain: value   \n"
+
+	tests := []struct {
+		preserve bool
+		expected string
+	}{
+		{false, "name: app\nowner: ops\nport: \"8080\"\nplain: value\n"},
+		{true, "name: \"app\"\nowner: 'ops'\nport: \"8080\"\nplain: value\n"},
+	}
+
+	for _, tt := range tests {
+		opts := DefaultFormatOptions()
+		opts.PreserveQuotes = tt.preserve
+		out, err := FormatString(parseYAML(t, input), opts)
+		if err != nil {
+			t.Fatalf("FormatString failed: %v", err)
+		}
+		if out != tt.expected {
+			t.Errorf("PreserveQuotes=%v: expected:\n%s\ngot:\n%s", tt.preserve, tt.expected, out)
+		}
+	}
+}
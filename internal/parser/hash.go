@@ -0,0 +1,149 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Hash returns a stable SHA-256 content hash of a subtree, as hex.
+// Values and their resolved tags are included; mapping key order,
+// formatting, quoting style, comments and positions are not, so trees
+// parsed from YAML and JSON with the same content hash alike. Aliases are
+// hashed as the content they refer to.
+func Hash(node *YamNode) string {
+	return hashTree(node, false)
+}
+
+// HashWithComments is like Hash but also includes each node's head, line
+// and foot comments, so changing a comment changes the hash
+func HashWithComments(node *YamNode) string {
+	return hashTree(node, true)
+}
+
+func hashTree(node *YamNode, withComments bool) string {
+	h := sha256.New()
+	hashNode(h, node, withComments, nil)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// hashNode writes an unambiguous encoding of node to h: a kind marker
+// followed by length-prefixed fields. within holds the yaml.Nodes of the
+// containers being hashed, outermost first, to detect recursive aliases.
+func hashNode(h hash.Hash, node *YamNode, withComments bool, within []*yaml.Node) {
+	if node == nil {
+		writeHashField(h, "~")
+		return
+	}
+
+	if withComments {
+		writeHashField(h, node.HeadComment())
+		writeHashField(h, node.LineComment())
+		writeHashField(h, node.FootComment())
+	}
+
+	switch node.Kind() {
+	case KindDocument:
+		var child *YamNode
+		if len(node.Children) > 0 {
+			child = node.Children[0]
+		}
+		hashNode(h, child, withComments, within)
+
+	case KindMapping:
+		children := append([]*YamNode(nil), node.Children...)
+		sort.SliceStable(children, func(i, j int) bool { return children[i].Key < children[j].Key })
+		writeHashField(h, "map")
+		writeHashCount(h, len(children))
+		within = append(within, node.Raw)
+		for _, child := range children {
+			writeHashField(h, child.Key)
+			hashNode(h, child, withComments, within)
+		}
+
+	case KindSequence:
+		writeHashField(h, "seq")
+		writeHashCount(h, len(node.Children))
+		within = append(within, node.Raw)
+		for _, child := range node.Children {
+			hashNode(h, child, withComments, within)
+		}
+
+	case KindAlias:
+		hashRaw(h, node.Raw.Alias, within)
+
+	default:
+		writeHashField(h, "scalar")
+		writeHashField(h, node.Tag())
+		writeHashField(h, node.Value())
+	}
+}
+
+// hashRaw hashes the target of an alias with the same encoding as
+// hashNode. Comments are not included since they belong to the anchor. An
+// alias to a container that is being hashed is written as a reference to
+// it by distance, so recursive anchors end and hash alike from anywhere.
+func hashRaw(h hash.Hash, node *yaml.Node, within []*yaml.Node) {
+	if node == nil {
+		writeHashField(h, "~")
+		return
+	}
+	for i := len(within) - 1; i >= 0; i-- {
+		if within[i] == node {
+			writeHashField(h, "ref")
+			writeHashCount(h, len(within)-i)
+			return
+		}
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		type entry struct {
+			key   string
+			value *yaml.Node
+		}
+		entries := make([]entry, 0, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			entries = append(entries, entry{node.Content[i].Value, node.Content[i+1]})
+		}
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+		writeHashField(h, "map")
+		writeHashCount(h, len(entries))
+		within = append(within, node)
+		for _, e := range entries {
+			writeHashField(h, e.key)
+			hashRaw(h, e.value, within)
+		}
+
+	case yaml.SequenceNode:
+		writeHashField(h, "seq")
+		writeHashCount(h, len(node.Content))
+		within = append(within, node)
+		for _, child := range node.Content {
+			hashRaw(h, child, within)
+		}
+
+	case yaml.AliasNode:
+		hashRaw(h, node.Alias, within)
+
+	default:
+		writeHashField(h, "scalar")
+		writeHashField(h, node.Tag)
+		writeHashField(h, node.Value)
+	}
+}
+
+func writeHashField(h hash.Hash, s string) {
+	writeHashCount(h, len(s))
+	h.Write([]byte(s))
+}
+
+func writeHashCount(h hash.Hash, n int) {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(n))
+	h.Write(buf[:])
+}
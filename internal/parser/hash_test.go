@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func hashOf(t *testing.T, input string, withComments bool) string {
+	t.Helper()
+	root, err := New().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if withComments {
+		return HashWithComments(root)
+	}
+	return Hash(root)
+}
+
+func TestHash(t *testing.T) {
+	base := hashOf(t, "a: 1\nb: [x, y]\n", false)
+
+	tests := []struct {
+		name  string
+		input string
+		same  bool
+	}{
+		{"key order", "b: [x, y]\na: 1\n", true},
+		{"formatting", "a: 1\nb:\n  - x\n  - \"y\"\n", true},
+		{"comments", "# head\na: 1 # line\nb: [x, y]\n", true},
+		{"value", "a: 2\nb: [x, y]\n", false},
+		{"tag", "a: \"1\"\nb: [x, y]\n", false},
+		{"sequence order", "a: 1\nb: [y, x]\n", false},
+		{"extra key", "a: 1\nb: [x, y]\nc: null\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := hashOf(t, tt.input, false)
+			if (got == base) != tt.same {
+				t.Errorf("expected same=%v, got hash %s vs %s", tt.same, got, base)
+			}
+		})
+	}
+}
+
+func TestHash_AliasResolved(t *testing.T) {
+	aliased := hashOf(t, "base: &b {x: 1}\nuse: *b\n", false)
+	inline := hashOf(t, "base: {x: 1}\nuse: {x: 1}\n", false)
+	if aliased != inline {
+		t.Errorf("expected alias to hash as its target")
+	}
+}
+
+func TestHash_RecursiveAlias(t *testing.T) {
+	root, err := New().Parse(strings.NewReader("a: &x [1, *x]\nb: *x\nc: &y [2, *y]\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	hash := func(path string) string {
+		node, err := GetByPath(root, path)
+		if err != nil {
+			t.Fatalf("GetByPath failed: %v", err)
+		}
+		return Hash(node)
+	}
+	if hash(".a") != hash(".b") {
+		t.Errorf("expected a recursive anchor and an alias to it to hash alike")
+	}
+	if hash(".a") == hash(".c") {
+		t.Errorf("expected different recursive anchors to hash differently")
+	}
+}
+
+func TestHash_MatchesJSON(t *testing.T) {
+	root, err := New().ParseJSON(strings.NewReader(`{"b": [true, null], "a": 1.5}`))
+	if err != nil {
+		t.Fatalf("ParseJSON failed: %v", err)
+	}
+	if got, want := Hash(root), hashOf(t, "a: 1.5\nb: [true, null]\n", false); got != want {
+		t.Errorf("expected JSON and YAML trees to hash alike")
+	}
+}
+
+func TestHashWithComments(t *testing.T) {
+	plain := hashOf(t, "a: 1\n", true)
+	commented := hashOf(t, "a: 1 # note\n", true)
+	if plain == commented {
+		t.Errorf("expected comment to change the hash")
+	}
+	if got := hashOf(t, "a: 1 # note\n", true); got != commented {
+		t.Errorf("expected hash to be stable")
+	}
+}
@@ -1,10 +1,13 @@
 package parser
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
+	"regexp"
 	"strconv"
+	"strings"
 )
 
 // ToJSON converts a YamNode tree to JSON bytes
@@ -16,6 +19,27 @@ func ToJSON(node *YamNode, indent bool) ([]byte, error) {
 	return json.Marshal(v)
 }
 
+// FormatJSON writes a YamNode tree as pretty-printed JSON followed by a
+// newline. Only the indentation options apply; keys are sorted as in ToJSON.
+func FormatJSON(node *YamNode, w io.Writer, opts FormatOptions) error {
+	data, err := ToJSON(node, false)
+	if err != nil {
+		return err
+	}
+
+	indent := strings.Repeat(" ", opts.Indent)
+	if opts.IndentStyle == IndentTabs {
+		indent = "\t"
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", indent); err != nil {
+		return err
+	}
+	buf.WriteString("\n")
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
 // nodeToInterface converts YamNode to native Go types for JSON marshaling
 func nodeToInterface(node *YamNode) interface{} {
 	if node == nil {
@@ -54,6 +78,9 @@ func nodeToInterface(node *YamNode) interface{} {
 	}
 }
 
+// jsonNumber matches the JSON number grammar (RFC 8259)
+var jsonNumber = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
 // scalarToInterface converts scalar node to appropriate Go type
 func scalarToInterface(node *YamNode) interface{} {
 	value := node.Value()
@@ -63,8 +90,13 @@ func scalarToInterface(node *YamNode) interface{} {
 	case TypeNull:
 		return nil
 	case TypeBoolean:
-		return value == "true" || value == "yes" || value == "on"
+		return parseBool(value)
 	case TypeNumber:
+		// Emit numbers that are already valid JSON verbatim so that large
+		// integers and high-precision decimals survive the round trip
+		if jsonNumber.MatchString(value) {
+			return json.Number(value)
+		}
 		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
 			return i
 		}
@@ -77,6 +109,64 @@ func scalarToInterface(node *YamNode) interface{} {
 	}
 }
 
+// NodeInfo is the JSON representation of a node's metadata, as produced by
+// ToTreeJSON for editor and tooling integration
+type NodeInfo struct {
+	Path     string      `json:"path"`
+	Key      string      `json:"key,omitempty"`
+	Kind     string      `json:"kind"`
+	Value    *string     `json:"value,omitempty"`
+	Type     string      `json:"type,omitempty"`
+	Tag      string      `json:"tag,omitempty"`
+	Line     int         `json:"line"`
+	Column   int         `json:"column"`
+	Depth    int         `json:"depth"`
+	Children []*NodeInfo `json:"children,omitempty"`
+}
+
+// ToTreeJSON converts a YamNode tree to JSON describing each node
+// (path, kind, value, inferred type, position) rather than its value
+func ToTreeJSON(node *YamNode, indent bool) ([]byte, error) {
+	// Skip document wrapper
+	if node.Kind() == KindDocument && len(node.Children) > 0 {
+		node = node.Children[0]
+	}
+
+	info := nodeToInfo(node)
+	if indent {
+		return json.MarshalIndent(info, "", "  ")
+	}
+	return json.Marshal(info)
+}
+
+// nodeToInfo converts a YamNode and its children to NodeInfo
+func nodeToInfo(node *YamNode) *NodeInfo {
+	info := &NodeInfo{
+		Path:   node.PathString(),
+		Key:    node.Key,
+		Kind:   node.Kind().String(),
+		Tag:    node.Tag(),
+		Line:   node.Line(),
+		Column: node.Column(),
+		Depth:  node.Depth,
+	}
+
+	switch node.Kind() {
+	case KindScalar:
+		value := node.Value()
+		info.Value = &value
+		info.Type = node.InferType().String()
+	case KindAlias:
+		value := node.Value()
+		info.Value = &value
+	}
+
+	for _, child := range node.Children {
+		info.Children = append(info.Children, nodeToInfo(child))
+	}
+	return info
+}
+
 // ParseJSON parses JSON from a reader and returns a YamNode tree
 func (p *Parser) ParseJSON(r io.Reader) (*YamNode, error) {
 	var data interface{}
@@ -86,10 +176,13 @@ func (p *Parser) ParseJSON(r io.Reader) (*YamNode, error) {
 		if err == io.EOF {
 			return nil, fmt.Errorf("empty JSON document")
 		}
-		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		return nil, &SyntaxError{Format: "JSON", Err: err}
 	}
 
-	root := interfaceToNode(data, nil, nil, 0)
+	root, err := interfaceToNode(data, nil, nil, 0, p.maxDepth())
+	if err != nil {
+		return nil, err
+	}
 	// Wrap in document node for consistency
 	doc := &YamNode{
 		Depth:    0,
@@ -99,8 +192,13 @@ func (p *Parser) ParseJSON(r io.Reader) (*YamNode, error) {
 	return doc, nil
 }
 
-// interfaceToNode converts native Go types to YamNode
-func interfaceToNode(data interface{}, parent *YamNode, path []string, depth int) *YamNode {
+// interfaceToNode converts native Go types to YamNode, failing with
+// ErrTooDeep below maxDepth
+func interfaceToNode(data interface{}, parent *YamNode, path []string, depth, maxDepth int) (*YamNode, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("%w: JSON is nested more than %d levels deep", ErrTooDeep, maxDepth)
+	}
+
 	node := &YamNode{
 		Parent: parent,
 		Path:   path,
@@ -113,7 +211,10 @@ func interfaceToNode(data interface{}, parent *YamNode, path []string, depth int
 		i := 0
 		for key, val := range v {
 			childPath := append(append([]string{}, path...), key)
-			child := interfaceToNode(val, node, childPath, depth+1)
+			child, err := interfaceToNode(val, node, childPath, depth+1, maxDepth)
+			if err != nil {
+				return nil, err
+			}
 			child.Key = key
 			child.Index = i
 			node.Children = append(node.Children, child)
@@ -124,7 +225,10 @@ func interfaceToNode(data interface{}, parent *YamNode, path []string, depth int
 		node.Raw = makeSequenceRaw()
 		for i, item := range v {
 			childPath := append(append([]string{}, path...), strconv.Itoa(i))
-			child := interfaceToNode(item, node, childPath, depth+1)
+			child, err := interfaceToNode(item, node, childPath, depth+1, maxDepth)
+			if err != nil {
+				return nil, err
+			}
 			child.Index = i
 			node.Children = append(node.Children, child)
 		}
@@ -145,7 +249,7 @@ func interfaceToNode(data interface{}, parent *YamNode, path []string, depth int
 		node.Raw = makeScalarRaw(fmt.Sprintf("%v", v), "")
 	}
 
-	return node
+	return node, nil
 }
 
 func inferNumberTag(s string) string {
@@ -0,0 +1,133 @@
+package parser
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestToTreeJSON(t *testing.T) {
+	root, err := New().ParseString("server:\n  port: 8080\n  hosts: [a]\n")
+	if err != nil {
+		t.Fatalf("ParseString failed: %v", err)
+	}
+
+	data, err := ToTreeJSON(root, false)
+	if err != nil {
+		t.Fatalf("ToTreeJSON failed: %v", err)
+	}
+
+	var info NodeInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+
+	if info.Kind != "mapping" || info.Path != "$" {
+		t.Errorf("expected root mapping at $, got %s at %s", info.Kind, info.Path)
+	}
+
+	port := info.Children[0].Children[0]
+	if port.Path != "$.server.port" || port.Kind != "scalar" || port.Type != "int" {
+		t.Errorf("unexpected port info: %+v", port)
+	}
+	if port.Value == nil || *port.Value != "8080" {
+		t.Errorf("expected port value 8080, got %v", port.Value)
+	}
+	if port.Line != 2 || port.Column != 9 || port.Depth != 2 {
+		t.Errorf("unexpected port position: line %d, column %d, depth %d", port.Line, port.Column, port.Depth)
+	}
+
+	hosts := info.Children[0].Children[1]
+	if hosts.Kind != "sequence" || hosts.Value != nil || len(hosts.Children) != 1 {
+		t.Errorf("unexpected hosts info: %+v", hosts)
+	}
+}
+
+func TestToJSON_NumberPrecision(t *testing.T) {
+	inputs := []string{
+		`[10000000000000000001,-98765432109876543210,0.10000000000000000000000001,1e400,3.0,42]`,
+		`{"big":123456789012345678901234567890}`,
+	}
+
+	for _, input := range inputs {
+		root, err := New().ParseJSON(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("ParseJSON failed: %v", err)
+		}
+		out, err := ToJSON(root, false)
+		if err != nil {
+			t.Fatalf("ToJSON failed: %v", err)
+		}
+		if string(out) != input {
+			t.Errorf("round trip changed numbers:\n  in:  %s\n  out: %s", input, out)
+		}
+	}
+}
+
+func TestToJSON_YAMLNumbers(t *testing.T) {
+	root, err := New().ParseString("big: 18446744073709551617\nratio: 0.3333333333333333333333\n")
+	if err != nil {
+		t.Fatalf("ParseString failed: %v", err)
+	}
+	out, err := ToJSON(root, false)
+	if err != nil {
+		t.Fatalf("ToJSON failed: %v", err)
+	}
+	if string(out) != `{"big":18446744073709551617,"ratio":0.3333333333333333333333}` {
+		t.Errorf("unexpected JSON: %s", out)
+	}
+}
+
+func TestToJSON_YAMLVersion(t *testing.T) {
+	input := "a: no\nb: On\nc: \"yes\"\nd: True\n"
+
+	tests := []struct {
+		version  YAMLVersion
+		expected string
+	}{
+		{YAML12, `{"a":"no","b":"On","c":"yes","d":true}`},
+		{YAML11, `{"a":false,"b":true,"c":"yes","d":true}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version.String(), func(t *testing.T) {
+			p := New()
+			p.YAMLVersion = tt.version
+			root, err := p.ParseString(input)
+			if err != nil {
+				t.Fatalf("ParseString failed: %v", err)
+			}
+			out, err := ToJSON(root, false)
+			if err != nil {
+				t.Fatalf("ToJSON failed: %v", err)
+			}
+			if string(out) != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, out)
+			}
+		})
+	}
+}
+
+func TestFormatJSON(t *testing.T) {
+	root, err := New().Parse(strings.NewReader("b: [1, two]\na:\n  c: null\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	var buf strings.Builder
+	if err := FormatJSON(root, &buf, FormatOptions{Indent: 4}); err != nil {
+		t.Fatalf("FormatJSON failed: %v", err)
+	}
+	expected := "{\n    \"a\": {\n        \"c\": null\n    },\n    \"b\": [\n        1,\n        \"two\"\n    ]\n}\n"
+	if buf.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+
+	buf.Reset()
+	if err := FormatJSON(root, &buf, FormatOptions{Indent: 2, IndentStyle: IndentTabs}); err != nil {
+		t.Fatalf("FormatJSON failed: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "{\n\t\"a\": {\n\t\t\"c\"") {
+		t.Errorf("expected tab indentation, got:\n%s", buf.String())
+	}
+}
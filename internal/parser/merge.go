@@ -0,0 +1,218 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ArrayMergeMode controls how sequences are combined by Merge
+type ArrayMergeMode int
+
+const (
+	ArrayMergeReplace ArrayMergeMode = iota // Overlay sequence replaces base sequence
+	ArrayMergeAppend                        // Overlay items are appended to base sequence
+)
+
+// MergeOptions configures Merge behavior
+type MergeOptions struct {
+	ArrayMerge  ArrayMergeMode
+	Force       bool        // Let overlay win type conflicts instead of reporting them
+	YAMLVersion YAMLVersion // Schema both files are read with; under 1.1 yes and true are both booleans
+}
+
+// MergeConflict describes a value whose type differs between base and overlay
+type MergeConflict struct {
+	Path        string
+	BaseType    string
+	OverlayType string
+}
+
+// MergeError is returned by Merge when type conflicts are found
+type MergeError struct {
+	Conflicts []MergeConflict
+}
+
+func (e *MergeError) Error() string {
+	lines := make([]string, len(e.Conflicts))
+	for i, c := range e.Conflicts {
+		lines[i] = fmt.Sprintf("  %s: %s → %s", c.Path, c.BaseType, c.OverlayType)
+	}
+	return fmt.Sprintf("%d type conflict(s):\n%s", len(e.Conflicts), strings.Join(lines, "\n"))
+}
+
+// Merge deep-merges overlay into base, modifying base in place.
+// Mappings are merged recursively, scalars from overlay win, and sequences
+// are replaced or appended according to opts.ArrayMerge.
+// Unless opts.Force is set, values whose kind or scalar type differ are
+// collected and returned as a *MergeError, and base is left unmodified.
+// Aliases whose anchor does not come before them in the result are
+// replaced with a copy of the content they refer to.
+func Merge(base, overlay *yaml.Node, opts MergeOptions) error {
+	base = unwrapDocument(base)
+	overlay = unwrapDocument(overlay)
+	if base == nil || overlay == nil {
+		return fmt.Errorf("cannot merge empty document")
+	}
+
+	if !opts.Force {
+		var conflicts []MergeConflict
+		findMergeConflicts(base, overlay, "$", opts.YAMLVersion, &conflicts)
+		if len(conflicts) > 0 {
+			return &MergeError{Conflicts: conflicts}
+		}
+	}
+
+	mergeNodes(base, overlay, opts)
+	expandMisplacedAliases(base)
+	return nil
+}
+
+// unwrapDocument returns the content node of a document node
+func unwrapDocument(node *yaml.Node) *yaml.Node {
+	if node != nil && node.Kind == yaml.DocumentNode {
+		if len(node.Content) == 0 {
+			return nil
+		}
+		return node.Content[0]
+	}
+	return node
+}
+
+// findMergeConflicts walks both trees and records kind/type mismatches
+func findMergeConflicts(base, overlay *yaml.Node, path string, version YAMLVersion, conflicts *[]MergeConflict) {
+	baseType, overlayType := mergeTypeName(base, version), mergeTypeName(overlay, version)
+	if baseType != overlayType {
+		// Nulls may be replaced by (or replace) anything
+		if baseType != "null" && overlayType != "null" {
+			*conflicts = append(*conflicts, MergeConflict{Path: path, BaseType: baseType, OverlayType: overlayType})
+		}
+		return
+	}
+
+	if base.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(overlay.Content); i += 2 {
+			key := overlay.Content[i].Value
+			if baseValue := mappingValue(base, key); baseValue != nil {
+				findMergeConflicts(baseValue, overlay.Content[i+1], path+"."+key, version, conflicts)
+			}
+		}
+	}
+}
+
+// mergeTypeName names the kind of a node, or its resolved tag for scalars
+func mergeTypeName(node *yaml.Node, version YAMLVersion) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "mapping"
+	case yaml.SequenceNode:
+		return "sequence"
+	case yaml.AliasNode:
+		return "alias"
+	}
+	// Plain yes/no/on/off are booleans under YAML 1.1
+	if version == YAML11 && node.Style == 0 && node.ShortTag() == "!!str" && isYAML11Bool(strings.ToLower(node.Value)) {
+		return "bool"
+	}
+	return strings.TrimPrefix(node.ShortTag(), "!!")
+}
+
+// mergeNodes merges overlay into base without conflict checks
+func mergeNodes(base, overlay *yaml.Node, opts MergeOptions) {
+	switch {
+	case base.Kind == yaml.MappingNode && overlay.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(overlay.Content); i += 2 {
+			key, value := overlay.Content[i], overlay.Content[i+1]
+			if baseValue := mappingValue(base, key.Value); baseValue != nil {
+				mergeNodes(baseValue, value, opts)
+			} else {
+				base.Content = append(base.Content, key, value)
+			}
+		}
+
+	case base.Kind == yaml.SequenceNode && overlay.Kind == yaml.SequenceNode && opts.ArrayMerge == ArrayMergeAppend:
+		base.Content = append(base.Content, overlay.Content...)
+
+	default:
+		replaceNode(base, overlay)
+	}
+}
+
+// replaceNode overwrites base with overlay, keeping base comments when
+// overlay has none
+func replaceNode(base, overlay *yaml.Node) {
+	head, line, foot := base.HeadComment, base.LineComment, base.FootComment
+	*base = *overlay
+	if base.HeadComment == "" {
+		base.HeadComment = head
+	}
+	if base.LineComment == "" {
+		base.LineComment = line
+	}
+	if base.FootComment == "" {
+		base.FootComment = foot
+	}
+}
+
+// mappingValue returns the value node for key in a mapping node
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// expandMisplacedAliases replaces each alias under node whose anchor is not
+// defined before it with a copy of the content it refers to. Grafting
+// overlay nodes can put an alias in front of its anchor, and replacing a
+// base node can drop or rename the anchor that base aliases refer to.
+func expandMisplacedAliases(node *yaml.Node) {
+	defined := make(map[string]*yaml.Node)
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		if n.Anchor != "" {
+			defined[n.Anchor] = n
+		}
+		for i, child := range n.Content {
+			if child.Kind == yaml.AliasNode && child.Alias != nil {
+				if target := child.Alias; target.Anchor != "" && defined[target.Anchor] == target {
+					child.Value = target.Anchor
+				} else {
+					n.Content[i] = copyAliased(target)
+				}
+			}
+			walk(n.Content[i])
+		}
+	}
+	walk(node)
+}
+
+// copyAliased deep-copies the target of an alias. Aliases inside the copy
+// that refer to nodes within it are pointed at their copies, and only the
+// anchors those aliases need are kept.
+func copyAliased(target *yaml.Node) *yaml.Node {
+	copies := make(map[*yaml.Node]*yaml.Node)
+	var copyNode func(n *yaml.Node) *yaml.Node
+	copyNode = func(n *yaml.Node) *yaml.Node {
+		out := *n
+		out.Anchor = ""
+		copies[n] = &out
+		out.Content = make([]*yaml.Node, len(n.Content))
+		for i, child := range n.Content {
+			out.Content[i] = copyNode(child)
+		}
+		return &out
+	}
+	out := copyNode(target)
+
+	for _, c := range copies {
+		if inner, ok := copies[c.Alias]; ok && c.Kind == yaml.AliasNode {
+			c.Alias = inner
+			inner.Anchor = c.Value
+		}
+	}
+	return out
+}
@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func mergeYAML(t *testing.T, base, overlay string, opts MergeOptions) (string, error) {
+	t.Helper()
+	baseNode := parseYAML(t, base)
+	overlayNode := parseYAML(t, overlay)
+	if err := Merge(baseNode, overlayNode, opts); err != nil {
+		return "", err
+	}
+	result, err := FormatString(baseNode, DefaultFormatOptions())
+	if err != nil {
+		t.Fatalf("FormatString failed: %v", err)
+	}
+	return result, nil
+}
+
+func TestMerge_Mappings(t *testing.T) {
+	base := `app:
+  name: base # keep me
+  port: 80
+db:
+  host: localhost`
+	overlay := `app:
+  port: 8080
+  debug: true
+cache: redis`
+
+	result, err := mergeYAML(t, base, overlay, MergeOptions{})
+	if err != nil {
+		t.Fatalf("Merge failed: %v", err)
+	}
+
+	expected := `app:
+  name: base # keep me
+  port: 8080
+  debug: true
+db:
+  host: localhost
+cache: redis
+`
+	if result != expected {
+		t.Errorf("merge mismatch\ngot:\n%s\nexpected:\n%s", result, expected)
+	}
+}
+
+func TestMerge_ArrayModes(t *testing.T) {
+	base := "items: [a, b]"
+	overlay := "items: [c]"
+
+	tests := []struct {
+		mode     ArrayMergeMode
+		expected string
+	}{
+		{ArrayMergeReplace, "items: [c]\n"},
+		{ArrayMergeAppend, "items: [a, b, c]\n"},
+	}
+
+	for _, tt := range tests {
+		result, err := mergeYAML(t, base, overlay, MergeOptions{ArrayMerge: tt.mode})
+		if err != nil {
+			t.Fatalf("Merge failed: %v", err)
+		}
+		if result != tt.expected {
+			t.Errorf("mode %d: expected %q, got %q", tt.mode, tt.expected, result)
+		}
+	}
+}
+
+func TestMerge_TypeConflicts(t *testing.T) {
+	base := "port: 80\nname: app\nopts: {a: 1}\nunset: null"
+	overlay := "port: \"80\"\nname: other\nopts: [a]\nunset: 5"
+
+	_, err := mergeYAML(t, base, overlay, MergeOptions{})
+	var mergeErr *MergeError
+	if !errors.As(err, &mergeErr) {
+		t.Fatalf("expected MergeError, got %v", err)
+	}
+	if len(mergeErr.Conflicts) != 2 {
+		t.Fatalf("expected 2 conflicts, got %d: %v", len(mergeErr.Conflicts), mergeErr)
+	}
+	if mergeErr.Conflicts[0].Path != "$.port" || mergeErr.Conflicts[1].Path != "$.opts" {
+		t.Errorf("unexpected conflict paths: %v", mergeErr.Conflicts)
+	}
+
+	result, err := mergeYAML(t, base, overlay, MergeOptions{Force: true})
+	if err != nil {
+		t.Fatalf("forced Merge failed: %v", err)
+	}
+	if !strings.Contains(result, `port: "80"`) || !strings.Contains(result, "opts: [a]") {
+		t.Errorf("expected overlay to win when forced, got:\n%s", result)
+	}
+}
+
+func TestMerge_Aliases(t *testing.T) {
+	tests := []struct {
+		name, base, overlay, expected string
+	}{
+		{"alias grafted before its anchor", "a: 1", "x: &X {k: 1}\na: *X", "a: {k: 1}\nx: &X {k: 1}\n"},
+		{"anchor replaced", "a: &A 1\nb: *A", "a: 2", "a: 2\nb: 2\n"},
+		{"anchor kept", "a: 1", "x: &X 2\ny: *X", "a: 1\nx: &X 2\ny: *X\n"},
+		{"recursive anchor", "a: 1", "r: &R\n  self: *R\na: *R", "a: &R\n  self: *R\nr: &R\n  self: *R\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := mergeYAML(t, tt.base, tt.overlay, MergeOptions{Force: true})
+			if err != nil {
+				t.Fatalf("Merge failed: %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+			// Every alias must follow its anchor for the output to parse
+			if _, err := New().ParseString(result); err != nil {
+				t.Errorf("merged output does not parse: %v", err)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"fmt"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -17,9 +18,28 @@ const (
 	KindAlias
 )
 
+// String returns a human-readable name for the kind
+func (k NodeKind) String() string {
+	switch k {
+	case KindDocument:
+		return "document"
+	case KindMapping:
+		return "mapping"
+	case KindSequence:
+		return "sequence"
+	case KindScalar:
+		return "scalar"
+	case KindAlias:
+		return "alias"
+	default:
+		return "unknown"
+	}
+}
+
 // YamNode wraps yaml.Node with additional metadata for rendering and TUI
 type YamNode struct {
 	Raw       *yaml.Node // Original yaml.Node
+	KeyRaw    *yaml.Node // Key yaml.Node (for mapping entries)
 	Parent    *YamNode   // Parent node reference
 	Children  []*YamNode // Child nodes (for Mapping/Sequence)
 	Key       string     // Key name (for mapping entries)
@@ -27,6 +47,9 @@ type YamNode struct {
 	Path      []string   // JSONPath-style path
 	Collapsed bool       // Collapse state for TUI
 	Index     int        // Index in parent (for sequences)
+
+	YAMLVersion YAMLVersion // Schema for inferring scalar types
+	Directives  *Directives // Document node only: directives and markers (nil if none)
 }
 
 // Kind returns the NodeKind for this node
@@ -82,28 +105,51 @@ func (n *YamNode) Column() int {
 	return n.Raw.Column
 }
 
-// HeadComment returns the head comment
+// HeadComment returns the head comment.
+// For mapping entries this includes the comment attached to the key.
 func (n *YamNode) HeadComment() string {
 	if n.Raw == nil {
 		return ""
 	}
-	return n.Raw.HeadComment
+	return joinComments(n.keyComment(func(k *yaml.Node) string { return k.HeadComment }), n.Raw.HeadComment)
 }
 
-// LineComment returns the line comment
+// LineComment returns the line comment.
+// For mapping entries this includes the comment attached to the key.
 func (n *YamNode) LineComment() string {
 	if n.Raw == nil {
 		return ""
 	}
-	return n.Raw.LineComment
+	return joinComments(n.keyComment(func(k *yaml.Node) string { return k.LineComment }), n.Raw.LineComment)
 }
 
-// FootComment returns the foot comment
+// FootComment returns the foot comment.
+// For mapping entries this includes the comment attached to the key.
 func (n *YamNode) FootComment() string {
 	if n.Raw == nil {
 		return ""
 	}
-	return n.Raw.FootComment
+	return joinComments(n.Raw.FootComment, n.keyComment(func(k *yaml.Node) string { return k.FootComment }))
+}
+
+// keyComment returns a comment from the key node, if any
+func (n *YamNode) keyComment(get func(*yaml.Node) string) string {
+	if n.KeyRaw == nil {
+		return ""
+	}
+	return get(n.KeyRaw)
+}
+
+// joinComments joins non-empty comments with newlines
+func joinComments(a, b string) string {
+	switch {
+	case a == "":
+		return b
+	case b == "":
+		return a
+	default:
+		return a + "\n" + b
+	}
 }
 
 // Anchor returns the anchor name if any
@@ -142,8 +188,49 @@ const (
 	TypeBoolean
 	TypeNull
 	TypeTimestamp
+	TypeBinary
 )
 
+// String returns the short type name used in annotations (e.g. "str", "int")
+func (t ScalarType) String() string {
+	switch t {
+	case TypeString:
+		return "str"
+	case TypeNumber:
+		return "int"
+	case TypeBoolean:
+		return "bool"
+	case TypeNull:
+		return "null"
+	case TypeTimestamp:
+		return "time"
+	case TypeBinary:
+		return "binary"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseScalarType parses a type name as accepted by --type: string,
+// number, bool, null, timestamp or binary (or the short names of String)
+func ParseScalarType(name string) (ScalarType, error) {
+	switch name {
+	case "string", "str":
+		return TypeString, nil
+	case "number", "int", "float":
+		return TypeNumber, nil
+	case "bool", "boolean":
+		return TypeBoolean, nil
+	case "null":
+		return TypeNull, nil
+	case "timestamp", "time":
+		return TypeTimestamp, nil
+	case "binary":
+		return TypeBinary, nil
+	}
+	return TypeString, fmt.Errorf("unknown type %q (want string, number, bool, null, timestamp or binary)", name)
+}
+
 // InferType infers the type of a scalar node
 func (n *YamNode) InferType() ScalarType {
 	if n.Raw == nil || n.Kind() != KindScalar {
@@ -162,16 +249,27 @@ func (n *YamNode) InferType() ScalarType {
 		return TypeNumber
 	case "!!timestamp":
 		return TypeTimestamp
+	case "!!binary":
+		return TypeBinary
 	case "!!str":
+		// The YAML 1.2 resolver tags yes/no/on/off as strings; under 1.1
+		// they are booleans unless quoted
+		if n.YAMLVersion == YAML11 && n.Raw.Style == 0 && isYAML11Bool(strings.ToLower(value)) {
+			return TypeBoolean
+		}
 		return TypeString
 	}
 
 	// Auto-detect if tag is not explicit
 	if tag == "" || tag == "!" {
-		switch strings.ToLower(value) {
+		lower := strings.ToLower(value)
+		switch lower {
 		case "null", "~", "":
 			return TypeNull
-		case "true", "false", "yes", "no", "on", "off":
+		case "true", "false":
+			return TypeBoolean
+		}
+		if n.YAMLVersion == YAML11 && isYAML11Bool(lower) {
 			return TypeBoolean
 		}
 
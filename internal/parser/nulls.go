@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NullStyle selects how null scalars are written or displayed
+type NullStyle int
+
+const (
+	NullOriginal  NullStyle = iota // As written in the source (null, Null, ~ or nothing)
+	NullCanonical                  // null
+	NullTilde                      // ~
+	NullEmpty                      // Nothing after the key or dash
+)
+
+// ParseNullStyle parses a null style name: original, canonical, tilde or empty
+func ParseNullStyle(name string) (NullStyle, error) {
+	switch name {
+	case "original":
+		return NullOriginal, nil
+	case "canonical", "null":
+		return NullCanonical, nil
+	case "tilde", "~":
+		return NullTilde, nil
+	case "empty":
+		return NullEmpty, nil
+	}
+	return NullOriginal, fmt.Errorf("unknown null style %q (want original, canonical, tilde or empty)", name)
+}
+
+// Form returns the text of a null scalar written as value in this style
+func (s NullStyle) Form(value string) string {
+	switch s {
+	case NullCanonical:
+		return "null"
+	case NullTilde:
+		return "~"
+	case NullEmpty:
+		return ""
+	}
+	return value
+}
+
+// normalizeNulls rewrites plain null scalars in the given style. An empty
+// null is not valid inside a flow collection, so NullEmpty writes null there.
+func normalizeNulls(node *yaml.Node, style NullStyle, inFlow bool) {
+	if node == nil {
+		return
+	}
+
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" && node.Style&yaml.TaggedStyle == 0 {
+		if style == NullEmpty && inFlow {
+			node.Value = NullCanonical.Form(node.Value)
+		} else {
+			node.Value = style.Form(node.Value)
+		}
+		node.Style = 0
+	}
+
+	inFlow = inFlow || node.Style&yaml.FlowStyle != 0
+	for _, child := range node.Content {
+		normalizeNulls(child, style, inFlow)
+	}
+}
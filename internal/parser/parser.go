@@ -1,16 +1,85 @@
 package parser
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"regexp"
 	"strconv"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
+// DefaultMaxDepth is the deepest nesting accepted by a Parser whose
+// MaxDepth is not set
+const DefaultMaxDepth = 10000
+
+// ErrTooDeep is returned (wrapped) when the input nests mappings and
+// sequences deeper than the parser's MaxDepth
+var ErrTooDeep = errors.New("nesting too deep")
+
 // Parser parses YAML content into YamNode tree
-type Parser struct{}
+type Parser struct {
+	YAMLVersion YAMLVersion // Schema for inferring scalar types (default: 1.2)
+	MaxDepth    int         // Deepest nesting accepted (default: DefaultMaxDepth)
+}
+
+// SyntaxError reports input that is not valid YAML or JSON
+type SyntaxError struct {
+	Format string // "YAML" or "JSON"
+	Err    error
+}
+
+func (e *SyntaxError) Error() string {
+	return "failed to parse " + e.Format + ": " + e.Err.Error()
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// IsSyntaxError reports whether err was caused by malformed input
+func IsSyntaxError(err error) bool {
+	var syntaxErr *SyntaxError
+	return errors.As(err, &syntaxErr)
+}
+
+// yamlTabError matches the decode errors yaml.v3 gives for tab
+// indentation: "cannot start any token" on the tab's line, or "violates
+// indentation" on a line before it
+var yamlTabError = regexp.MustCompile(`^yaml: line (\d+): found (character that cannot start any token|a tab character that violates indentation)$`)
+
+// yamlSyntaxError wraps a decode error of src. When yaml.v3 rejects tab
+// indentation, the line indented with a tab is named instead. Other errors
+// are kept, also when a tab elsewhere is valid, e.g. in a flow collection.
+func yamlSyntaxError(src []byte, err error) *SyntaxError {
+	if m := yamlTabError.FindStringSubmatch(err.Error()); m != nil {
+		reported, _ := strconv.Atoi(m[1])
+		line := tabIndentedLine(src, reported)
+		if line == reported || line > 0 && strings.HasPrefix(m[2], "a tab") {
+			err = fmt.Errorf("tab character used for indentation at line %d; YAML requires spaces", line)
+		}
+	}
+	return &SyntaxError{Format: "YAML", Err: err}
+}
+
+// tabIndentedLine returns the number of the first line of src from line
+// from on whose indentation contains a tab, or 0 when there is none
+func tabIndentedLine(src []byte, from int) int {
+	for i, line := range bytes.Split(src, []byte("\n")) {
+		if i+1 < from {
+			continue
+		}
+		content := bytes.TrimLeft(line, " \t")
+		if len(bytes.TrimSpace(content)) > 0 && bytes.IndexByte(line[:len(line)-len(content)], '\t') >= 0 {
+			return i + 1
+		}
+	}
+	return 0
+}
 
 // New creates a new Parser
 func New() *Parser {
@@ -30,43 +99,101 @@ func (p *Parser) ParseFile(path string) (*YamNode, error) {
 
 // Parse parses YAML from a reader and returns the root YamNode
 func (p *Parser) Parse(r io.Reader) (*YamNode, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+	directives, src := scanDirectives(src)
+
 	var node yaml.Node
-	decoder := yaml.NewDecoder(r)
+	decoder := yaml.NewDecoder(bytes.NewReader(src))
 	if err := decoder.Decode(&node); err != nil {
 		if err == io.EOF {
 			return nil, fmt.Errorf("empty YAML document")
 		}
-		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+		return nil, yamlSyntaxError(src, err)
 	}
 
-	root := p.convertNode(&node, nil, nil, 0)
+	root, err := p.convertNode(&node, nil, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	root.Directives = directives
 	return root, nil
 }
 
+// ParseAll parses every document in a YAML stream separated by "---".
+// Directives are recorded on the first document only.
+func (p *Parser) ParseAll(r io.Reader) ([]*YamNode, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+	directives, src := scanDirectives(src)
+
+	var docs []*YamNode
+	decoder := yaml.NewDecoder(bytes.NewReader(src))
+	for {
+		var node yaml.Node
+		if err := decoder.Decode(&node); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, yamlSyntaxError(src, err)
+		}
+		doc, err := p.convertNode(&node, nil, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	if len(docs) == 0 {
+		return nil, fmt.Errorf("empty YAML document")
+	}
+	docs[0].Directives = directives
+	return docs, nil
+}
+
 // ParseString parses a YAML string and returns the root YamNode
 func (p *Parser) ParseString(content string) (*YamNode, error) {
+	directives, src := scanDirectives([]byte(content))
+
 	var node yaml.Node
-	if err := yaml.Unmarshal([]byte(content), &node); err != nil {
-		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	if err := yaml.Unmarshal(src, &node); err != nil {
+		return nil, yamlSyntaxError(src, err)
 	}
 
-	root := p.convertNode(&node, nil, nil, 0)
+	root, err := p.convertNode(&node, nil, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	root.Directives = directives
 	return root, nil
 }
 
-// convertNode converts yaml.Node to YamNode recursively
-func (p *Parser) convertNode(raw *yaml.Node, parent *YamNode, path []string, depth int) *YamNode {
+// convertNode converts yaml.Node to YamNode recursively. It fails with
+// ErrTooDeep below the parser's MaxDepth instead of recursing further.
+func (p *Parser) convertNode(raw *yaml.Node, parent *YamNode, path []string, depth int) (*YamNode, error) {
+	if depth > p.maxDepth() {
+		return nil, fmt.Errorf("%w: line %d is nested more than %d levels deep", ErrTooDeep, raw.Line, p.maxDepth())
+	}
+
 	node := &YamNode{
 		Raw:    raw,
 		Parent: parent,
 		Path:   path,
 		Depth:  depth,
+
+		YAMLVersion: p.YAMLVersion,
 	}
 
 	switch raw.Kind {
 	case yaml.DocumentNode:
 		if len(raw.Content) > 0 {
-			child := p.convertNode(raw.Content[0], node, path, depth)
+			child, err := p.convertNode(raw.Content[0], node, path, depth)
+			if err != nil {
+				return nil, err
+			}
 			node.Children = []*YamNode{child}
 		}
 
@@ -78,8 +205,12 @@ func (p *Parser) convertNode(raw *yaml.Node, parent *YamNode, path []string, dep
 			key := keyNode.Value
 			childPath := append(append([]string{}, path...), key)
 
-			child := p.convertNode(valueNode, node, childPath, depth+1)
+			child, err := p.convertNode(valueNode, node, childPath, depth+1)
+			if err != nil {
+				return nil, err
+			}
 			child.Key = key
+			child.KeyRaw = keyNode
 			child.Index = i / 2
 			node.Children = append(node.Children, child)
 		}
@@ -87,13 +218,24 @@ func (p *Parser) convertNode(raw *yaml.Node, parent *YamNode, path []string, dep
 	case yaml.SequenceNode:
 		for i, item := range raw.Content {
 			childPath := append(append([]string{}, path...), strconv.Itoa(i))
-			child := p.convertNode(item, node, childPath, depth+1)
+			child, err := p.convertNode(item, node, childPath, depth+1)
+			if err != nil {
+				return nil, err
+			}
 			child.Index = i
 			node.Children = append(node.Children, child)
 		}
 	}
 
-	return node
+	return node, nil
+}
+
+// maxDepth returns MaxDepth, or DefaultMaxDepth when it is not set
+func (p *Parser) maxDepth() int {
+	if p.MaxDepth > 0 {
+		return p.MaxDepth
+	}
+	return DefaultMaxDepth
 }
 
 // Walk traverses all nodes in depth-first order
@@ -138,3 +280,23 @@ func FlattenVisible(root *YamNode) []*YamNode {
 	})
 	return nodes
 }
+
+// Leaves returns all scalar and alias nodes in depth-first order
+func Leaves(root *YamNode) []*YamNode {
+	var nodes []*YamNode
+	Walk(root, func(n *YamNode) bool {
+		if kind := n.Kind(); kind == KindScalar || kind == KindAlias {
+			nodes = append(nodes, n)
+		}
+		return true
+	})
+	return nodes
+}
+
+// RelativePath returns the path segments of node below root
+func RelativePath(root, node *YamNode) []string {
+	if len(node.Path) < len(root.Path) {
+		return node.Path
+	}
+	return node.Path[len(root.Path):]
+}
@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParse_TabIndentation(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"nested key", "a:\n\tb: 1\n", "tab character used for indentation at line 2; YAML requires spaces"},
+		{"after spaces", "a:\n  b: 1\n  c:\n  \td: 2\n", "tab character used for indentation at line 4; YAML requires spaces"},
+		{"sequence", "items:\n  - a\n\t- b\n", "tab character used for indentation at line 3; YAML requires spaces"},
+		{"tab-indented JSON", "{\n\t\"a\": 1\n}\n", ""},
+		{"tab inside a value", "a: \"x\ty\"\n", ""},
+		{"token error on another line", "a: {\n\tb: 1}\nc: @x\n", "line 3: found character that cannot start any token"},
+		{"other error with a valid tab", "a: {\n\tb: 1}\nc: d: e\n", "line 3: mapping values are not allowed in this context"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New().Parse(strings.NewReader(tt.input))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+			if !IsSyntaxError(err) {
+				t.Errorf("expected a syntax error, got %T", err)
+			}
+		})
+	}
+}
+
+func TestParse_MaxDepth(t *testing.T) {
+	p := &Parser{MaxDepth: 3}
+	if _, err := p.Parse(strings.NewReader("a: {b: {c: 1}}\n")); err != nil {
+		t.Fatalf("unexpected error at the maximum depth: %v", err)
+	}
+
+	_, err := p.Parse(strings.NewReader("a:\n  b:\n    c:\n      d: 1\n"))
+	if !errors.Is(err, ErrTooDeep) {
+		t.Fatalf("expected ErrTooDeep, got %v", err)
+	}
+	if want := "line 4"; !strings.Contains(err.Error(), want) {
+		t.Errorf("expected the error to name %s, got %v", want, err)
+	}
+
+	if _, err := p.ParseJSON(strings.NewReader(`{"a": [[[1]]]}`)); !errors.Is(err, ErrTooDeep) {
+		t.Errorf("expected ErrTooDeep for JSON, got %v", err)
+	}
+}
+
+func TestParse_DeepNestingDefault(t *testing.T) {
+	// Deeper than DefaultMaxDepth must fail cleanly, not overflow the stack
+	n := DefaultMaxDepth + 1
+	input := strings.Repeat("[", n) + strings.Repeat("]", n)
+	if _, err := New().Parse(strings.NewReader(input)); err == nil {
+		t.Error("expected an error for YAML nested too deep")
+	}
+	if _, err := New().ParseJSON(strings.NewReader(input)); err == nil {
+		t.Error("expected an error for JSON nested too deep")
+	}
+}
@@ -1,7 +1,10 @@
 package parser
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 )
@@ -71,6 +74,10 @@ func ParsePath(path string) ([]string, error) {
 	return segments, nil
 }
 
+// ErrPathNotFound is returned (wrapped) by GetByPath when the path does not
+// exist in the document
+var ErrPathNotFound = errors.New("path not found")
+
 // GetByPath retrieves a node by path from the root
 func GetByPath(root *YamNode, path string) (*YamNode, error) {
 	segments, err := ParsePath(path)
@@ -107,22 +114,89 @@ func GetByPath(root *YamNode, path string) (*YamNode, error) {
 			// Parse as array index
 			idx, err := strconv.Atoi(segment)
 			if err != nil {
-				return nil, fmt.Errorf("expected array index, got: %s", segment)
+				return nil, fmt.Errorf("%w: expected array index, got: %s", ErrPathNotFound, segment)
 			}
 			if idx < 0 || idx >= len(current.Children) {
-				return nil, fmt.Errorf("array index out of bounds: %d (length: %d)", idx, len(current.Children))
+				return nil, fmt.Errorf("%w: array index out of bounds: %d (length: %d)", ErrPathNotFound, idx, len(current.Children))
 			}
 			current = current.Children[idx]
 			found = true
 
 		default:
-			return nil, fmt.Errorf("cannot traverse into scalar value at: %s", segment)
+			return nil, fmt.Errorf("%w: cannot traverse into scalar value at: %s", ErrPathNotFound, segment)
 		}
 
 		if !found {
-			return nil, fmt.Errorf("path not found: %s", segment)
+			return nil, fmt.Errorf("%w: %s", ErrPathNotFound, segment)
 		}
 	}
 
 	return current, nil
 }
+
+// jqIdentifier matches keys that jq accepts after a bare "."
+var jqIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// JQPath returns the path of a node as a jq expression, e.g. .a.b[0] or
+// .a."dotted.key"
+func (n *YamNode) JQPath() string {
+	var b strings.Builder
+	for _, step := range pathSteps(n) {
+		switch {
+		case step.Parent.Kind() == KindSequence:
+			fmt.Fprintf(&b, "[%d]", step.Index)
+		case jqIdentifier.MatchString(step.Key):
+			b.WriteString("." + step.Key)
+		default:
+			quoted, _ := json.Marshal(step.Key)
+			b.WriteString("." + string(quoted))
+		}
+	}
+	if b.Len() == 0 {
+		return "."
+	}
+	return b.String()
+}
+
+// JSONPath returns the path of a node in JSONPath notation, e.g. $.a.b[0]
+// or $['dotted.key']
+func (n *YamNode) JSONPath() string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, step := range pathSteps(n) {
+		switch {
+		case step.Parent.Kind() == KindSequence:
+			fmt.Fprintf(&b, "[%d]", step.Index)
+		case jqIdentifier.MatchString(step.Key):
+			b.WriteString("." + step.Key)
+		default:
+			b.WriteString("['" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(step.Key) + "']")
+		}
+	}
+	return b.String()
+}
+
+// JSONPointer returns the path of a node as an RFC 6901 JSON Pointer,
+// e.g. /a/b/0 (the empty string for the root)
+func (n *YamNode) JSONPointer() string {
+	var b strings.Builder
+	for _, step := range pathSteps(n) {
+		b.WriteString("/")
+		if step.Parent.Kind() == KindSequence {
+			b.WriteString(strconv.Itoa(step.Index))
+		} else {
+			b.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(step.Key))
+		}
+	}
+	return b.String()
+}
+
+// pathSteps returns the ancestors of n (and n itself) below the root
+// container, outermost first
+func pathSteps(n *YamNode) []*YamNode {
+	var steps []*YamNode
+	for ; n != nil && n.Parent != nil && n.Parent.Kind() != KindDocument; n = n.Parent {
+		steps = append([]*YamNode{n}, steps...)
+	}
+	return steps
+}
@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPathFormats(t *testing.T) {
+	input := `a:
+  b:
+    - x
+    - y
+"dotted.key": 1
+"a/b~c": 2
+`
+
+	root, err := New().Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	tests := []struct {
+		path     string
+		jq       string
+		pointer  string
+		jsonPath string
+	}{
+		{".", ".", "", "$"},
+		{".a", ".a", "/a", "$.a"},
+		{".a.b[1]", ".a.b[1]", "/a/b/1", "$.a.b[1]"},
+	}
+
+	for _, tt := range tests {
+		node, err := GetByPath(root, tt.path)
+		if err != nil {
+			t.Fatalf("GetByPath(%s) failed: %v", tt.path, err)
+		}
+		if got := node.JQPath(); got != tt.jq {
+			t.Errorf("%s: expected jq %q, got %q", tt.path, tt.jq, got)
+		}
+		if got := node.JSONPointer(); got != tt.pointer {
+			t.Errorf("%s: expected pointer %q, got %q", tt.path, tt.pointer, got)
+		}
+		if got := node.JSONPath(); got != tt.jsonPath {
+			t.Errorf("%s: expected JSONPath %q, got %q", tt.path, tt.jsonPath, got)
+		}
+	}
+
+	// Keys that GetByPath cannot address
+	mapping := root.Children[0]
+	dotted, special := mapping.Children[1], mapping.Children[2]
+	if got := dotted.JQPath(); got != `."dotted.key"` {
+		t.Errorf("expected quoted jq key, got %q", got)
+	}
+	if got := special.JQPath(); got != `."a/b~c"` {
+		t.Errorf("expected quoted jq key, got %q", got)
+	}
+	if got := dotted.JSONPath(); got != "$['dotted.key']" {
+		t.Errorf("expected bracketed JSONPath key, got %q", got)
+	}
+	if got := special.JSONPointer(); got != "/a~1b~0c" {
+		t.Errorf("expected escaped pointer, got %q", got)
+	}
+}
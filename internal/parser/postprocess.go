@@ -0,0 +1,414 @@
+package parser
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// findBlankLineGaps records which mapping keys were preceded by a blank line
+// in the original source. yaml.v3 drops blank lines, so they are recovered
+// from the line numbers of adjacent entries.
+func findBlankLineGaps(node *yaml.Node) map[*yaml.Node]bool {
+	gaps := make(map[*yaml.Node]bool)
+	collectBlankLineGaps(node, gaps)
+	return gaps
+}
+
+func collectBlankLineGaps(node *yaml.Node, gaps map[*yaml.Node]bool) {
+	if node == nil {
+		return
+	}
+
+	if node.Kind == yaml.MappingNode && node.Style&yaml.FlowStyle == 0 {
+		for i := 2; i < len(node.Content); i += 2 {
+			key := node.Content[i]
+			prevKey := node.Content[i-2]
+			prevValue := node.Content[i-1]
+
+			start := key.Line - countLines(key.HeadComment)
+			end := lastLine(prevValue)
+			if l := prevKey.Line + countLines(prevKey.FootComment); l > end {
+				end = l
+			}
+			if start-end > 1 {
+				gaps[key] = true
+			}
+		}
+	}
+
+	for _, child := range node.Content {
+		collectBlankLineGaps(child, gaps)
+	}
+}
+
+// lastLine returns the last source line occupied by a node and its descendants
+func lastLine(node *yaml.Node) int {
+	last := node.Line
+	if node.Kind == yaml.ScalarNode {
+		last += strings.Count(strings.TrimRight(node.Value, "\n"), "\n")
+		if node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+			last++ // content starts on the line after the indicator
+		}
+	}
+	for _, child := range node.Content {
+		if l := lastLine(child); l > last {
+			last = l
+		}
+	}
+	return last + countLines(node.FootComment)
+}
+
+// countLines returns the number of lines in a comment
+func countLines(comment string) int {
+	if comment == "" {
+		return 0
+	}
+	return strings.Count(comment, "\n") + 1
+}
+
+// restoreBlankLines re-inserts blank lines before the keys recorded in gaps.
+// The formatted output is parsed again so that each key can be located in it;
+// formatted is walked in parallel with the source node it was encoded from.
+func restoreBlankLines(node *yaml.Node, formatted []byte, gaps map[*yaml.Node]bool) ([]byte, error) {
+	var reparsed yaml.Node
+	if err := yaml.Unmarshal(formatted, &reparsed); err != nil {
+		return nil, err
+	}
+
+	insertBefore := make(map[int]bool)
+	matchBlankLineGaps(node, &reparsed, gaps, insertBefore)
+	if len(insertBefore) == 0 {
+		return formatted, nil
+	}
+
+	lines := strings.Split(string(formatted), "\n")
+	var buf bytes.Buffer
+	for i, line := range lines {
+		lineNo := i + 1
+		if insertBefore[lineNo] && i > 0 && strings.TrimSpace(lines[i-1]) != "" {
+			buf.WriteString("\n")
+		}
+		buf.WriteString(line)
+		if i < len(lines)-1 {
+			buf.WriteString("\n")
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func matchBlankLineGaps(orig, out *yaml.Node, gaps map[*yaml.Node]bool, insertBefore map[int]bool) {
+	if orig == nil || out == nil || len(orig.Content) != len(out.Content) {
+		return
+	}
+	for i, child := range orig.Content {
+		outChild := out.Content[i]
+		if gaps[child] {
+			insertBefore[outChild.Line-countLines(outChild.HeadComment)] = true
+		}
+		matchBlankLineGaps(child, outChild, gaps, insertBefore)
+	}
+}
+
+// indentWithTabs replaces each leading group of width spaces with a tab.
+// Inside block scalars only the block's own indentation is converted, so
+// spaces that belong to the scalar's content are left untouched.
+func indentWithTabs(formatted []byte, width int) ([]byte, error) {
+	if width <= 0 {
+		return formatted, nil
+	}
+
+	var reparsed yaml.Node
+	if err := yaml.Unmarshal(formatted, &reparsed); err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(string(formatted), "\n")
+
+	// blockIndent maps block scalar content lines (0-based) to the
+	// indentation of their block
+	blockIndent := make(map[int]int)
+	collectBlockScalarLines(&reparsed, lines, blockIndent)
+
+	for i, line := range lines {
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if indent == len(line) {
+			continue // blank or whitespace-only line
+		}
+		if bi, ok := blockIndent[i]; ok && bi < indent {
+			indent = bi
+		}
+		groups := indent / width
+		lines[i] = strings.Repeat("\t", groups) + line[groups*width:]
+	}
+
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+func collectBlockScalarLines(node *yaml.Node, lines []string, blockIndent map[int]int) {
+	if node.Kind == yaml.ScalarNode && node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		first, last := node.Line, lastLine(node)-1 // 0-based content lines
+		if last >= len(lines) {
+			last = len(lines) - 1
+		}
+
+		indent := -1
+		for i := first; i <= last; i++ {
+			trimmed := strings.TrimLeft(lines[i], " ")
+			if trimmed == "" {
+				continue
+			}
+			if n := len(lines[i]) - len(trimmed); indent < 0 || n < indent {
+				indent = n
+			}
+		}
+		for i := first; i <= last; i++ {
+			blockIndent[i] = indent
+		}
+	}
+
+	for _, child := range node.Content {
+		collectBlockScalarLines(child, lines, blockIndent)
+	}
+}
+
+// compactSequences moves block sequences that are mapping values left so
+// their dashes line up with the parent key ("key:\n- item") instead of
+// being indented below it as yaml.v3 writes them. Everything in the
+// sequence moves with it, including comments and block scalar content.
+func compactSequences(formatted []byte) ([]byte, error) {
+	var reparsed yaml.Node
+	if err := yaml.Unmarshal(formatted, &reparsed); err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(string(formatted), "\n")
+	shift := make([]int, len(lines))
+	collectSequenceShifts(&reparsed, lines, shift)
+
+	for i, line := range lines {
+		if shift[i] > 0 && strings.TrimSpace(line) != "" {
+			lines[i] = line[shift[i]:]
+		}
+	}
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+// collectSequenceShifts adds the indentation of the dashes relative to the
+// key to the shift of every line below a key whose value is a block
+// sequence, from the line after the key to the end of the sequence. Lines
+// indented no deeper than the key are left alone.
+func collectSequenceShifts(node *yaml.Node, lines []string, shift []int) {
+	if node.Kind == yaml.MappingNode && node.Style&yaml.FlowStyle == 0 {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if value.Kind != yaml.SequenceNode || value.Style&yaml.FlowStyle != 0 || len(value.Content) == 0 {
+				continue
+			}
+			keyIndent := key.Column - 1
+			amount := value.Column - key.Column
+			if amount <= 0 {
+				continue
+			}
+			last := lastLine(value)
+			for l := key.Line; l < last && l < len(lines); l++ { // 0-based lines after the key
+				indent := len(lines[l]) - len(strings.TrimLeft(lines[l], " "))
+				if indent > keyIndent && indent-shift[l] >= amount {
+					shift[l] += amount
+				}
+			}
+		}
+	}
+
+	for _, child := range node.Content {
+		collectSequenceShifts(child, lines, shift)
+	}
+}
+
+// alignComments pads lines so that the line comments of the entries of each
+// block mapping or sequence start in the same column, one space after the
+// longest commented entry. Only the lines the entries start on are touched,
+// so block scalar content is left alone.
+func alignComments(formatted []byte) ([]byte, error) {
+	var reparsed yaml.Node
+	if err := yaml.Unmarshal(formatted, &reparsed); err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(string(formatted), "\n")
+	alignCommentGroups(&reparsed, lines)
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+func alignCommentGroups(node *yaml.Node, lines []string) {
+	if node.Style&yaml.FlowStyle == 0 {
+		// 0-based line index and comment of each commented entry
+		type entry struct {
+			line    int
+			comment string
+		}
+		var group []entry
+		add := func(line int, comments ...string) {
+			for _, c := range comments {
+				if c != "" && !strings.Contains(c, "\n") && line > 0 && line <= len(lines) {
+					group = append(group, entry{line - 1, c})
+					return
+				}
+			}
+		}
+
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key, value := node.Content[i], node.Content[i+1]
+				valueComment := ""
+				if value.Line == key.Line {
+					valueComment = value.LineComment
+				}
+				add(key.Line, key.LineComment, valueComment)
+			}
+		case yaml.SequenceNode:
+			for _, item := range node.Content {
+				// Block mapping items align with their own mapping
+				if item.Kind != yaml.MappingNode || item.Style&yaml.FlowStyle != 0 {
+					add(item.Line, item.LineComment)
+				}
+			}
+		}
+
+		// Split each line into its code and the comment it ends with
+		width := 0
+		codes := make([]string, len(group))
+		for i, e := range group {
+			line := lines[e.line]
+			if !strings.HasSuffix(line, e.comment) {
+				codes[i] = ""
+				continue
+			}
+			codes[i] = strings.TrimRight(strings.TrimSuffix(line, e.comment), " ")
+			if w := len([]rune(codes[i])); w > width {
+				width = w
+			}
+		}
+		if len(group) > 1 {
+			for i, e := range group {
+				if codes[i] == "" {
+					continue
+				}
+				pad := width - len([]rune(codes[i])) + 1
+				lines[e.line] = codes[i] + strings.Repeat(" ", pad) + e.comment
+			}
+		}
+	}
+
+	for _, child := range node.Content {
+		alignCommentGroups(child, lines)
+	}
+}
+
+// wrapFoldedScalars re-wraps the content of folded (>) block scalars so
+// lines end at or before width columns where a space allows it. yaml.v3
+// writes each paragraph of a folded scalar on one line; breaking a line at
+// a single space folds back to that space, so the value is unchanged.
+// Blank lines (paragraph breaks) and more-indented lines, which are not
+// folded, are kept as they are. Literal (|) scalars are left alone.
+func wrapFoldedScalars(formatted []byte, width int) ([]byte, error) {
+	if width <= 0 {
+		return formatted, nil
+	}
+
+	var reparsed yaml.Node
+	if err := yaml.Unmarshal(formatted, &reparsed); err != nil {
+		return nil, err
+	}
+	var starts []int // 0-based first content line of each folded scalar
+	collectFoldedScalars(&reparsed, &starts)
+	sort.Sort(sort.Reverse(sort.IntSlice(starts)))
+
+	// From the last scalar up, so the line numbers of the others stay valid
+	lines := strings.Split(string(formatted), "\n")
+	for _, first := range starts {
+		last, indent := first, -1
+		for ; last < len(lines); last++ {
+			trimmed := strings.TrimLeft(lines[last], " ")
+			if trimmed == "" {
+				continue
+			}
+			n := len(lines[last]) - len(trimmed)
+			if indent < 0 {
+				indent = n
+			}
+			if n < indent {
+				break
+			}
+		}
+
+		var wrapped []string
+		var paragraph []string // consecutive lines that fold into one
+		flush := func() {
+			if len(paragraph) > 0 {
+				text := strings.Join(paragraph, " ")
+				wrapped = append(wrapped, wrapText(text, indent, width)...)
+				paragraph = nil
+			}
+		}
+		for _, line := range lines[first:last] {
+			if strings.TrimSpace(line) == "" || line[indent] == ' ' || line[indent] == '\t' {
+				flush()
+				wrapped = append(wrapped, line)
+				continue
+			}
+			paragraph = append(paragraph, line[indent:])
+		}
+		flush()
+		lines = append(lines[:first], append(wrapped, lines[last:]...)...)
+	}
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+func collectFoldedScalars(node *yaml.Node, starts *[]int) {
+	// Content starting with a space gets an indentation indicator, which
+	// the indentation found by wrapFoldedScalars would not match
+	if node.Kind == yaml.ScalarNode && node.Style&yaml.FoldedStyle != 0 &&
+		!strings.HasPrefix(strings.TrimLeft(node.Value, "\n"), " ") {
+		*starts = append(*starts, node.Line) // the line after the indicator
+	}
+	for _, child := range node.Content {
+		collectFoldedScalars(child, starts)
+	}
+}
+
+// wrapText breaks text into lines indented by indent that end at or before
+// width columns, breaking only at single spaces between words. A word that
+// does not fit is left on a line of its own.
+func wrapText(text string, indent, width int) []string {
+	prefix := strings.Repeat(" ", indent)
+	var lines []string
+	for indent+utf8.RuneCountInString(text) > width {
+		// Last single space that keeps the line within width, or else the
+		// first one at all
+		cut, col := -1, indent
+		for i, r := range text {
+			col++
+			if r != ' ' || i == 0 || text[i-1] == ' ' || i+1 >= len(text) || text[i+1] == ' ' {
+				continue
+			}
+			if col-1 > width && cut >= 0 {
+				break
+			}
+			cut = i
+			if col-1 > width {
+				break
+			}
+		}
+		if cut < 0 {
+			break
+		}
+		lines = append(lines, prefix+text[:cut])
+		text = text[cut+1:]
+	}
+	return append(lines, prefix+text)
+}
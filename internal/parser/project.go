@@ -0,0 +1,156 @@
+package parser
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// selection marks the parts of a tree chosen by Project: either a whole
+// subtree or some of its children
+type selection struct {
+	whole    bool
+	children map[*YamNode]*selection
+}
+
+// Project returns a new document containing only the subtrees at paths,
+// nested under copies of their ancestor mappings and sequences so the
+// structure is kept. Entries keep their source order; selected sequence
+// items are kept in order without the unselected ones, so their indexes
+// may change. Aliases to anchors outside the selection are replaced by the
+// anchored content.
+func Project(root *YamNode, paths []string) (*YamNode, error) {
+	src := root
+	if src.Kind() == KindDocument && len(src.Children) > 0 {
+		src = src.Children[0]
+	}
+
+	sel := &selection{}
+	for _, path := range paths {
+		node, err := GetByPath(src, path)
+		if err != nil {
+			return nil, fmt.Errorf("select %s: %w", path, err)
+		}
+		sel.add(src, node)
+	}
+
+	p := &projector{copies: make(map[*yaml.Node]*yaml.Node), partial: make(map[*yaml.Node]bool)}
+	content := p.build(src, sel)
+	p.resolveAliases(content)
+
+	doc := &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{content}}
+	return (&Parser{YAMLVersion: src.YAMLVersion}).convertNode(doc, nil, nil, 0)
+}
+
+// add selects node, a descendant of (or equal to) src, whose selection is s
+func (s *selection) add(src, node *YamNode) {
+	var chain []*YamNode
+	for n := node; n != src && n != nil; n = n.Parent {
+		chain = append([]*YamNode{n}, chain...)
+	}
+
+	cur := s
+	for _, n := range chain {
+		if cur.whole {
+			return
+		}
+		if cur.children == nil {
+			cur.children = make(map[*YamNode]*selection)
+		}
+		child, ok := cur.children[n]
+		if !ok {
+			child = &selection{}
+			cur.children[n] = child
+		}
+		cur = child
+	}
+	cur.whole = true
+	cur.children = nil
+}
+
+// projector copies selected nodes, remembering the copy of each source
+// node so aliases can be pointed at copied anchors
+type projector struct {
+	copies  map[*yaml.Node]*yaml.Node
+	partial map[*yaml.Node]bool // source nodes copied without some of their children
+}
+
+// build copies node and the selected part of its subtree; a nil or whole
+// selection copies everything
+func (p *projector) build(node *YamNode, sel *selection) *yaml.Node {
+	out := &yaml.Node{}
+	if node.Raw != nil {
+		*out = *node.Raw
+		out.Content = nil
+		p.copies[node.Raw] = out
+	}
+	if sel != nil && sel.whole {
+		sel = nil
+	}
+	if sel != nil && node.Raw != nil {
+		// Aliases to a partial copy are expanded instead, so its anchor
+		// is unused
+		p.partial[node.Raw] = true
+		out.Anchor = ""
+	}
+
+	for _, child := range node.Children {
+		var childSel *selection
+		if sel != nil {
+			var ok bool
+			if childSel, ok = sel.children[child]; !ok {
+				continue
+			}
+		}
+		if node.Kind() == KindMapping {
+			out.Content = append(out.Content, p.copyKey(child))
+		}
+		out.Content = append(out.Content, p.build(child, childSel))
+	}
+	return out
+}
+
+// copyKey returns a copy of the key of a mapping entry. Trees parsed from
+// JSON have no key nodes, so one is made from the key string.
+func (p *projector) copyKey(entry *YamNode) *yaml.Node {
+	if entry.KeyRaw == nil {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: entry.Key}
+	}
+	return p.copyRaw(entry.KeyRaw)
+}
+
+// copyRaw deep-copies a yaml.Node
+func (p *projector) copyRaw(node *yaml.Node) *yaml.Node {
+	out := *node
+	out.Content = make([]*yaml.Node, len(node.Content))
+	p.copies[node] = &out
+	for i, child := range node.Content {
+		out.Content[i] = p.copyRaw(child)
+	}
+	return &out
+}
+
+// resolveAliases points copied aliases at the copies of their anchors,
+// or replaces them with the anchored content when it was not selected in
+// full
+func (p *projector) resolveAliases(node *yaml.Node) {
+	for i, child := range node.Content {
+		if child.Kind == yaml.AliasNode && child.Alias != nil {
+			if target, ok := p.copies[child.Alias]; ok && !p.partial[child.Alias] {
+				child.Alias = target
+			} else {
+				// The expansion has no anchor, so later aliases must not
+				// be pointed at it
+				expanded := p.copyRaw(child.Alias)
+				expanded.Anchor = ""
+				if ok {
+					p.copies[child.Alias] = target
+				} else {
+					delete(p.copies, child.Alias)
+				}
+				node.Content[i] = expanded
+			}
+		}
+		p.resolveAliases(node.Content[i])
+	}
+}
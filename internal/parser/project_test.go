@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProject(t *testing.T) {
+	input := `name: app
+base: &base
+  retries: 3
+base2: &base2
+  a: 1
+  b: 2
+spec:
+  replicas: 2
+  containers:
+    - name: web
+      image: nginx
+    - name: sidecar
+      image: envoy
+  strategy: *base
+  strategy2: *base2
+other: true
+alias: *base
+`
+
+	tests := []struct {
+		name     string
+		paths    []string
+		expected string
+	}{
+		{
+			name:     "structure kept",
+			paths:    []string{".spec.replicas", ".name"},
+			expected: "name: app\nspec:\n    replicas: 2\n",
+		},
+		{
+			name:     "sequence items",
+			paths:    []string{".spec.containers[1].image"},
+			expected: "spec:\n    containers:\n        - image: envoy\n",
+		},
+		{
+			name:     "overlapping paths",
+			paths:    []string{".spec.containers[0].name", ".spec.containers[0]"},
+			expected: "spec:\n    containers:\n        - name: web\n          image: nginx\n",
+		},
+		{
+			name:     "alias outside selection",
+			paths:    []string{".spec.strategy"},
+			expected: "spec:\n    strategy:\n        retries: 3\n",
+		},
+		{
+			name:     "anchor partly selected",
+			paths:    []string{".base2.a", ".spec.strategy2"},
+			expected: "base2:\n    a: 1\nspec:\n    strategy2:\n        a: 1\n        b: 2\n",
+		},
+		{
+			name:     "two aliases outside selection",
+			paths:    []string{".spec.strategy", ".spec.strategy2", ".alias"},
+			expected: "spec:\n    strategy:\n        retries: 3\n    strategy2:\n        a: 1\n        b: 2\nalias:\n    retries: 3\n",
+		},
+		{
+			name:     "alias inside selection",
+			paths:    []string{".base", ".spec.strategy"},
+			expected: "base: &base\n    retries: 3\nspec:\n    strategy: *base\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := New().Parse(strings.NewReader(input))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			projected, err := Project(root, tt.paths)
+			if err != nil {
+				t.Fatalf("Project failed: %v", err)
+			}
+			out, err := FormatString(projected.Raw, FormatOptions{Indent: 4})
+			if err != nil {
+				t.Fatalf("FormatString failed: %v", err)
+			}
+			if out != tt.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", tt.expected, out)
+			}
+		})
+	}
+}
+
+func TestProject_JSON(t *testing.T) {
+	root, err := New().ParseJSON(strings.NewReader(`{"a": {"b": 1, "c": [1, 2]}, "d": null}`))
+	if err != nil {
+		t.Fatalf("ParseJSON failed: %v", err)
+	}
+	projected, err := Project(root, []string{".a.c"})
+	if err != nil {
+		t.Fatalf("Project failed: %v", err)
+	}
+	out, err := ToJSON(projected, false)
+	if err != nil {
+		t.Fatalf("ToJSON failed: %v", err)
+	}
+	if string(out) != `{"a":{"c":[1,2]}}` {
+		t.Errorf("unexpected JSON: %s", out)
+	}
+}
+
+func TestProject_MissingPath(t *testing.T) {
+	root, err := New().Parse(strings.NewReader("a: 1\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if _, err := Project(root, []string{".a", ".b"}); err == nil || !strings.Contains(err.Error(), "select .b") {
+		t.Errorf("expected error naming the path, got %v", err)
+	}
+}
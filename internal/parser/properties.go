@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// ToProperties converts a YamNode tree to Java .properties format.
+// Each scalar leaf becomes one key=value line, where key is the path below
+// node joined by "." (e.g. database.host, items.0). Keys and values are
+// escaped as java.util.Properties.store does, with non-ASCII characters
+// written as \uXXXX. Aliases are exported as the values they refer to and
+// merge keys are applied (see exportLeaves).
+func ToProperties(node *YamNode) string {
+	var buf strings.Builder
+	for _, leaf := range exportLeaves(node) {
+		segments := RelativePath(node, leaf)
+		if len(segments) == 0 {
+			segments = leaf.Path
+		}
+
+		buf.WriteString(escapeProperty(strings.Join(segments, "."), true))
+		buf.WriteString("=")
+		buf.WriteString(escapeProperty(leafValue(leaf), false))
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
+
+// escapeProperty escapes a key or value for a .properties file. Spaces are
+// escaped everywhere in keys but only at the start of values.
+func escapeProperty(s string, isKey bool) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch r {
+		case ' ':
+			if isKey || i == 0 {
+				b.WriteString(`\ `)
+			} else {
+				b.WriteByte(' ')
+			}
+		case '\\':
+			b.WriteString(`\\`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '=', ':', '#', '!':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		default:
+			if r < 0x20 || r > 0x7e {
+				for _, unit := range utf16.Encode([]rune{r}) {
+					fmt.Fprintf(&b, `\u%04X`, unit)
+				}
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	return b.String()
+}
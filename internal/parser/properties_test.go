@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestToProperties(t *testing.T) {
+	input := `database:
+  host: localhost
+  url: "jdbc:postgresql://db:5432/app?a=b"
+items:
+  - one
+  - two
+greeting: " héllo 😀"
+"key with space": "#not a comment!"
+empty: null`
+
+	root, err := New().ParseString(input)
+	if err != nil {
+		t.Fatalf("ParseString failed: %v", err)
+	}
+
+	expected := `database.host=localhost
+database.url=jdbc\:postgresql\://db\:5432/app?a\=b
+items.0=one
+items.1=two
+greeting=\ h\u00E9llo \uD83D\uDE00
+key\ with\ space=\#not a comment\!
+empty=
+`
+	if got := ToProperties(root); got != expected {
+		t.Errorf("ToProperties mismatch\ngot:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestToProperties_Subtree(t *testing.T) {
+	root, err := New().ParseString("database:\n  host: localhost\n")
+	if err != nil {
+		t.Fatalf("ParseString failed: %v", err)
+	}
+	sub, err := GetByPath(root, ".database")
+	if err != nil {
+		t.Fatalf("GetByPath failed: %v", err)
+	}
+
+	if got := ToProperties(sub); got != "host=localhost\n" {
+		t.Errorf("expected path relative to the subtree, got %q", got)
+	}
+}
+
+func TestToProperties_Aliases(t *testing.T) {
+	input := `base: &base
+  host: localhost
+version: &v "1.0"
+copy: *v
+db: *base`
+
+	root, err := New().ParseString(input)
+	if err != nil {
+		t.Fatalf("ParseString failed: %v", err)
+	}
+
+	expected := `base.host=localhost
+version=1.0
+copy=1.0
+db.host=localhost
+`
+	if got := ToProperties(root); got != expected {
+		t.Errorf("ToProperties mismatch\ngot:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestToProperties_MergeKeys(t *testing.T) {
+	root, err := New().ParseString("b: &b {x: 1, y: 0}\nm: {<<: *b, y: 2}\n")
+	if err != nil {
+		t.Fatalf("ParseString failed: %v", err)
+	}
+
+	expected := "b.x=1\nb.y=0\nm.x=1\nm.y=2\n"
+	if got := ToProperties(root); got != expected {
+		t.Errorf("ToProperties mismatch\ngot:\n%s\nexpected:\n%s", got, expected)
+	}
+}
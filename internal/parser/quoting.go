@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// timestampPrefix matches values that YAML resolves as timestamps
+var timestampPrefix = regexp.MustCompile(`^\d{4}-\d{1,2}-\d{1,2}`)
+
+// ScalarNeedsQuoting reports whether a scalar value must be quoted to be
+// read back as the same string. It is shared by the renderer (to decide
+// when to display quotes) and the formatter (to decide when quotes can be
+// dropped), so both always agree.
+//
+// A value needs quoting when it cannot be written as a plain scalar
+// (empty, surrounding whitespace, control characters, leading indicator
+// characters, ": " or " #" sequences) or, for string-tagged values, when
+// the plain form would resolve to a boolean, null, number, or timestamp.
+func ScalarNeedsQuoting(value, tag string) bool {
+	if value == "" {
+		return true
+	}
+
+	// Leading/trailing whitespace and line breaks are lost in plain scalars
+	if value != strings.TrimSpace(value) || strings.ContainsAny(value, "\n\r\t") {
+		return true
+	}
+
+	// Indicator characters cannot start a plain scalar
+	switch value[0] {
+	case ',', '[', ']', '{', '}', '#', '&', '*', '!', '|', '>', '\'', '"', '%', '@', '`':
+		return true
+	case '-', '?', ':':
+		if len(value) == 1 || value[1] == ' ' {
+			return true
+		}
+	}
+
+	// Mapping separators and comments inside the value
+	if strings.Contains(value, ": ") || strings.Contains(value, " #") || strings.HasSuffix(value, ":") {
+		return true
+	}
+
+	// Strings that would be read back as another type
+	if tag == "!!str" && resolvesToNonString(value) {
+		return true
+	}
+
+	return false
+}
+
+// resolvesToNonString reports whether a plain scalar would be interpreted
+// as a boolean (including YAML 1.1 forms), null, number, or timestamp
+func resolvesToNonString(value string) bool {
+	switch strings.ToLower(value) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~":
+		return true
+	case ".inf", "-.inf", "+.inf", ".nan":
+		return true
+	}
+	return isNumber(value) || timestampPrefix.MatchString(value)
+}
+
+// PlainTag returns the tag a value would resolve to when written as a
+// plain scalar: !!int, !!float, !!bool, !!null or !!timestamp, or !!str
+// for anything else (including values that cannot be written plain)
+func PlainTag(value string) string {
+	if value == "" {
+		return "!!null"
+	}
+	if ScalarNeedsQuoting(value, "") {
+		return "!!str"
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(value), &doc); err != nil || len(doc.Content) != 1 {
+		return "!!str"
+	}
+	if node := doc.Content[0]; node.Kind == yaml.ScalarNode && node.Style == 0 {
+		return node.Tag
+	}
+	return "!!str"
+}
@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// quotingCases is shared by the renderer and formatter: both decide quoting
+// through ScalarNeedsQuoting, so one table covers both.
+var quotingCases = []struct {
+	value string
+	tag   string
+	quote bool
+}{
+	{"simple", "!!str", false},
+	{"hello world", "!!str", false},
+	{"user@example.com", "!!str", false},
+	{"nginx:1.25", "!!str", false},
+	{"-dash", "!!str", false},
+	{"", "!!str", true},
+	{" padded", "!!str", true},
+	{"trailing ", "!!str", true},
+	{"line1\nline2", "!!str", true},
+	{"@handle", "!!str", true},
+	{"*star", "!!str", true},
+	{"- item", "!!str", true},
+	{"-", "!!str", true},
+	{"key: value", "!!str", true},
+	{"text #comment", "!!str", true},
+	{"ends:", "!!str", true},
+	{"true", "!!str", true},
+	{"No", "!!str", true},
+	{"~", "!!str", true},
+	{"123", "!!str", true},
+	{"1.5e3", "!!str", true},
+	{".inf", "!!str", true},
+	{"2024-01-15", "!!str", true},
+	{"123", "!!int", false},
+	{"true", "!!bool", false},
+}
+
+func TestScalarNeedsQuoting(t *testing.T) {
+	for _, tt := range quotingCases {
+		if got := ScalarNeedsQuoting(tt.value, tt.tag); got != tt.quote {
+			t.Errorf("ScalarNeedsQuoting(%q, %q) = %v, expected %v", tt.value, tt.tag, got, tt.quote)
+		}
+	}
+}
+
+// TestScalarNeedsQuoting_RoundTrip checks that values reported as safe to
+// leave unquoted are read back by yaml.v3 as the same string
+func TestScalarNeedsQuoting_RoundTrip(t *testing.T) {
+	for _, tt := range quotingCases {
+		if tt.tag != "!!str" || ScalarNeedsQuoting(tt.value, tt.tag) {
+			continue
+		}
+		var out map[string]interface{}
+		if err := yaml.Unmarshal([]byte("k: "+tt.value), &out); err != nil {
+			t.Errorf("unquoted %q failed to parse: %v", tt.value, err)
+			continue
+		}
+		if out["k"] != tt.value {
+			t.Errorf("unquoted %q read back as %#v", tt.value, out["k"])
+		}
+	}
+}
+
+func TestPlainTag(t *testing.T) {
+	tests := []struct {
+		value string
+		tag   string
+	}{
+		{"3", "!!int"},
+		{"0x1F", "!!int"},
+		{"1.5", "!!float"},
+		{"true", "!!bool"},
+		{"yes", "!!str"},
+		{"null", "!!null"},
+		{"~", "!!null"},
+		{"", "!!null"},
+		{"2024-01-02", "!!timestamp"},
+		{"hello", "!!str"},
+		{"a: b", "!!str"},
+		{"[1, 2]", "!!str"},
+	}
+
+	for _, tt := range tests {
+		if got := PlainTag(tt.value); got != tt.tag {
+			t.Errorf("PlainTag(%q) = %s, want %s", tt.value, got, tt.tag)
+		}
+	}
+}
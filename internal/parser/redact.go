@@ -0,0 +1,24 @@
+package parser
+
+import "regexp"
+
+// Redact replaces the value of every scalar under a key (its own or an
+// ancestor's) matching pattern with mask, as a plain string, so that no
+// output made from the tree shows it. The masked scalars get copies of
+// their yaml.Nodes; the nodes they were parsed into, and the source, are
+// left as they are. Aliases only show their anchor name and are kept.
+func Redact(root *YamNode, pattern *regexp.Regexp, mask string) {
+	var walk func(n *YamNode, masked bool)
+	walk = func(n *YamNode, masked bool) {
+		masked = masked || n.Key != "" && pattern.MatchString(n.Key)
+		if masked && n.Kind() == KindScalar {
+			raw := *n.Raw
+			raw.Value, raw.Tag, raw.Style = mask, "!!str", 0
+			n.Raw = &raw
+		}
+		for _, child := range n.Children {
+			walk(child, masked)
+		}
+	}
+	walk(root, false)
+}
@@ -0,0 +1,41 @@
+package parser
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	input := `user: admin
+password: hunter2
+db:
+  api_token: 12345
+  credentials:
+    - secret: s3cr3t
+    - plain
+port: 5432
+`
+	root, err := New().ParseString(input)
+	if err != nil {
+		t.Fatalf("ParseString failed: %v", err)
+	}
+	original, err := GetByPath(root, ".password")
+	if err != nil {
+		t.Fatalf("GetByPath failed: %v", err)
+	}
+	raw := original.Raw
+
+	Redact(root, regexp.MustCompile(`(?i)(password|token|credentials)`), "****")
+
+	out, err := ToJSON(root, false)
+	if err != nil {
+		t.Fatalf("ToJSON failed: %v", err)
+	}
+	expected := `{"db":{"api_token":"****","credentials":[{"secret":"****"},"****"]},"password":"****","port":5432,"user":"admin"}`
+	if string(out) != expected {
+		t.Errorf("expected %s, got %s", expected, out)
+	}
+	if raw.Value != "hunter2" {
+		t.Errorf("expected the parsed yaml.Node to be left alone, got %q", raw.Value)
+	}
+}
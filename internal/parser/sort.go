@@ -0,0 +1,102 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SortSequenceBy sorts the items of a sequence by the scalar at keyPath
+// below each item (e.g. ".name"; "" or "." sorts by the items themselves),
+// reordering both Children and the underlying Raw.Content. The values are
+// compared as numbers when every one of them is a number, and as strings
+// otherwise. The sort is stable, and items without the key keep their
+// order after the others, also when reverse is set.
+func SortSequenceBy(node *YamNode, keyPath string, reverse bool) error {
+	if node.Kind() == KindDocument && len(node.Children) > 0 {
+		node = node.Children[0]
+	}
+	if node.Kind() != KindSequence {
+		return fmt.Errorf("cannot sort %s: not a sequence", node.PathString())
+	}
+	if keyPath != "" && !strings.HasPrefix(keyPath, ".") {
+		keyPath = "." + keyPath
+	}
+
+	// Sort key of each item; nil when the item has no such key
+	keys := make([]*YamNode, len(node.Children))
+	numbers := make([]float64, len(node.Children))
+	numeric := true
+	for i, item := range node.Children {
+		key, err := GetByPath(item, keyPath)
+		if errors.Is(err, ErrPathNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if key.Kind() != KindScalar {
+			return fmt.Errorf("cannot sort by %s: value at %s is a %s", keyPath, key.PathString(), key.Kind())
+		}
+		keys[i] = key
+		if key.InferType() == TypeNumber {
+			numbers[i], err = strconv.ParseFloat(key.Value(), 64)
+		}
+		if key.InferType() != TypeNumber || err != nil {
+			numeric = false
+		}
+	}
+
+	less := func(a, b int) bool {
+		if numeric {
+			return numbers[a] < numbers[b]
+		}
+		return keys[a].Value() < keys[b].Value()
+	}
+
+	order := make([]int, len(node.Children))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		switch {
+		case keys[a] == nil || keys[b] == nil:
+			return keys[a] != nil
+		case reverse:
+			return less(b, a)
+		}
+		return less(a, b)
+	})
+
+	children := make([]*YamNode, len(order))
+	for i, from := range order {
+		children[i] = node.Children[from]
+	}
+	if node.Raw != nil && len(node.Raw.Content) == len(children) {
+		for i, child := range children {
+			node.Raw.Content[i] = child.Raw
+		}
+	}
+	node.Children = children
+	renumberItems(node)
+	return nil
+}
+
+// renumberItems updates the index of the items of a sequence, and the
+// matching segment of the paths below them, after the items moved
+func renumberItems(node *YamNode) {
+	segment := len(node.Path)
+	for i, item := range node.Children {
+		item.Index = i
+		index := strconv.Itoa(i)
+		Walk(item, func(n *YamNode) bool {
+			if segment < len(n.Path) {
+				n.Path[segment] = index
+			}
+			return true
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSortSequenceBy(t *testing.T) {
+	input := `users:
+  - name: carol
+    id: 10
+  - name: alice
+    id: 9
+  - role: guest
+  - name: bob
+    id: 100
+`
+	tests := []struct {
+		keyPath string
+		reverse bool
+		want    string
+	}{
+		{"name", false, "alice,bob,carol,"},
+		{".name", true, "carol,bob,alice,"},
+		{"id", false, "alice,carol,bob,"}, // numeric: 9 < 10 < 100
+	}
+
+	for _, tt := range tests {
+		root, err := New().Parse(strings.NewReader(input))
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		users, err := GetByPath(root, ".users")
+		if err != nil {
+			t.Fatalf("GetByPath failed: %v", err)
+		}
+		if err := SortSequenceBy(users, tt.keyPath, tt.reverse); err != nil {
+			t.Fatalf("SortSequenceBy(%q) failed: %v", tt.keyPath, err)
+		}
+
+		var got strings.Builder
+		for i, item := range users.Children {
+			if item.Index != i || item.Raw != users.Raw.Content[i] {
+				t.Errorf("SortSequenceBy(%q): item %d out of sync with Raw.Content", tt.keyPath, i)
+			}
+			if name, err := GetByPath(item, ".name"); err == nil {
+				got.WriteString(name.Value())
+				if want := fmt.Sprintf("$.users.%d.name", i); name.PathString() != want {
+					t.Errorf("SortSequenceBy(%q): path = %s, expected %s", tt.keyPath, name.PathString(), want)
+				}
+			}
+			got.WriteString(",")
+		}
+		// The item without a name stays last
+		if want := tt.want + ","; got.String() != want {
+			t.Errorf("SortSequenceBy(%q, reverse=%v) = %q, expected %q", tt.keyPath, tt.reverse, got.String(), want)
+		}
+	}
+}
+
+func TestSortSequenceBy_Errors(t *testing.T) {
+	root, err := New().Parse(strings.NewReader("list:\n  - a: {x: 1}\nmap: {a: 1}\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	mapping, _ := GetByPath(root, ".map")
+	if err := SortSequenceBy(mapping, "", false); err == nil {
+		t.Error("expected an error sorting a mapping")
+	}
+	list, _ := GetByPath(root, ".list")
+	if err := SortSequenceBy(list, "a", false); err == nil {
+		t.Error("expected an error sorting by a mapping value")
+	}
+}
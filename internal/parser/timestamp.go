@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Timestamp forms accepted by ParseTimestamp (YAML 1.1 timestamp type):
+//
+//	2001-12-14                      date only
+//	2001-12-14T21:59:43.10Z         'T' or 't' separator
+//	2001-12-14 21:59:43.10          space separator, no zone (UTC)
+//	2001-12-14 21:59:43.10 -5       zone as ±h, ±hh, ±hhmm or ±hh:mm
+var (
+	dateOnlyPattern  = regexp.MustCompile(`^(\d{4})-(\d{1,2})-(\d{1,2})$`)
+	timestampPattern = regexp.MustCompile(
+		`^(\d{4})-(\d{1,2})-(\d{1,2})(?:[Tt]|[ \t]+)(\d{1,2}):(\d{2}):(\d{2})(\.\d+)?[ \t]*(Z|z|[+-]\d{1,2}(?::?\d{2})?)?$`)
+)
+
+// ParseTimestamp parses a YAML timestamp value. dateOnly is true when the
+// value has no time component. Values without a zone are interpreted as UTC.
+func ParseTimestamp(value string) (t time.Time, dateOnly bool, ok bool) {
+	if m := dateOnlyPattern.FindStringSubmatch(value); m != nil {
+		year, month, day := atoi(m[1]), atoi(m[2]), atoi(m[3])
+		t = time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+		if t.Month() != time.Month(month) || t.Day() != day {
+			return time.Time{}, false, false // e.g. 2024-02-30
+		}
+		return t, true, true
+	}
+
+	m := timestampPattern.FindStringSubmatch(value)
+	if m == nil {
+		return time.Time{}, false, false
+	}
+
+	year, month, day := atoi(m[1]), atoi(m[2]), atoi(m[3])
+	hour, minute, second := atoi(m[4]), atoi(m[5]), atoi(m[6])
+	if hour > 23 || minute > 59 || second > 60 {
+		return time.Time{}, false, false
+	}
+
+	nsec := 0
+	if frac := m[7]; frac != "" {
+		digits := (frac[1:] + "000000000")[:9]
+		nsec = atoi(digits)
+	}
+
+	loc := time.UTC
+	if zone := m[8]; zone != "" && zone != "Z" && zone != "z" {
+		loc = parseZoneOffset(zone)
+	}
+
+	t = time.Date(year, time.Month(month), day, hour, minute, second, nsec, loc)
+	if t.Month() != time.Month(month) || t.Day() != day {
+		return time.Time{}, false, false
+	}
+	return t, false, true
+}
+
+// FormatTimestamp formats a timestamp in canonical RFC 3339 form.
+// Date-only values are formatted as an RFC 3339 full-date (2006-01-02).
+func FormatTimestamp(t time.Time, dateOnly bool) string {
+	if dateOnly {
+		return t.Format("2006-01-02")
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// parseZoneOffset converts ±h, ±hh, ±hhmm or ±hh:mm to a fixed zone
+func parseZoneOffset(zone string) *time.Location {
+	sign := 1
+	if zone[0] == '-' {
+		sign = -1
+	}
+	digits := zone[1:]
+
+	var hours, minutes int
+	switch {
+	case len(digits) > 3 && digits[len(digits)-3] == ':':
+		hours, minutes = atoi(digits[:len(digits)-3]), atoi(digits[len(digits)-2:])
+	case len(digits) > 2:
+		hours, minutes = atoi(digits[:len(digits)-2]), atoi(digits[len(digits)-2:])
+	default:
+		hours = atoi(digits)
+	}
+
+	return time.FixedZone("", sign*(hours*3600+minutes*60))
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// normalizeTimestamps rewrites plain timestamp scalars to canonical RFC 3339
+func normalizeTimestamps(node *yaml.Node) {
+	if node == nil {
+		return
+	}
+
+	if node.Kind == yaml.ScalarNode && node.Style == 0 {
+		if node.Tag == "!!timestamp" || node.Tag == "!!str" || node.Tag == "" {
+			if t, dateOnly, ok := ParseTimestamp(node.Value); ok {
+				node.Value = FormatTimestamp(t, dateOnly)
+				node.Tag = "!!timestamp"
+			}
+		}
+	}
+
+	for _, child := range node.Content {
+		normalizeTimestamps(child)
+	}
+}
@@ -0,0 +1,79 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct {
+		value     string
+		canonical string
+		ok        bool
+	}{
+		{"2001-12-14", "2001-12-14", true},
+		{"2001-1-4", "2001-01-04", true},
+		{"2001-12-14T21:59:43.10Z", "2001-12-14T21:59:43.1Z", true},
+		{"2001-12-14t21:59:43Z", "2001-12-14T21:59:43Z", true},
+		{"2001-12-14 21:59:43", "2001-12-14T21:59:43Z", true},
+		{"2001-12-14 21:59:43.10 -5", "2001-12-14T21:59:43.1-05:00", true},
+		{"2001-12-14T21:59:43+09:00", "2001-12-14T21:59:43+09:00", true},
+		{"2001-12-14T21:59:43+0530", "2001-12-14T21:59:43+05:30", true},
+		{"2024-02-30", "", false},
+		{"2001-12-14T25:00:00Z", "", false},
+		{"not a date", "", false},
+		{"20011214", "", false},
+	}
+
+	for _, tt := range tests {
+		ts, dateOnly, ok := ParseTimestamp(tt.value)
+		if ok != tt.ok {
+			t.Errorf("ParseTimestamp(%q) ok = %v, expected %v", tt.value, ok, tt.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if got := FormatTimestamp(ts, dateOnly); got != tt.canonical {
+			t.Errorf("FormatTimestamp(ParseTimestamp(%q)) = %q, expected %q", tt.value, got, tt.canonical)
+		}
+	}
+}
+
+func TestParseTimestamp_Zone(t *testing.T) {
+	ts, _, ok := ParseTimestamp("2001-12-14 21:59:43 -5")
+	if !ok {
+		t.Fatal("expected timestamp to parse")
+	}
+	want := time.Date(2001, 12, 15, 2, 59, 43, 0, time.UTC)
+	if !ts.Equal(want) {
+		t.Errorf("expected %v, got %v", want, ts.UTC())
+	}
+}
+
+func TestFormatTo_NormalizeTimestamps(t *testing.T) {
+	input := `created: 2001-12-14 21:59:43.10 -5
+released: 2024-1-5
+quoted: "2001-12-14 21:59:43"
+name: release`
+
+	node := parseYAML(t, input)
+	opts := FormatOptions{Indent: 2, NormalizeTimestamps: true}
+
+	result, err := FormatString(node, opts)
+	if err != nil {
+		t.Fatalf("FormatString failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"created: 2001-12-14T21:59:43.1-05:00\n",
+		"released: 2024-01-05\n",
+		`quoted: "2001-12-14 21:59:43"`,
+		"name: release\n",
+	} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, result)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TransformFunc rewrites a single node in place. isKey reports whether
+// the node is a mapping key.
+type TransformFunc func(node *yaml.Node, isKey bool) error
+
+// transforms are the named transforms available to fmt --transform
+var transforms = map[string]TransformFunc{
+	"lowercase-keys": lowercaseKeys,
+	"trim-values":    trimValues,
+}
+
+// LookupTransform returns the transform registered under name
+func LookupTransform(name string) (TransformFunc, error) {
+	fn, ok := transforms[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown transform %q (available: %s)", name, strings.Join(TransformNames(), ", "))
+	}
+	return fn, nil
+}
+
+// TransformNames returns the names of the registered transforms, sorted
+func TransformNames() []string {
+	names := make([]string, 0, len(transforms))
+	for name := range transforms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Transform applies fn to node and every node below it, parents first
+func Transform(node *yaml.Node, fn TransformFunc) error {
+	return transformNode(node, fn, false)
+}
+
+func transformNode(node *yaml.Node, fn TransformFunc, isKey bool) error {
+	if node == nil {
+		return nil
+	}
+	if err := fn(node, isKey); err != nil {
+		return err
+	}
+	for i, child := range node.Content {
+		if err := transformNode(child, fn, node.Kind == yaml.MappingNode && i%2 == 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lowercaseKeys lowercases the scalar keys of a mapping. Keys that would
+// collide after lowercasing are reported as an error.
+func lowercaseKeys(node *yaml.Node, isKey bool) error {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	seen := make(map[string]int)
+	for i := 0; i < len(node.Content); i += 2 {
+		key := node.Content[i]
+		if key.Kind != yaml.ScalarNode {
+			continue
+		}
+		lower := strings.ToLower(key.Value)
+		if line, ok := seen[lower]; ok {
+			return fmt.Errorf("lowercase-keys: key %q on line %d collides with the key on line %d", key.Value, key.Line, line)
+		}
+		seen[lower] = key.Line
+		key.Value = lower
+	}
+	return nil
+}
+
+// trimValues removes leading and trailing whitespace from scalar values.
+// Block scalars are left alone since their whitespace is content.
+func trimValues(node *yaml.Node, isKey bool) error {
+	if isKey || node.Kind != yaml.ScalarNode || node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		return nil
+	}
+	node.Value = strings.TrimSpace(node.Value)
+	return nil
+}
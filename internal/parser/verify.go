@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// verifyEqual is what formatting must preserve: values and their resolved
+// tags, but not key order or comments
+var verifyEqual = EqualOptions{IgnoreComments: true, IgnoreKeyOrder: true}
+
+// VerifyFormat re-parses output formatted with opts and checks that it
+// holds the same values as node, which FormatTo has already rewritten
+// according to those options. Both are read with opts.YAMLVersion. Key
+// order, styles and comments may differ; values, their resolved tags and
+// their inferred types may not. The error names the first path that
+// differs.
+func VerifyFormat(node *yaml.Node, formatted []byte, opts FormatOptions) error {
+	if opts.IndentStyle == IndentTabs {
+		// Tab indentation is not YAML; undo it to read the output back
+		formatted = indentWithSpaces(formatted, opts.Indent)
+	}
+
+	p := &Parser{YAMLVersion: opts.YAMLVersion}
+	want, err := p.convertNode(node, nil, nil, 0)
+	if err != nil {
+		return err
+	}
+	got, err := p.ParseString(string(formatted))
+	if err != nil {
+		return fmt.Errorf("formatted output does not parse: %w", err)
+	}
+	if Equal(want, got, verifyEqual) {
+		return nil
+	}
+	return fmt.Errorf("formatted output changes the value at %s", firstDifference(want, got).PathString())
+}
+
+// indentWithSpaces reverses indentWithTabs, replacing each leading tab
+// with width spaces
+func indentWithSpaces(formatted []byte, width int) []byte {
+	lines := strings.Split(string(formatted), "\n")
+	for i, line := range lines {
+		tabs := len(line) - len(strings.TrimLeft(line, "\t"))
+		lines[i] = strings.Repeat(" ", tabs*width) + line[tabs:]
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// firstDifference returns the deepest node of want whose content differs
+// from the node at the same position in got
+func firstDifference(want, got *YamNode) *YamNode {
+	if want.Kind() == KindDocument && len(want.Children) > 0 && len(got.Children) > 0 {
+		return firstDifference(want.Children[0], got.Children[0])
+	}
+	if want.Kind() != got.Kind() || len(want.Children) != len(got.Children) {
+		return want
+	}
+
+	switch want.Kind() {
+	case KindMapping:
+		for _, child := range want.Children {
+			other := childByKey(got, child.Key)
+			if other == nil {
+				return want
+			}
+			if !Equal(child, other, verifyEqual) {
+				return firstDifference(child, other)
+			}
+		}
+	case KindSequence:
+		for i, child := range want.Children {
+			if !Equal(child, got.Children[i], verifyEqual) {
+				return firstDifference(child, got.Children[i])
+			}
+		}
+	}
+	return want
+}
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVerifyFormat(t *testing.T) {
+	input := "b:\n  port: '8080'\n  tags: [x, y]\na: 1\n"
+
+	tests := []struct {
+		name    string
+		opts    func(*FormatOptions)
+		tamper  func(string) string
+		wantErr string
+	}{
+		{"unchanged", nil, nil, ""},
+		{"sorted and tab-indented", func(o *FormatOptions) { o.SortKeys = true; o.IndentStyle = IndentTabs }, nil, ""},
+		{"quotes lost", nil, func(s string) string { return strings.Replace(s, "'8080'", "8080", 1) }, "$.b.port"},
+		{"item dropped", nil, func(s string) string { return strings.Replace(s, "[x, y]", "[x]", 1) }, "$.b.tags"},
+		{"unparseable", nil, func(s string) string { return s + "c: [\n" }, "does not parse"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := parseYAML(t, input)
+			opts := DefaultFormatOptions()
+			if tt.opts != nil {
+				tt.opts(&opts)
+			}
+			out, err := FormatString(root, opts)
+			if err != nil {
+				t.Fatalf("FormatString failed: %v", err)
+			}
+			if tt.tamper != nil {
+				tampered := tt.tamper(out)
+				if tampered == out {
+					t.Fatalf("tamper did not change %q", out)
+				}
+				out = tampered
+			}
+
+			err = VerifyFormat(root, []byte(out), opts)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("expected no error, got %v", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestVerifyFormat_YAMLVersion(t *testing.T) {
+	root := parseYAML(t, "a: 'on'\n")
+
+	// Unquoted, on keeps its !!str tag but is a boolean under YAML 1.1
+	opts := DefaultFormatOptions()
+	if err := VerifyFormat(root, []byte("a: on\n"), opts); err != nil {
+		t.Errorf("expected no error under 1.2, got %v", err)
+	}
+	opts.YAMLVersion = YAML11
+	if err := VerifyFormat(root, []byte("a: on\n"), opts); err == nil || !strings.Contains(err.Error(), "$.a") {
+		t.Errorf("expected an error at $.a under 1.1, got %v", err)
+	}
+}
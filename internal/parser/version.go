@@ -0,0 +1,30 @@
+package parser
+
+import "fmt"
+
+// YAMLVersion selects the schema used to infer the type of plain scalars
+type YAMLVersion int
+
+const (
+	YAML12 YAMLVersion = iota // Core schema: only true/false are booleans (default)
+	YAML11                    // Also yes/no/on/off/y/n in any case
+)
+
+// ParseYAMLVersion parses a YAML version: 1.1 or 1.2
+func ParseYAMLVersion(name string) (YAMLVersion, error) {
+	switch name {
+	case "1.2":
+		return YAML12, nil
+	case "1.1":
+		return YAML11, nil
+	}
+	return YAML12, fmt.Errorf("unsupported YAML version %q (want 1.1 or 1.2)", name)
+}
+
+// String returns the version number
+func (v YAMLVersion) String() string {
+	if v == YAML11 {
+		return "1.1"
+	}
+	return "1.2"
+}
@@ -2,9 +2,15 @@ package renderer
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/simota/yam/internal/parser"
+	"gopkg.in/yaml.v3"
 )
 
 // Options configures rendering behavior
@@ -12,11 +18,28 @@ type Options struct {
 	ShowLineNumbers bool
 	TreeStyle       TreeStyle
 	IndentSize      int
-	MaxWidth        int
-	Interactive     bool // Show fold indicators (▼/▶) for TUI mode
-	ShowTypes       bool // Show type annotations like <str>, <int>
+	MaxWidth        int                         // Truncate lines wider than this with "…" (0 = unlimited)
+	Interactive     bool                        // Show fold indicators (▼/▶) for TUI mode
+	ShowTypes       bool                        // Show type annotations like <str>, <int>
+	ShowComments    bool                        // Show head/foot comments as separate lines (Render only)
+	ShowTags        bool                        // Show resolved tags too, not only explicit ones
+	Timezone        *time.Location              // Display timestamps in this zone (nil = as written)
+	NullStyle       parser.NullStyle            // How null values are displayed
+	Redact          *regexp.Regexp              // Mask scalars under keys matching this pattern (nil = off)
+	ShowBinary      bool                        // Show binary values in full instead of <binary N bytes>
+	Revealed        map[*parser.YamNode]bool    // Binary values shown in full even without ShowBinary
+	ExpandEnv       func(string) (string, bool) // Substitute ${VAR} and $VAR in strings using this lookup (nil = off)
+	ShowDirectives  bool                        // Show %YAML/%TAG directives and ---/... markers (Render only)
+	Compact         bool                        // Show every container below the root folded, with its size
+	MaxValueLength  int                         // Cut scalar values longer than this many characters with "…" (0 = unlimited)
 }
 
+// RedactedValue replaces the values masked by Options.Redact
+const RedactedValue = "****"
+
+// DefaultRedactPattern matches keys that commonly hold secrets
+var DefaultRedactPattern = regexp.MustCompile(`(?i)(password|passwd|secret|token|key)`)
+
 // DefaultOptions returns default rendering options
 func DefaultOptions() Options {
 	return Options{
@@ -25,6 +48,8 @@ func DefaultOptions() Options {
 		IndentSize:      2,
 		MaxWidth:        0,
 		Interactive:     false,
+		ShowComments:    true,
+		NullStyle:       parser.NullCanonical,
 	}
 }
 
@@ -33,6 +58,16 @@ type Renderer struct {
 	theme   *Theme
 	options Options
 	chars   TreeChars
+
+	// topDepth is the depth of the node passed to Render, whose children
+	// compact mode shows
+	topDepth int
+
+	// highlight is the lowercased search query whose occurrences in keys
+	// (highlightKeys) and values (highlightValues) use the Match style
+	highlight       string
+	highlightKeys   bool
+	highlightValues bool
 }
 
 // New creates a new Renderer
@@ -47,36 +82,148 @@ func New(theme *Theme, opts Options) *Renderer {
 	}
 }
 
+// WithTheme returns a copy of the renderer that draws with theme
+func (r *Renderer) WithTheme(theme *Theme) *Renderer {
+	c := *r
+	c.theme = theme
+	return &c
+}
+
+// WithMaxValueLength returns a copy of the renderer that cuts scalar
+// values longer than n characters (0 = unlimited)
+func (r *Renderer) WithMaxValueLength(n int) *Renderer {
+	c := *r
+	c.options.MaxValueLength = n
+	return &c
+}
+
+// WithHighlight returns a copy of the renderer that highlights the
+// occurrences of query, ignoring case, in keys and/or values. An empty
+// query highlights nothing.
+func (r *Renderer) WithHighlight(query string, keys, values bool) *Renderer {
+	c := *r
+	c.highlight = strings.ToLower(query)
+	c.highlightKeys = keys
+	c.highlightValues = values
+	return &c
+}
+
 // Render converts a YamNode tree to a styled string
 func (r *Renderer) Render(root *parser.YamNode) string {
 	var buf strings.Builder
-	r.renderNode(&buf, root, "", true)
+	directives := root.Directives
+	if !r.options.ShowDirectives {
+		directives = nil
+	}
+	if directives != nil {
+		for _, line := range directives.Lines {
+			buf.WriteString(r.theme.Tag.Render(line) + "\n")
+		}
+	}
+	if directives.StartMarker() {
+		buf.WriteString(r.theme.TreeBranch.Render("---") + "\n")
+	}
+	top := *r
+	top.topDepth = root.Depth
+	top.renderNode(&buf, root, "", true)
+	if directives != nil && directives.End {
+		buf.WriteString(r.theme.TreeBranch.Render("...") + "\n")
+	}
 	return buf.String()
 }
 
-// RenderVisible renders only visible nodes (respecting collapse state)
+// RenderVisible renders only visible nodes (respecting collapse state).
+// Head and foot comments are never rendered here so that each output line
+// corresponds to exactly one node.
 func (r *Renderer) RenderVisible(root *parser.YamNode) string {
 	var buf strings.Builder
-	r.renderNodeVisible(&buf, root, "", true)
+	top := *r
+	top.topDepth = root.Depth
+	top.renderNodeVisible(&buf, root, "", true)
 	return buf.String()
 }
 
 func (r *Renderer) renderNode(buf *strings.Builder, node *parser.YamNode, prefix string, isLast bool) {
 	if node.Kind() == parser.KindDocument {
+		r.renderComment(buf, node.HeadComment(), "")
 		for i, child := range node.Children {
 			r.renderNode(buf, child, prefix, i == len(node.Children)-1)
 		}
+		r.renderComment(buf, node.FootComment(), "")
 		return
 	}
 
+	r.renderComment(buf, node.HeadComment(), r.getCommentPrefix(prefix, false, node.Depth))
 	r.renderSingleNode(buf, node, prefix, isLast)
 
-	if node.HasChildren() {
+	if node.HasChildren() && !r.compacted(node) {
 		newPrefix := r.getChildPrefix(prefix, isLast, node.Depth)
 		for i, child := range node.Children {
 			r.renderNode(buf, child, newPrefix, i == len(node.Children)-1)
 		}
 	}
+
+	r.renderComment(buf, node.FootComment(), r.getCommentPrefix(prefix, isLast, node.Depth))
+}
+
+// renderComment writes each line of a head/foot comment behind the tree prefix
+func (r *Renderer) renderComment(buf *strings.Builder, comment, prefix string) {
+	if !r.options.ShowComments || comment == "" {
+		return
+	}
+	for _, line := range strings.Split(comment, "\n") {
+		buf.WriteString(r.fitWidth(prefix + r.theme.Comment.Render(line)))
+		buf.WriteString("\n")
+	}
+}
+
+// getCommentPrefix returns the tree prefix for comment lines of a node,
+// aligning the comment text with the node's key
+func (r *Renderer) getCommentPrefix(prefix string, isLast bool, depth int) string {
+	if depth == 0 {
+		return ""
+	}
+	if isLast {
+		return prefix + "   "
+	}
+	return prefix + r.theme.TreeBranch.Render(r.chars.Vertical) + "  "
+}
+
+// RenderLine renders the single line of node as RenderVisible(root) would,
+// without rendering the rest of the tree. The tree prefix is derived from
+// the node's ancestors up to root. The result has no trailing newline.
+func (r *Renderer) RenderLine(root, node *parser.YamNode) string {
+	var ancestors []*parser.YamNode
+	if node != root {
+		for a := node.Parent; a != nil; a = a.Parent {
+			ancestors = append(ancestors, a)
+			if a == root {
+				break
+			}
+		}
+	}
+
+	prefix := ""
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		a := ancestors[i]
+		if a.Kind() == parser.KindDocument {
+			continue
+		}
+		prefix = r.getChildPrefix(prefix, a == root || isLastChild(a), a.Depth)
+	}
+
+	var buf strings.Builder
+	r.renderSingleNode(&buf, node, prefix, node == root || isLastChild(node))
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// isLastChild reports whether node is the last child of its parent
+func isLastChild(node *parser.YamNode) bool {
+	if node.Parent == nil {
+		return true
+	}
+	siblings := node.Parent.Children
+	return len(siblings) > 0 && siblings[len(siblings)-1] == node
 }
 
 func (r *Renderer) renderNodeVisible(buf *strings.Builder, node *parser.YamNode, prefix string, isLast bool) {
@@ -89,7 +236,7 @@ func (r *Renderer) renderNodeVisible(buf *strings.Builder, node *parser.YamNode,
 
 	r.renderSingleNode(buf, node, prefix, isLast)
 
-	if node.HasChildren() && !node.Collapsed {
+	if node.HasChildren() && !r.folded(node) {
 		newPrefix := r.getChildPrefix(prefix, isLast, node.Depth)
 		for i, child := range node.Children {
 			r.renderNodeVisible(buf, child, newPrefix, i == len(node.Children)-1)
@@ -111,8 +258,9 @@ func (r *Renderer) renderSingleNode(buf *strings.Builder, node *parser.YamNode,
 	}
 
 	// Collapse indicator for containers (only in interactive/TUI mode)
+	folded := r.folded(node)
 	if r.options.Interactive && node.IsContainer() && node.HasChildren() {
-		if node.Collapsed {
+		if folded {
 			line.WriteString(r.theme.TreeBranch.Render(r.chars.Collapsed + " "))
 		} else {
 			line.WriteString(r.theme.TreeBranch.Render(r.chars.Expanded + " "))
@@ -121,7 +269,7 @@ func (r *Renderer) renderSingleNode(buf *strings.Builder, node *parser.YamNode,
 
 	// Key (for mapping entries) or array index
 	if node.Key != "" {
-		line.WriteString(r.theme.Key.Render(node.Key))
+		line.WriteString(r.renderMatches(node.Key, r.theme.Key, r.highlightKeys))
 		line.WriteString(r.theme.KeySeparator.Render(": "))
 	} else if node.Parent != nil && node.Parent.Kind() == parser.KindSequence {
 		// Array element - show index
@@ -129,21 +277,37 @@ func (r *Renderer) renderSingleNode(buf *strings.Builder, node *parser.YamNode,
 		line.WriteString(r.theme.KeySeparator.Render(" "))
 	}
 
-	// Value rendering based on node type
+	// Tag (explicit tags always; resolved tags with ShowTags)
+	empty := node.IsContainer() && !node.HasChildren()
+	if tag := r.visibleTag(node); tag != "" {
+		line.WriteString(r.theme.Tag.Render(tag))
+		if node.Kind() == parser.KindScalar || folded || empty {
+			line.WriteString(" ")
+		}
+	}
+
+	// Value rendering based on node type. Empty containers are written out
+	// so they do not look like nulls.
 	switch node.Kind() {
 	case parser.KindMapping:
-		if node.Collapsed {
+		if empty {
+			line.WriteString(r.theme.Collapsed.Render("{}"))
+		} else if folded && r.options.Compact {
+			line.WriteString(r.theme.Collapsed.Render(fmt.Sprintf("{%d keys}", len(node.Children))))
+		} else if folded {
 			line.WriteString(r.theme.Collapsed.Render("{...}"))
 		}
 	case parser.KindSequence:
-		if node.Collapsed {
+		if empty {
+			line.WriteString(r.theme.Collapsed.Render("[]"))
+		} else if folded {
 			count := len(node.Children)
 			line.WriteString(r.theme.Collapsed.Render(fmt.Sprintf("[%d items]", count)))
 		}
 	case parser.KindScalar:
 		line.WriteString(r.renderValue(node))
 	case parser.KindAlias:
-		line.WriteString(r.theme.Alias.Render("*" + node.Value()))
+		line.WriteString(r.renderMatches("*"+node.Value(), r.theme.Alias, r.highlightValues))
 	}
 
 	// Anchor
@@ -158,34 +322,89 @@ func (r *Renderer) renderSingleNode(buf *strings.Builder, node *parser.YamNode,
 		line.WriteString(r.theme.Comment.Render(comment))
 	}
 
-	buf.WriteString(line.String())
+	buf.WriteString(r.fitWidth(line.String()))
 	buf.WriteString("\n")
 }
 
+// folded reports whether a container is shown on one line without its
+// children: when collapsed, or when compact mode folds it
+func (r *Renderer) folded(node *parser.YamNode) bool {
+	return node.Collapsed || r.compacted(node)
+}
+
+// compacted reports whether compact mode folds a container: every one
+// below the rendered root
+func (r *Renderer) compacted(node *parser.YamNode) bool {
+	return r.options.Compact && node.Depth > r.topDepth && node.HasChildren()
+}
+
+// fitWidth truncates a styled line to MaxWidth display columns
+func (r *Renderer) fitWidth(line string) string {
+	if r.options.MaxWidth <= 0 || ansi.StringWidth(line) <= r.options.MaxWidth {
+		return line
+	}
+	return ansi.Truncate(line, r.options.MaxWidth, "…")
+}
+
+// visibleTag returns the tag to display for a node. Tags written in the
+// source (e.g. !Ref, !!binary) are always shown; tags resolved by the parser
+// (!!str on a plain string) only when ShowTags is set.
+func (r *Renderer) visibleTag(node *parser.YamNode) string {
+	if node.Raw == nil || node.Kind() == parser.KindAlias || node.Kind() == parser.KindDocument {
+		return ""
+	}
+	if node.Raw.Style&yaml.TaggedStyle != 0 || r.options.ShowTags {
+		return node.Tag()
+	}
+	return ""
+}
+
+// isRedacted reports whether a scalar sits under a key (its own or an
+// ancestor's) matching the Redact pattern
+func (r *Renderer) isRedacted(node *parser.YamNode) bool {
+	if r.options.Redact == nil {
+		return false
+	}
+	for n := node; n != nil; n = n.Parent {
+		if n.Key != "" && r.options.Redact.MatchString(n.Key) {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *Renderer) renderValue(node *parser.YamNode) string {
 	value := node.Value()
 	scalarType := node.InferType()
 
+	// Binary blobs are summarized unless shown in full
+	var binary string
+	if !r.options.ShowBinary && !r.options.Revealed[node] {
+		binary, _ = parser.BinaryLabel(node)
+	}
+
 	var rendered string
-	switch scalarType {
-	case parser.TypeNull:
-		if value == "" || value == "~" {
-			rendered = r.theme.Null.Render("null")
-		} else {
-			rendered = r.theme.Null.Render(value)
-		}
-	case parser.TypeBoolean:
-		rendered = r.theme.Boolean.Render(value)
-	case parser.TypeNumber:
-		rendered = r.theme.Number.Render(value)
-	case parser.TypeTimestamp:
-		rendered = r.theme.Timestamp.Render(value)
+	switch {
+	case r.isRedacted(node):
+		rendered = r.theme.String.Render(RedactedValue)
+	case binary != "":
+		rendered = r.theme.Collapsed.Render(binary)
+	case scalarType == parser.TypeNull:
+		rendered = r.renderMatches(r.truncateValue(r.options.NullStyle.Form(value)), r.theme.Null, r.highlightValues)
+	case scalarType == parser.TypeBoolean:
+		rendered = r.renderMatches(r.truncateValue(value), r.theme.Boolean, r.highlightValues)
+	case scalarType == parser.TypeNumber:
+		rendered = r.renderMatches(r.truncateValue(value), r.theme.Number, r.highlightValues)
+	case scalarType == parser.TypeTimestamp:
+		rendered = r.renderMatches(r.truncateValue(r.formatTimestamp(value)), r.theme.Timestamp, r.highlightValues)
+	case r.options.ExpandEnv != nil && strings.Contains(value, "$"):
+		rendered = r.renderExpanded(value, node.Tag())
 	default:
 		// Quote strings that might be confusing
-		if needsQuoting(value) {
-			rendered = r.theme.String.Render(fmt.Sprintf("%q", value))
+		if parser.ScalarNeedsQuoting(value, node.Tag()) {
+			rendered = r.renderMatches(fmt.Sprintf("%q", r.truncateValue(value)), r.theme.String, r.highlightValues)
 		} else {
-			rendered = r.theme.String.Render(value)
+			rendered = r.renderMatches(r.truncateValue(value), r.theme.String, r.highlightValues)
 		}
 	}
 
@@ -198,51 +417,112 @@ func (r *Renderer) renderValue(node *parser.YamNode) string {
 	return rendered
 }
 
-func (r *Renderer) getTypeLabel(t parser.ScalarType) string {
-	switch t {
-	case parser.TypeString:
-		return "<str>"
-	case parser.TypeNumber:
-		return "<int>"
-	case parser.TypeBoolean:
-		return "<bool>"
-	case parser.TypeNull:
-		return "<null>"
-	case parser.TypeTimestamp:
-		return "<time>"
-	default:
-		return ""
+// renderMatches renders text with style, drawing the occurrences of the
+// highlighted query with the Match style on top when on is set
+func (r *Renderer) renderMatches(text string, style lipgloss.Style, on bool) string {
+	lower := strings.ToLower(text)
+	// Offsets into lower only apply to text when lowering kept the length
+	if !on || r.highlight == "" || len(lower) != len(text) {
+		return style.Render(text)
+	}
+	match := r.theme.Match.Inherit(style)
+
+	var buf strings.Builder
+	for {
+		i := strings.Index(lower, r.highlight)
+		if i < 0 {
+			break
+		}
+		end := i + len(r.highlight)
+		if i > 0 {
+			buf.WriteString(style.Render(text[:i]))
+		}
+		buf.WriteString(match.Render(text[i:end]))
+		text, lower = text[end:], lower[end:]
 	}
+	if text != "" {
+		buf.WriteString(style.Render(text))
+	}
+	return buf.String()
 }
 
-func (r *Renderer) getChildPrefix(prefix string, isLast bool, depth int) string {
-	if depth == 0 {
-		return ""
+// renderExpanded renders a string with its environment placeholders
+// substituted. Placeholders for unset variables keep their original text
+// and are shown in the warning color.
+func (r *Renderer) renderExpanded(value, tag string) string {
+	segments := parser.ExpandEnv(value, r.options.ExpandEnv)
+	if segments == nil {
+		if parser.ScalarNeedsQuoting(value, tag) {
+			return r.theme.String.Render(fmt.Sprintf("%q", r.truncateValue(value)))
+		}
+		return r.theme.String.Render(r.truncateValue(value))
 	}
-	if isLast {
-		return prefix + "    "
+
+	// MaxValueLength applies to the expanded text as a whole
+	total := 0
+	for _, seg := range segments {
+		total += utf8.RuneCountInString(seg.Text)
 	}
-	return prefix + r.theme.TreeBranch.Render(r.chars.Vertical) + "   "
+	cut := r.options.MaxValueLength > 0 && total > r.options.MaxValueLength
+	room := r.options.MaxValueLength - 1
+
+	var buf strings.Builder
+	for _, seg := range segments {
+		text := seg.Text
+		if cut {
+			if runes := []rune(text); len(runes) > room {
+				text = string(runes[:room])
+			}
+			room -= utf8.RuneCountInString(text)
+			if text == "" {
+				continue
+			}
+		}
+		if seg.Unset {
+			buf.WriteString(r.theme.Warning.Render(text))
+		} else {
+			buf.WriteString(r.theme.String.Render(text))
+		}
+	}
+	if cut {
+		buf.WriteString(r.theme.String.Render("…"))
+	}
+	return buf.String()
 }
 
-func needsQuoting(s string) bool {
-	if s == "" {
-		return true
+// truncateValue cuts text to MaxValueLength characters, the last of them
+// "…". Characters are runes, so multibyte text is never split.
+func (r *Renderer) truncateValue(text string) string {
+	limit := r.options.MaxValueLength
+	if limit <= 0 || utf8.RuneCountInString(text) <= limit {
+		return text
 	}
-	// Check for special characters or values that might be confusing
-	switch strings.ToLower(s) {
-	case "true", "false", "yes", "no", "on", "off", "null", "~":
-		return true
+	return string([]rune(text)[:limit-1]) + "…"
+}
+
+// formatTimestamp converts a timestamp to the configured display zone.
+// Date-only values and unparseable values are shown as written.
+func (r *Renderer) formatTimestamp(value string) string {
+	if r.options.Timezone == nil {
+		return value
 	}
-	// Check for leading/trailing whitespace
-	if s != strings.TrimSpace(s) {
-		return true
+	t, dateOnly, ok := parser.ParseTimestamp(value)
+	if !ok || dateOnly {
+		return value
 	}
-	// Check for special characters
-	for _, c := range s {
-		if c == ':' || c == '#' || c == '\n' || c == '\t' {
-			return true
-		}
+	return parser.FormatTimestamp(t.In(r.options.Timezone), false)
+}
+
+func (r *Renderer) getTypeLabel(t parser.ScalarType) string {
+	return "<" + t.String() + ">"
+}
+
+func (r *Renderer) getChildPrefix(prefix string, isLast bool, depth int) string {
+	if depth == 0 {
+		return ""
 	}
-	return false
+	if isLast {
+		return prefix + "    "
+	}
+	return prefix + r.theme.TreeBranch.Render(r.chars.Vertical) + "   "
 }
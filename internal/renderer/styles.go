@@ -1,6 +1,10 @@
 package renderer
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+)
 
 // Theme defines colors for YAML elements
 type Theme struct {
@@ -33,6 +37,12 @@ type Theme struct {
 
 	// Type annotations
 	TypeLabel lipgloss.Style
+
+	// Warnings (e.g. unset environment variables)
+	Warning lipgloss.Style
+
+	// Search matches within keys and values, drawn on top of their style
+	Match lipgloss.Style
 }
 
 // DefaultTheme returns the default color theme
@@ -77,6 +87,10 @@ func DefaultTheme() *Theme {
 		TypeLabel: lipgloss.NewStyle().
 			Foreground(lipgloss.AdaptiveColor{Light: "#8B949E", Dark: "#6E7681"}).
 			Italic(true),
+		Warning: lipgloss.NewStyle().
+			Foreground(lipgloss.AdaptiveColor{Light: "#9A6700", Dark: "#D29922"}),
+		Match: lipgloss.NewStyle().
+			Reverse(true),
 	}
 }
 
@@ -87,8 +101,27 @@ const (
 	TreeStyleUnicode TreeStyle = iota
 	TreeStyleASCII
 	TreeStyleIndent
+	TreeStyleRounded
+	TreeStyleHeavy
 )
 
+// ParseTreeStyle parses a --style flag value
+func ParseTreeStyle(name string) (TreeStyle, error) {
+	switch name {
+	case "unicode":
+		return TreeStyleUnicode, nil
+	case "ascii":
+		return TreeStyleASCII, nil
+	case "indent":
+		return TreeStyleIndent, nil
+	case "rounded":
+		return TreeStyleRounded, nil
+	case "heavy":
+		return TreeStyleHeavy, nil
+	}
+	return TreeStyleUnicode, fmt.Errorf("unknown tree style %q (want unicode, ascii, indent, rounded or heavy)", name)
+}
+
 // TreeChars holds the characters for tree drawing
 type TreeChars struct {
 	Vertical   string // │
@@ -120,6 +153,24 @@ func GetTreeChars(style TreeStyle) TreeChars {
 			Collapsed:  "+",
 			Expanded:   "-",
 		}
+	case TreeStyleRounded:
+		return TreeChars{
+			Vertical:   "│",
+			Horizontal: "─",
+			Corner:     "╰",
+			Tee:        "├",
+			Collapsed:  "▶",
+			Expanded:   "▼",
+		}
+	case TreeStyleHeavy:
+		return TreeChars{
+			Vertical:   "┃",
+			Horizontal: "━",
+			Corner:     "┗",
+			Tee:        "┣",
+			Collapsed:  "▶",
+			Expanded:   "▼",
+		}
 	default: // TreeStyleUnicode
 		return TreeChars{
 			Vertical:   "│",
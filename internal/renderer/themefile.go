@@ -0,0 +1,122 @@
+package renderer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/simota/yam/internal/parser"
+)
+
+// hexColor matches the colors accepted in a theme file: #RGB or #RRGGBB
+var hexColor = regexp.MustCompile(`^#([0-9A-Fa-f]{3}|[0-9A-Fa-f]{6})$`)
+
+// DefaultThemeFile returns the theme file read when --theme-file is not
+// given, under the user config directory (e.g. ~/.config/yam/theme.yaml)
+func DefaultThemeFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "yam", "theme.yaml"), nil
+}
+
+// themeElements maps the element names used in theme files to the styles
+// they color
+func themeElements(t *Theme) map[string]*lipgloss.Style {
+	return map[string]*lipgloss.Style{
+		"key":           &t.Key,
+		"key_separator": &t.KeySeparator,
+		"string":        &t.String,
+		"number":        &t.Number,
+		"boolean":       &t.Boolean,
+		"null":          &t.Null,
+		"timestamp":     &t.Timestamp,
+		"anchor":        &t.Anchor,
+		"alias":         &t.Alias,
+		"tag":           &t.Tag,
+		"comment":       &t.Comment,
+		"line_number":   &t.LineNumber,
+		"tree_branch":   &t.TreeBranch,
+		"collapsed":     &t.Collapsed,
+		"array_index":   &t.ArrayIndex,
+		"type_label":    &t.TypeLabel,
+		"warning":       &t.Warning,
+		"match":         &t.Match,
+	}
+}
+
+// LoadThemeFile reads a theme file mapping element names to colors, e.g.
+//
+//	key: "#FFD700"
+//	string: {light: "#0A3069", dark: "#A5D6FF"}
+//
+// and applies it on top of the default theme. A color is either one hex
+// color or separate colors for light and dark terminals. Unknown elements
+// and invalid colors keep their defaults and are reported as warnings.
+func LoadThemeFile(path string) (*Theme, []string, error) {
+	root, err := parser.New().ParseFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read theme: %w", err)
+	}
+	if root.Kind() == parser.KindDocument && len(root.Children) > 0 {
+		root = root.Children[0]
+	}
+	if root.Kind() != parser.KindMapping {
+		return nil, nil, fmt.Errorf("failed to read theme: %s: expected a mapping of element names to colors", path)
+	}
+
+	theme := DefaultTheme()
+	elements := themeElements(theme)
+	var warnings []string
+	for _, entry := range root.Children {
+		style, ok := elements[entry.Key]
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("%s:%d: unknown element %q", path, entry.Line(), entry.Key))
+			continue
+		}
+		color, err := themeColor(entry)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s:%d: %s: %v; using the default", path, entry.Line(), entry.Key, err))
+			continue
+		}
+		*style = style.Foreground(color)
+	}
+	return theme, warnings, nil
+}
+
+// themeColor reads a color entry: a hex color, or a mapping with light
+// and dark hex colors
+func themeColor(node *parser.YamNode) (lipgloss.TerminalColor, error) {
+	switch node.Kind() {
+	case parser.KindScalar:
+		if !hexColor.MatchString(node.Value()) {
+			return nil, fmt.Errorf("invalid color %q (want #RGB or #RRGGBB)", node.Value())
+		}
+		return lipgloss.Color(node.Value()), nil
+
+	case parser.KindMapping:
+		var adaptive lipgloss.AdaptiveColor
+		for _, child := range node.Children {
+			value := child.Value()
+			if child.Kind() != parser.KindScalar || !hexColor.MatchString(value) {
+				return nil, fmt.Errorf("invalid %s color %q (want #RGB or #RRGGBB)", child.Key, value)
+			}
+			switch child.Key {
+			case "light":
+				adaptive.Light = value
+			case "dark":
+				adaptive.Dark = value
+			default:
+				return nil, fmt.Errorf("unknown color variant %q (want light or dark)", child.Key)
+			}
+		}
+		if adaptive.Light == "" || adaptive.Dark == "" {
+			return nil, fmt.Errorf("both light and dark colors are required")
+		}
+		return adaptive, nil
+	}
+	return nil, fmt.Errorf("expected a color or a mapping with light and dark colors")
+}
@@ -0,0 +1,30 @@
+package ui
+
+import "github.com/simota/yam/internal/parser"
+
+// lineCache holds rendered tree lines between frames, so cursor movement
+// and scrolling only re-render lines that have not been seen yet.
+// Anything that changes the text of a line (folding, edits, a new tree)
+// must call invalidate.
+type lineCache struct {
+	lines map[*parser.YamNode]string
+}
+
+func newLineCache() *lineCache {
+	return &lineCache{lines: make(map[*parser.YamNode]string)}
+}
+
+// line returns the rendered line for node, rendering it on a cache miss
+func (c *lineCache) line(node *parser.YamNode, render func(*parser.YamNode) string) string {
+	if line, ok := c.lines[node]; ok {
+		return line
+	}
+	line := render(node)
+	c.lines[node] = line
+	return line
+}
+
+// invalidate drops all cached lines
+func (c *lineCache) invalidate() {
+	clear(c.lines)
+}
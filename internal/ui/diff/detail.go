@@ -0,0 +1,106 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/simota/yam/internal/diff"
+	"github.com/simota/yam/internal/parser"
+)
+
+// detailHeight returns the number of rows taken by the detail panel,
+// including its top border, or 0 when it is hidden
+func (m *Model) detailHeight() int {
+	if !m.showDetail {
+		return 0
+	}
+	h := (m.height - 5) / 2
+	if h < 3 {
+		return 0
+	}
+	return h
+}
+
+// detailLines lays out the selected change: its path and diff type, then
+// the full old and new values with their types, wrapped to the width
+func (m Model) detailLines() []string {
+	if m.cursor < 0 || m.cursor >= len(m.diffNodes) {
+		return []string{"(nothing selected)"}
+	}
+	node := m.diffNodes[m.cursor]
+
+	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#79C0FF"))
+	lines := []string{labelStyle.Render(node.Path) + "  " + diffStyle(node.Type).Render(node.Type.String())}
+
+	addSide := func(label string, yamNode *parser.YamNode, style lipgloss.Style) {
+		if yamNode == nil {
+			lines = append(lines, labelStyle.Render(label)+" (absent)")
+			return
+		}
+		lines = append(lines, labelStyle.Render(label)+" ("+detailType(yamNode)+")")
+		for _, line := range strings.Split(detailValue(yamNode), "\n") {
+			for _, row := range wrapText(line, m.width-2) {
+				lines = append(lines, style.Render("  "+row))
+			}
+		}
+	}
+	addSide("old", node.Left, diffStyle(diff.DiffRemoved))
+	addSide("new", node.Right, diffStyle(diff.DiffAdded))
+	return lines
+}
+
+// renderDetail renders the detail panel at detailHeight rows, cutting off
+// values that do not fit
+func (m Model) renderDetail() string {
+	height := m.detailHeight()
+	border := lipgloss.NewStyle().Foreground(lipgloss.Color("#30363D")).
+		Render(strings.Repeat("─", m.width))
+
+	lines := m.detailLines()
+	if len(lines) > height-1 {
+		lines = lines[:height-1]
+		lines[len(lines)-1] = diffStyle(diff.DiffUnchanged).Render("  …")
+	}
+	for len(lines) < height-1 {
+		lines = append(lines, "")
+	}
+	return border + "\n" + strings.Join(lines, "\n") + "\n"
+}
+
+// detailType names the type of a value: the inferred type of a scalar, or
+// the kind and size of a container
+func detailType(n *parser.YamNode) string {
+	switch n.Kind() {
+	case parser.KindMapping:
+		return fmt.Sprintf("mapping, %d keys", len(n.Children))
+	case parser.KindSequence:
+		return fmt.Sprintf("sequence, %d items", len(n.Children))
+	case parser.KindScalar:
+		return n.InferType().String()
+	}
+	return n.Kind().String()
+}
+
+// detailValue returns the full text of a value; containers are shown as
+// indented JSON
+func detailValue(n *parser.YamNode) string {
+	if n.Kind() == parser.KindAlias {
+		return "*" + n.Value()
+	}
+	if !n.IsContainer() {
+		return n.Value()
+	}
+	out, err := parser.ToJSON(n, true)
+	if err != nil {
+		return n.Value()
+	}
+	return string(out)
+}
+
+// wrapText splits s into rows of at most width cells, so wide characters
+// such as CJK and emoji take up the two columns they are drawn in
+func wrapText(s string, width int) []string {
+	return strings.Split(ansi.Hardwrap(s, width, true), "\n")
+}
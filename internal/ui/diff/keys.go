@@ -12,6 +12,11 @@ type KeyMap struct {
 	Bottom   key.Binding
 	NextDiff key.Binding
 	PrevDiff key.Binding
+	Toggle   key.Binding
+	Unified  key.Binding
+	Unlink   key.Binding
+	Switch   key.Binding
+	Detail   key.Binding
 	Help     key.Binding
 	Quit     key.Binding
 }
@@ -51,6 +56,26 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("N", "["),
 			key.WithHelp("N", "prev diff"),
 		),
+		Toggle: key.NewBinding(
+			key.WithKeys("enter", "o"),
+			key.WithHelp("Enter/o", "toggle fold"),
+		),
+		Unified: key.NewBinding(
+			key.WithKeys("u"),
+			key.WithHelp("u", "unified/split view"),
+		),
+		Unlink: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "unlink/link panes"),
+		),
+		Switch: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("Tab", "switch pane"),
+		),
+		Detail: key.NewBinding(
+			key.WithKeys("i"),
+			key.WithHelp("i", "value details"),
+		),
 		Help: key.NewBinding(
 			key.WithKeys("?"),
 			key.WithHelp("?", "help"),
@@ -64,7 +89,7 @@ func DefaultKeyMap() KeyMap {
 
 // ShortHelp returns keybindings to be shown in the mini help view
 func (k KeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Up, k.Down, k.NextDiff, k.PrevDiff, k.Help, k.Quit}
+	return []key.Binding{k.Up, k.Down, k.NextDiff, k.PrevDiff, k.Toggle, k.Unified, k.Detail, k.Help, k.Quit}
 }
 
 // FullHelp returns keybindings for the expanded help view
@@ -73,6 +98,8 @@ func (k KeyMap) FullHelp() [][]key.Binding {
 		{k.Up, k.Down, k.PageUp, k.PageDown},
 		{k.Top, k.Bottom},
 		{k.NextDiff, k.PrevDiff},
+		{k.Toggle, k.Unified, k.Detail},
+		{k.Unlink, k.Switch},
 		{k.Help, k.Quit},
 	}
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/simota/yam/internal/diff"
 	"github.com/simota/yam/internal/parser"
 )
@@ -18,11 +19,17 @@ type Model struct {
 	leftRoot  *parser.YamNode
 	rightRoot *parser.YamNode
 
-	// Flattened diff nodes for navigation
+	// Flattened diff nodes for navigation (visible nodes only)
 	diffNodes []*diff.DiffNode
 	cursor    int
 	offset    int
 
+	// Fold state: collapsed containers, and every node in tree order
+	// with its parent so that navigation can reach folded nodes
+	collapsed map[*diff.DiffNode]bool
+	allNodes  []*diff.DiffNode
+	parents   map[*diff.DiffNode]*diff.DiffNode
+
 	// Window dimensions
 	width  int
 	height int
@@ -32,6 +39,21 @@ type Model struct {
 	help     help.Model
 	showHelp bool
 
+	// unified renders a single column with removed and added lines
+	// interleaved instead of the side-by-side split view
+	unified bool
+
+	// showDetail shows the full old and new values of the selected change
+	// in a panel below the diff
+	showDetail bool
+
+	// unlinked shows each side of the split view on its own, scrolled
+	// separately (see panes.go); the cursor moves in the focused pane
+	unlinked    bool
+	focusRight  bool
+	leftOffset  int
+	rightOffset int
+
 	// Status message (temporary feedback)
 	statusMessage string
 }
@@ -44,12 +66,42 @@ func NewModel(result *diff.DiffResult, left, right *parser.YamNode) Model {
 		rightRoot: right,
 		keyMap:    DefaultKeyMap(),
 		help:      help.New(),
+		collapsed: make(map[*diff.DiffNode]bool),
+		parents:   make(map[*diff.DiffNode]*diff.DiffNode),
 	}
+	if result != nil && result.Root != nil {
+		m.indexDiffTree(result.Root, nil)
+	}
+	m.collapseUnchanged()
 	m.flattenDiffNodes()
 	return m
 }
 
-// flattenDiffNodes builds a flat list of diff nodes for navigation
+// indexDiffTree records every node in tree order together with its parent
+func (m *Model) indexDiffTree(node, parent *diff.DiffNode) {
+	if node == nil {
+		return
+	}
+	if !isDocumentNode(node) {
+		m.allNodes = append(m.allNodes, node)
+	}
+	m.parents[node] = parent
+	for _, child := range node.Children {
+		m.indexDiffTree(child, node)
+	}
+}
+
+// collapseUnchanged folds every container without changes so the view
+// opens on the changed regions
+func (m *Model) collapseUnchanged() {
+	for _, node := range m.allNodes {
+		if node.Type == diff.DiffUnchanged && len(node.Children) > 0 {
+			m.collapsed[node] = true
+		}
+	}
+}
+
+// flattenDiffNodes builds a flat list of visible diff nodes for navigation
 func (m *Model) flattenDiffNodes() {
 	m.diffNodes = nil
 	if m.result == nil || m.result.Root == nil {
@@ -68,11 +120,56 @@ func (m *Model) walkDiffTree(node *diff.DiffNode) {
 		m.diffNodes = append(m.diffNodes, node)
 	}
 
+	if m.collapsed[node] {
+		return
+	}
 	for _, child := range node.Children {
 		m.walkDiffTree(child)
 	}
 }
 
+// toggleCurrent folds or unfolds the container under the cursor
+func (m *Model) toggleCurrent() {
+	if m.cursor < 0 || m.cursor >= len(m.diffNodes) {
+		return
+	}
+	node := m.diffNodes[m.cursor]
+	if len(node.Children) == 0 {
+		return
+	}
+	m.collapsed[node] = !m.collapsed[node]
+	m.flattenDiffNodes()
+}
+
+// reveal unfolds the ancestors of node and moves the cursor to it
+func (m *Model) reveal(node *diff.DiffNode) {
+	for p := m.parents[node]; p != nil; p = m.parents[p] {
+		delete(m.collapsed, p)
+	}
+	m.flattenDiffNodes()
+	for i, n := range m.diffNodes {
+		if n == node {
+			m.cursor = i
+			break
+		}
+	}
+	m.adjustOffset()
+}
+
+// allNodesIndex returns the position of the cursor node in allNodes
+func (m *Model) allNodesIndex() int {
+	if m.cursor < 0 || m.cursor >= len(m.diffNodes) {
+		return -1
+	}
+	current := m.diffNodes[m.cursor]
+	for i, n := range m.allNodes {
+		if n == current {
+			return i
+		}
+	}
+	return -1
+}
+
 func isDocumentNode(node *diff.DiffNode) bool {
 	if node.Left != nil && node.Left.Kind() == parser.KindDocument {
 		return true
@@ -120,11 +217,17 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.moveCursor(m.viewportHeight())
 
 		case key.Matches(msg, m.keyMap.Top):
-			m.cursor = 0
-			m.offset = 0
+			if m.panesUnlinked() {
+				m.movePaneCursor(-len(m.diffNodes))
+			} else {
+				m.cursor = 0
+				m.offset = 0
+			}
 
 		case key.Matches(msg, m.keyMap.Bottom):
-			if len(m.diffNodes) > 0 {
+			if m.panesUnlinked() {
+				m.movePaneCursor(len(m.diffNodes))
+			} else if len(m.diffNodes) > 0 {
 				m.cursor = len(m.diffNodes) - 1
 				m.adjustOffset()
 			}
@@ -134,6 +237,25 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case key.Matches(msg, m.keyMap.PrevDiff):
 			m.prevDiff()
+
+		case key.Matches(msg, m.keyMap.Toggle):
+			m.toggleCurrent()
+
+		case key.Matches(msg, m.keyMap.Unified):
+			m.unified = !m.unified
+			m.adjustOffset()
+
+		case key.Matches(msg, m.keyMap.Unlink):
+			m.toggleUnlinked()
+
+		case key.Matches(msg, m.keyMap.Switch):
+			if m.panesUnlinked() {
+				m.switchPane()
+			}
+
+		case key.Matches(msg, m.keyMap.Detail):
+			m.showDetail = !m.showDetail
+			m.adjustOffset()
 		}
 	}
 
@@ -141,6 +263,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m *Model) moveCursor(delta int) {
+	if m.panesUnlinked() {
+		m.movePaneCursor(delta)
+		return
+	}
 	m.cursor += delta
 	if m.cursor < 0 {
 		m.cursor = 0
@@ -155,6 +281,10 @@ func (m *Model) moveCursor(delta int) {
 }
 
 func (m *Model) adjustOffset() {
+	if m.panesUnlinked() {
+		m.adjustPaneOffset()
+		return
+	}
 	vh := m.viewportHeight()
 	if vh <= 0 {
 		return
@@ -168,19 +298,18 @@ func (m *Model) adjustOffset() {
 }
 
 func (m *Model) viewportHeight() int {
-	h := m.height - 5 // header + footer + summary + help
+	h := m.height - 5 - m.detailHeight() // header + footer + summary + help
 	if h < 1 {
 		return 1
 	}
 	return h
 }
 
-// nextDiff jumps to next changed node
+// nextDiff jumps to next changed node, unfolding it if needed
 func (m *Model) nextDiff() {
-	for i := m.cursor + 1; i < len(m.diffNodes); i++ {
-		if m.diffNodes[i].Type != diff.DiffUnchanged {
-			m.cursor = i
-			m.adjustOffset()
+	for i := m.allNodesIndex() + 1; i < len(m.allNodes); i++ {
+		if m.allNodes[i].Type != diff.DiffUnchanged {
+			m.reveal(m.allNodes[i])
 			return
 		}
 	}
@@ -188,12 +317,11 @@ func (m *Model) nextDiff() {
 	m.statusMessage = "No more diffs below"
 }
 
-// prevDiff jumps to previous changed node
+// prevDiff jumps to previous changed node, unfolding it if needed
 func (m *Model) prevDiff() {
-	for i := m.cursor - 1; i >= 0; i-- {
-		if m.diffNodes[i].Type != diff.DiffUnchanged {
-			m.cursor = i
-			m.adjustOffset()
+	for i := m.allNodesIndex() - 1; i >= 0; i-- {
+		if m.allNodes[i].Type != diff.DiffUnchanged {
+			m.reveal(m.allNodes[i])
 			return
 		}
 	}
@@ -213,8 +341,18 @@ func (m Model) View() string {
 	b.WriteString(m.renderHeader())
 	b.WriteString("\n")
 
-	// Split view content
-	b.WriteString(m.renderSplitView())
+	// Diff content
+	switch {
+	case m.unified:
+		b.WriteString(m.renderUnifiedView())
+	case m.unlinked:
+		b.WriteString(m.renderPanes())
+	default:
+		b.WriteString(m.renderSplitView())
+	}
+	if m.detailHeight() > 0 {
+		b.WriteString(m.renderDetail())
+	}
 
 	// Footer with summary
 	b.WriteString(m.renderFooter())
@@ -249,6 +387,9 @@ func (m Model) renderHeader() string {
 
 	// Show OLD/NEW labels for clarity
 	headerText := fmt.Sprintf(" ← OLD: %s  │  NEW: %s →", leftFile, rightFile)
+	if m.unified {
+		headerText = fmt.Sprintf(" --- %s  +++ %s  (unified)", leftFile, rightFile)
+	}
 	return headerStyle.Render(headerText)
 }
 
@@ -266,11 +407,6 @@ func (m Model) renderSplitView() string {
 	cursorStyle := lipgloss.NewStyle().
 		Background(lipgloss.Color("#30363D"))
 
-	addedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#A6E3A1"))
-	removedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F38BA8"))
-	modifiedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#F9E2AF"))
-	unchangedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#8B949E"))
-
 	var lines []string
 
 	for i := 0; i < vh; i++ {
@@ -284,56 +420,121 @@ func (m Model) renderSplitView() string {
 		}
 
 		node := m.diffNodes[idx]
-		isCursor := idx == m.cursor
-
-		// Get display content for each side
-		leftText := m.renderNodeLeft(node, halfWidth)
-		rightText := m.renderNodeRight(node, halfWidth)
-
-		// Apply diff styling
-		var style lipgloss.Style
-		switch node.Type {
-		case diff.DiffAdded:
-			style = addedStyle
-		case diff.DiffRemoved:
-			style = removedStyle
-		case diff.DiffModified:
-			style = modifiedStyle
-		default:
-			style = unchangedStyle
+		leftDisplay := m.renderCell(node, false, halfWidth)
+		rightDisplay := m.renderCell(node, true, halfWidth)
+
+		// Apply cursor style
+		if idx == m.cursor {
+			leftDisplay = cursorStyle.Render(leftPaneStyle.Render(leftDisplay))
+			rightDisplay = cursorStyle.Render(rightPaneStyle.Render(rightDisplay))
 		}
 
-		// Get prefix based on diff type
-		leftPrefix, rightPrefix := m.getDiffPrefixes(node.Type)
+		lines = append(lines, leftDisplay+separatorStyle.String()+rightDisplay)
+	}
 
-		// Build left side
-		leftDisplay := leftPrefix + leftText
-		if len(leftDisplay) > halfWidth {
-			leftDisplay = leftDisplay[:halfWidth-1] + "…"
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// unifiedLine is one row of the unified view
+type unifiedLine struct {
+	node  int // index in diffNodes
+	text  string
+	style lipgloss.Style
+}
+
+// unifiedLines lays out diffNodes as git-style rows: a modified scalar
+// becomes a removed line followed by an added line, everything else one row
+func (m Model) unifiedLines() []unifiedLine {
+	removedPrefix, _ := m.getDiffPrefixes(diff.DiffRemoved)
+	_, addedPrefix := m.getDiffPrefixes(diff.DiffAdded)
+
+	var lines []unifiedLine
+	for i, node := range m.diffNodes {
+		bothContainers := node.Left != nil && node.Right != nil &&
+			node.Left.IsContainer() && node.Right.IsContainer()
+
+		switch {
+		case node.Type == diff.DiffAdded:
+			lines = append(lines, unifiedLine{i, addedPrefix + m.formatDiffSide(node, node.Right), diffStyle(diff.DiffAdded)})
+		case node.Type == diff.DiffRemoved:
+			lines = append(lines, unifiedLine{i, removedPrefix + m.formatDiffSide(node, node.Left), diffStyle(diff.DiffRemoved)})
+		case node.Type == diff.DiffModified && !bothContainers:
+			lines = append(lines,
+				unifiedLine{i, removedPrefix + m.formatDiffSide(node, node.Left), diffStyle(diff.DiffRemoved)},
+				unifiedLine{i, addedPrefix + m.formatDiffSide(node, node.Right), diffStyle(diff.DiffAdded)},
+			)
+		default:
+			prefix, _ := m.getDiffPrefixes(node.Type)
+			yamNode := node.Right
+			if yamNode == nil {
+				yamNode = node.Left
+			}
+			lines = append(lines, unifiedLine{i, prefix + m.formatDiffSide(node, yamNode), diffStyle(node.Type)})
 		}
-		leftDisplay = style.Render(leftDisplay)
-		leftDisplay = padRight(leftDisplay, halfWidth)
+	}
+	return lines
+}
+
+// renderUnifiedView renders the diff as a single column. Scrolling is
+// tracked per node, so the first visible row is that of the node at offset,
+// moved down if needed to keep every row of the cursor node on screen.
+func (m Model) renderUnifiedView() string {
+	vh := m.viewportHeight()
+	all := m.unifiedLines()
+
+	cursorStyle := lipgloss.NewStyle().
+		Background(lipgloss.Color("#30363D")).
+		Width(m.width)
 
-		// Build right side
-		rightDisplay := rightPrefix + rightText
-		if len(rightDisplay) > halfWidth {
-			rightDisplay = rightDisplay[:halfWidth-1] + "…"
+	start, cursorEnd := len(all), 0
+	for i, line := range all {
+		if line.node >= m.offset && i < start {
+			start = i
+		}
+		if line.node == m.cursor {
+			cursorEnd = i + 1
 		}
-		rightDisplay = style.Render(rightDisplay)
-		rightDisplay = padRight(rightDisplay, halfWidth)
+	}
+	if cursorEnd-start > vh {
+		start = cursorEnd - vh
+	}
 
-		// Apply cursor style
-		if isCursor {
-			leftDisplay = cursorStyle.Render(leftPaneStyle.Render(leftDisplay))
-			rightDisplay = cursorStyle.Render(rightPaneStyle.Render(rightDisplay))
+	var lines []string
+	for i := start; i < start+vh; i++ {
+		if i >= len(all) {
+			lines = append(lines, "")
+			continue
 		}
 
-		lines = append(lines, leftDisplay+separatorStyle.String()+rightDisplay)
+		line := all[i]
+		text := line.text
+		text = ansi.Truncate(text, m.width, "…")
+		text = line.style.Render(text)
+		if line.node == m.cursor {
+			text = cursorStyle.Render(text)
+		}
+		lines = append(lines, text)
 	}
 
 	return strings.Join(lines, "\n") + "\n"
 }
 
+// diffStyle returns the foreground style for a diff type
+func diffStyle(diffType diff.DiffType) lipgloss.Style {
+	switch diffType {
+	case diff.DiffAdded:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#A6E3A1"))
+	case diff.DiffRemoved:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#F38BA8"))
+	case diff.DiffModified:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#F9E2AF"))
+	case diff.DiffReordered:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#CBA6F7"))
+	default:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#8B949E"))
+	}
+}
+
 func (m Model) getDiffPrefixes(diffType diff.DiffType) (left, right string) {
 	switch diffType {
 	case diff.DiffAdded:
@@ -342,6 +543,8 @@ func (m Model) getDiffPrefixes(diffType diff.DiffType) (left, right string) {
 		return "- ", "  "
 	case diff.DiffModified:
 		return "~ ", "~ "
+	case diff.DiffReordered:
+		return "↕ ", "↕ "
 	default:
 		return "  ", "  "
 	}
@@ -351,14 +554,29 @@ func (m Model) renderNodeLeft(node *diff.DiffNode, maxWidth int) string {
 	if node.Left == nil {
 		return ""
 	}
-	return m.formatNode(node.Left)
+	return m.formatDiffSide(node, node.Left)
 }
 
 func (m Model) renderNodeRight(node *diff.DiffNode, maxWidth int) string {
 	if node.Right == nil {
 		return ""
 	}
-	return m.formatNode(node.Right)
+	return m.formatDiffSide(node, node.Right)
+}
+
+// formatDiffSide formats one side of a diff node, marking folded containers
+func (m Model) formatDiffSide(node *diff.DiffNode, yamNode *parser.YamNode) string {
+	text := m.formatNode(yamNode)
+	if !m.collapsed[node] {
+		return text
+	}
+	switch yamNode.Kind() {
+	case parser.KindMapping:
+		return text + " {...}"
+	case parser.KindSequence:
+		return text + fmt.Sprintf(" [%d items]", len(yamNode.Children))
+	}
+	return text
 }
 
 func (m Model) formatNode(yamNode *parser.YamNode) string {
@@ -401,6 +619,13 @@ func (m Model) renderFooter() string {
 
 	// Position info
 	position := fmt.Sprintf("%d/%d", m.cursor+1, len(m.diffNodes))
+	if m.panesUnlinked() {
+		side := "old"
+		if m.focusRight {
+			side = "new"
+		}
+		position += "  |  panes unlinked, " + side + " side focused"
+	}
 
 	// Legend with summary
 	addedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#A6E3A1"))
@@ -415,6 +640,9 @@ func (m Model) renderFooter() string {
 		modifiedStyle.Render("~"),
 		m.result.Summary.Modified,
 	)
+	if m.result.Summary.Reordered > 0 {
+		legend += fmt.Sprintf("  %s %d", diffStyle(diff.DiffReordered).Render("↕"), m.result.Summary.Reordered)
+	}
 
 	footerText := position + "  |  " + legend
 	return footerStyle.Render(footerText)
@@ -0,0 +1,283 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/simota/yam/internal/diff"
+	"github.com/simota/yam/internal/parser"
+)
+
+// newTestModel compares two documents, an empty one being a missing file,
+// and returns the diff TUI for them sized like a terminal
+func newTestModel(t *testing.T, left, right string, width, height int) Model {
+	t.Helper()
+	parse := func(input string) *parser.YamNode {
+		if input == "" {
+			return nil
+		}
+		root, err := parser.New().ParseString(input)
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		return root
+	}
+	l, r := parse(left), parse(right)
+	updated, _ := NewModel(diff.Compare(l, r), l, r).
+		Update(tea.WindowSizeMsg{Width: width, Height: height})
+	return updated.(Model)
+}
+
+// cursorPath returns the path of the node under the cursor
+func cursorPath(m Model) string {
+	if m.cursor < 0 || m.cursor >= len(m.diffNodes) {
+		return ""
+	}
+	return m.diffNodes[m.cursor].Path
+}
+
+// visiblePaths returns the paths of the nodes shown, in order
+func visiblePaths(m Model) []string {
+	var paths []string
+	for _, node := range m.diffNodes {
+		paths = append(paths, node.Path)
+	}
+	return paths
+}
+
+func TestUnlinkedPanes(t *testing.T) {
+	// Rows: $ $.a $.new1 $.old1 $.old2 $.old3 $.z, three per pane
+	m := newTestModel(t,
+		"a: 1\nold1: x\nold2: x\nold3: x\nz: 1\n",
+		"a: 1\nnew1: y\nz: 2\n",
+		80, 8)
+	m.toggleUnlinked()
+	if !m.panesUnlinked() || m.focusRight {
+		t.Fatalf("expected unlinked panes with the old side focused")
+	}
+	if got := len(m.paneNodes(false)); got != 6 {
+		t.Errorf("expected 6 rows in the old pane, got %d", got)
+	}
+	if got := len(m.paneNodes(true)); got != 4 {
+		t.Errorf("expected 4 rows in the new pane, got %d", got)
+	}
+
+	// Moving scrolls only the focused pane, clamped to its last row
+	m.moveCursor(100)
+	if cursorPath(m) != "$.z" || m.leftOffset != 3 || m.rightOffset != 0 {
+		t.Errorf("expected $.z with offsets 3/0, got %s with %d/%d", cursorPath(m), m.leftOffset, m.rightOffset)
+	}
+
+	// Switching keeps the cursor on a node of the other side
+	m.switchPane()
+	if !m.focusRight || cursorPath(m) != "$.z" || m.rightOffset != 1 {
+		t.Errorf("expected $.z in the new pane at offset 1, got %s at %d (focus right %v)", cursorPath(m), m.rightOffset, m.focusRight)
+	}
+	m.moveCursor(-1)
+	if cursorPath(m) != "$.new1" || m.rightOffset != 1 || m.leftOffset != 3 {
+		t.Errorf("expected $.new1 with offsets 3/1, got %s with %d/%d", cursorPath(m), m.leftOffset, m.rightOffset)
+	}
+	m.moveCursor(-100)
+	if cursorPath(m) != "$" || m.rightOffset != 0 {
+		t.Errorf("expected the top of the new pane, got %s at %d", cursorPath(m), m.rightOffset)
+	}
+
+	// A node on the new side only moves the focus along with the cursor
+	m.switchPane()
+	m.cursor = 2 // $.new1
+	m.adjustPaneOffset()
+	if !m.focusRight {
+		t.Errorf("expected the focus to follow the cursor to the new pane")
+	}
+
+	// The first node after the cursor stands in for one not on a side
+	if pos := m.panePosition(m.paneNodes(false)); pos != 2 {
+		t.Errorf("expected $.old1 at row 2 of the old pane, got %d", pos)
+	}
+
+	// Relinking scrolls the shared view to the cursor
+	m.moveCursor(100)
+	m.toggleUnlinked()
+	if m.panesUnlinked() || m.offset != len(m.diffNodes)-m.viewportHeight() {
+		t.Errorf("expected linked panes scrolled to the cursor, got offset %d", m.offset)
+	}
+}
+
+func TestUnlinkedPanesEmptySide(t *testing.T) {
+	// The new file is missing, so nothing is shown on its side
+	m := newTestModel(t, "a: 1\nb: 2\n", "", 80, 8)
+	if pos := m.panePosition(m.paneNodes(true)); pos != -1 {
+		t.Fatalf("expected no row in the empty pane, got %d", pos)
+	}
+
+	m.toggleUnlinked()
+	if m.rightOffset != 0 || m.leftOffset != 0 {
+		t.Errorf("expected both panes at the top, got offsets %d/%d", m.leftOffset, m.rightOffset)
+	}
+
+	m.moveCursor(1)
+	cursor := m.cursor
+	m.switchPane()
+	m.moveCursor(1)
+	m.moveCursor(-1)
+	if m.cursor != cursor || m.rightOffset != 0 {
+		t.Errorf("expected moving in the empty pane to do nothing, got cursor %d offset %d", m.cursor, m.rightOffset)
+	}
+	if view := m.View(); view == "" {
+		t.Error("expected the panes to render")
+	}
+}
+
+func TestCollapseUnchanged(t *testing.T) {
+	m := newTestModel(t,
+		"a:\n  x: 1\n  y: 2\nc:\n  p: 1\n  q: 1\n",
+		"a:\n  x: 1\n  y: 2\nc:\n  p: 2\n  q: 1\n",
+		80, 20)
+
+	// Unchanged containers open folded, changed ones unfolded
+	want := []string{"$", "$.a", "$.c", "$.c.p", "$.c.q"}
+	if got := visiblePaths(m); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	a := m.diffNodes[1]
+	if got := m.formatDiffSide(a, a.Left); got != "  a: {...}" {
+		t.Errorf("expected the folded mapping to be marked, got %q", got)
+	}
+
+	m.cursor = 1
+	m.toggleCurrent()
+	want = []string{"$", "$.a", "$.a.x", "$.a.y", "$.c", "$.c.p", "$.c.q"}
+	if got := visiblePaths(m); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v after expanding, got %v", want, got)
+	}
+	m.toggleCurrent()
+	if got := len(m.diffNodes); got != 5 {
+		t.Errorf("expected 5 rows after collapsing again, got %d", got)
+	}
+}
+
+func TestNextDiffUnfolds(t *testing.T) {
+	m := newTestModel(t,
+		"a:\n  x: 1\nc:\n  p: 1\n  q: 1\nd: 1\n",
+		"a:\n  x: 1\nc:\n  p: 2\n  q: 1\nd: 2\n",
+		80, 20)
+
+	// Fold the changed container by hand; navigation still reaches inside
+	m.cursor = 2 // $.c
+	m.toggleCurrent()
+	m.cursor = 0
+
+	for _, want := range []string{"$.c", "$.c.p", "$.d"} {
+		m.nextDiff()
+		if got := cursorPath(m); got != want {
+			t.Fatalf("expected next diff %s, got %s", want, got)
+		}
+	}
+	if m.collapsed[m.diffNodes[2]] {
+		t.Error("expected $.c to be unfolded to show $.c.p")
+	}
+	m.nextDiff()
+	if cursorPath(m) != "$.d" || m.statusMessage != "No more diffs below" {
+		t.Errorf("expected to stay on the last diff, got %s (%q)", cursorPath(m), m.statusMessage)
+	}
+
+	m.cursor = 2 // $.c
+	m.toggleCurrent()
+	m.cursor = len(m.diffNodes) - 1
+	for _, want := range []string{"$.c.p", "$.c", "$"} {
+		m.prevDiff()
+		if got := cursorPath(m); got != want {
+			t.Fatalf("expected previous diff %s, got %s", want, got)
+		}
+	}
+	m.prevDiff()
+	if m.statusMessage != "No more diffs above" {
+		t.Errorf("expected no diff above the root, got %q", m.statusMessage)
+	}
+}
+
+func TestUnifiedViewWideCharacters(t *testing.T) {
+	m := newTestModel(t,
+		"title: 日本語のタイトルはとても長いのでここで切れます\nicon: 🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉\n",
+		"title: 新しい日本語のタイトルもとても長いので切れます\nicon: 🚀🚀🚀🚀🚀🚀🚀🚀🚀🚀🚀🚀🚀🚀🚀🚀\n",
+		30, 20)
+	m.unified = true
+
+	// A modified scalar is a removed row followed by an added row
+	lines := m.unifiedLines()
+	if len(lines) != 5 || lines[1].node != lines[2].node || !strings.HasPrefix(lines[1].text, "- ") || !strings.HasPrefix(lines[2].text, "+ ") {
+		t.Fatalf("unexpected unified rows %+v", lines)
+	}
+
+	for _, cursor := range []int{0, 1} {
+		m.cursor = cursor
+		rows := strings.Split(strings.TrimSuffix(m.renderUnifiedView(), "\n"), "\n")
+		if len(rows) != m.viewportHeight() {
+			t.Errorf("expected %d rows, got %d", m.viewportHeight(), len(rows))
+		}
+		for _, row := range rows {
+			if w := ansi.StringWidth(row); w > m.width {
+				t.Errorf("row %q is %d cells wide, more than %d", ansi.Strip(row), w, m.width)
+			}
+		}
+		if !strings.Contains(rows[1], "…") {
+			t.Errorf("expected the long row to be cut off, got %q", ansi.Strip(rows[1]))
+		}
+	}
+}
+
+func TestDetailWrapsWideCharacters(t *testing.T) {
+	oldValue := "日本語のタイトルはとても長いので折り返します"
+	newValue := "🎉🚀🎉🚀🎉🚀🎉🚀🎉🚀🎉🚀🎉🚀"
+	m := newTestModel(t, "title: "+oldValue+"\n", "title: "+newValue+"\n", 20, 40)
+	m.showDetail = true
+	m.cursor = 1 // $.title
+
+	lines := m.detailLines()
+	var oldRows, newRows []string
+	rows := &oldRows
+	for _, line := range lines[2:] {
+		text := ansi.Strip(line)
+		if w := ansi.StringWidth(text); w > m.width {
+			t.Errorf("line %q is %d cells wide, more than %d", text, w, m.width)
+		}
+		if strings.HasPrefix(text, "new ") {
+			rows = &newRows
+			continue
+		}
+		*rows = append(*rows, strings.TrimPrefix(text, "  "))
+	}
+	if len(oldRows) < 2 || strings.Join(oldRows, "") != oldValue {
+		t.Errorf("expected the old value wrapped over several rows, got %q", oldRows)
+	}
+	if len(newRows) < 2 || strings.Join(newRows, "") != newValue {
+		t.Errorf("expected the new value wrapped over several rows, got %q", newRows)
+	}
+
+	for _, line := range strings.Split(m.renderDetail(), "\n") {
+		if w := ansi.StringWidth(line); w > m.width {
+			t.Errorf("panel line %q is %d cells wide, more than %d", ansi.Strip(line), w, m.width)
+		}
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		input string
+		width int
+		want  []string
+	}{
+		{"abcdef", 4, []string{"abcd", "ef"}},
+		{"日本語", 5, []string{"日本", "語"}},
+		{"🎉🎉🎉", 4, []string{"🎉🎉", "🎉"}},
+		{"a日本", 2, []string{"a", "日", "本"}},
+	}
+	for _, tt := range tests {
+		if got := wrapText(tt.input, tt.width); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("wrapText(%q, %d) = %q, want %q", tt.input, tt.width, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package diff
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/simota/yam/internal/diff"
+)
+
+// Independent panes: in the split view each side can be shown on its own,
+// listing only the nodes that exist on that side and scrolling separately.
+// The cursor moves in the focused pane while the other pane stays where it
+// is, so a long block added on one side can be read with the other side
+// kept in view.
+
+// paneNodes returns the positions in diffNodes of the nodes shown in the
+// left or right pane when the panes are unlinked
+func (m *Model) paneNodes(right bool) []int {
+	var nodes []int
+	for i, node := range m.diffNodes {
+		if right && node.Right != nil || !right && node.Left != nil {
+			nodes = append(nodes, i)
+		}
+	}
+	return nodes
+}
+
+// panePosition returns the row of the cursor in a pane, or of the first
+// node after it when the cursor node is not on that side
+func (m *Model) panePosition(nodes []int) int {
+	for pos, idx := range nodes {
+		if idx >= m.cursor {
+			return pos
+		}
+	}
+	return len(nodes) - 1
+}
+
+// paneOffset returns the scroll offset of a pane
+func (m *Model) paneOffset(right bool) *int {
+	if right {
+		return &m.rightOffset
+	}
+	return &m.leftOffset
+}
+
+// panesUnlinked reports whether the panes scroll on their own. The unified
+// view has a single column and moves the cursor as if they were linked.
+func (m Model) panesUnlinked() bool {
+	return m.unlinked && !m.unified
+}
+
+// toggleUnlinked unlinks or relinks the panes. Both panes start out
+// scrolled to the cursor.
+func (m *Model) toggleUnlinked() {
+	m.unlinked = !m.unlinked
+	if !m.unlinked {
+		m.adjustOffset()
+		return
+	}
+	for _, right := range []bool{false, true} {
+		*m.paneOffset(right) = 0
+		m.scrollPaneTo(right, m.panePosition(m.paneNodes(right)))
+	}
+	m.focusSide(m.focusRight)
+}
+
+// switchPane moves the focus to the other pane
+func (m *Model) switchPane() {
+	m.focusSide(!m.focusRight)
+}
+
+// focusSide focuses a pane and puts the cursor on a node shown in it
+func (m *Model) focusSide(right bool) {
+	m.focusRight = right
+	nodes := m.paneNodes(right)
+	if len(nodes) == 0 {
+		return
+	}
+	m.cursor = nodes[m.panePosition(nodes)]
+	m.adjustPaneOffset()
+}
+
+// movePaneCursor moves the cursor by delta rows of the focused pane
+func (m *Model) movePaneCursor(delta int) {
+	nodes := m.paneNodes(m.focusRight)
+	if len(nodes) == 0 {
+		return
+	}
+	pos := m.panePosition(nodes) + delta
+	if pos < 0 {
+		pos = 0
+	}
+	if pos >= len(nodes) {
+		pos = len(nodes) - 1
+	}
+	m.cursor = nodes[pos]
+	m.adjustPaneOffset()
+}
+
+// adjustPaneOffset scrolls the focused pane to keep the cursor visible,
+// focusing the other pane first when the cursor node is not on this side
+// (e.g. after jumping to a removal while the new side is focused)
+func (m *Model) adjustPaneOffset() {
+	if m.cursor < 0 || m.cursor >= len(m.diffNodes) {
+		return
+	}
+	node := m.diffNodes[m.cursor]
+	if m.focusRight && node.Right == nil || !m.focusRight && node.Left == nil {
+		m.focusRight = !m.focusRight
+	}
+	m.scrollPaneTo(m.focusRight, m.panePosition(m.paneNodes(m.focusRight)))
+}
+
+// scrollPaneTo scrolls a pane just enough to show row pos
+func (m *Model) scrollPaneTo(right bool, pos int) {
+	vh := m.viewportHeight()
+	offset := m.paneOffset(right)
+	if pos < *offset {
+		*offset = pos
+	}
+	if pos >= *offset+vh {
+		*offset = pos - vh + 1
+	}
+	if *offset < 0 {
+		*offset = 0
+	}
+}
+
+// renderPanes renders the split view with unlinked panes, each listing
+// the nodes of its own side from its own offset
+func (m Model) renderPanes() string {
+	vh := m.viewportHeight()
+	halfWidth := (m.width - 3) / 2 // -3 for separator
+	separatorStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#30363D")).
+		SetString(" │ ")
+
+	leftNodes, rightNodes := m.paneNodes(false), m.paneNodes(true)
+	var lines []string
+	for i := 0; i < vh; i++ {
+		left := m.renderPaneRow(leftNodes, m.leftOffset+i, false, halfWidth)
+		right := m.renderPaneRow(rightNodes, m.rightOffset+i, true, halfWidth)
+		lines = append(lines, left+separatorStyle.String()+right)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// renderPaneRow renders row pos of one pane, blank past the last node
+func (m Model) renderPaneRow(nodes []int, pos int, right bool, width int) string {
+	if pos >= len(nodes) {
+		return strings.Repeat(" ", width)
+	}
+	idx := nodes[pos]
+	cell := m.renderCell(m.diffNodes[idx], right, width)
+	if idx == m.cursor {
+		cursorStyle := lipgloss.NewStyle().Background(lipgloss.Color("#30363D"))
+		if right != m.focusRight {
+			cursorStyle = lipgloss.NewStyle().Background(lipgloss.Color("#21262D"))
+		}
+		cell = cursorStyle.Render(lipgloss.NewStyle().Width(width).Render(cell))
+	}
+	return cell
+}
+
+// renderCell renders one side of a node for the split view: the diff
+// prefix and the node, truncated and padded to width
+func (m Model) renderCell(node *diff.DiffNode, right bool, width int) string {
+	leftPrefix, rightPrefix := m.getDiffPrefixes(node.Type)
+	text := leftPrefix + m.renderNodeLeft(node, width)
+	if right {
+		text = rightPrefix + m.renderNodeRight(node, width)
+	}
+	text = ansi.Truncate(text, width, "…")
+	return padRight(diffStyle(node.Type).Render(text), width)
+}
@@ -0,0 +1,119 @@
+package ui
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/simota/yam/internal/parser"
+)
+
+// foldStateFile returns the file that remembers collapsed nodes per input
+// file, under the user config directory (e.g. ~/.config/yam/folds.json)
+func foldStateFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "yam", "folds.json"), nil
+}
+
+// loadFoldState reads the remembered state: collapsed node paths keyed by
+// absolute file path. A missing or unreadable file is an empty state.
+func loadFoldState() map[string][]string {
+	state := make(map[string][]string)
+	file, err := foldStateFile()
+	if err != nil {
+		return state
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return state
+	}
+	_ = json.Unmarshal(data, &state)
+	return state
+}
+
+// saveFoldState writes the collapsed node paths for key, dropping the
+// entry when nothing is collapsed
+func saveFoldState(key string, collapsed []string) error {
+	file, err := foldStateFile()
+	if err != nil {
+		return err
+	}
+
+	state := loadFoldState()
+	if len(collapsed) == 0 {
+		if _, ok := state[key]; !ok {
+			return nil
+		}
+		delete(state, key)
+	} else {
+		state[key] = collapsed
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(data, '\n'), 0o644)
+}
+
+// foldKey returns the key the fold state of filename is remembered under
+func foldKey(filename string) string {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return filename
+	}
+	return abs
+}
+
+// collapsedPaths returns the paths of collapsed containers under root
+func collapsedPaths(root *parser.YamNode) []string {
+	var paths []string
+	parser.Walk(root, func(n *parser.YamNode) bool {
+		if n.Collapsed && n.HasChildren() {
+			paths = append(paths, n.PathString())
+		}
+		return true
+	})
+	sort.Strings(paths)
+	return paths
+}
+
+// restoreFolds collapses the containers whose paths were remembered for
+// the model's file
+func (m *Model) restoreFolds() {
+	m.collapsePaths(loadFoldState()[foldKey(m.filename)])
+}
+
+// collapsePaths collapses the containers at paths
+func (m *Model) collapsePaths(paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+	collapsed := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		collapsed[p] = true
+	}
+	parser.Walk(m.root, func(n *parser.YamNode) bool {
+		if n.HasChildren() && collapsed[n.PathString()] {
+			n.Collapsed = true
+		}
+		return true
+	})
+	m.rebuildFlatList()
+}
+
+// saveFolds remembers the current fold state for the model's file. Errors
+// are ignored so that quitting never fails.
+func (m *Model) saveFolds() {
+	if !m.rememberFolds || m.root == nil {
+		return
+	}
+	_ = saveFoldState(foldKey(m.filename), collapsedPaths(m.root))
+}
@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/simota/yam/internal/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// nodeInfo describes the node under the cursor for the footer: its type
+// (inferred for scalars, with the size for containers), its tag when one
+// is written in the source, and where it starts in the source
+func nodeInfo(node *parser.YamNode) string {
+	var parts []string
+	switch node.Kind() {
+	case parser.KindMapping:
+		parts = append(parts, fmt.Sprintf("mapping, %s", plural(len(node.Children), "key")))
+	case parser.KindSequence:
+		parts = append(parts, fmt.Sprintf("sequence, %s", plural(len(node.Children), "item")))
+	case parser.KindAlias:
+		parts = append(parts, "alias of &"+node.Value())
+	case parser.KindScalar:
+		parts = append(parts, node.InferType().String())
+	}
+	if node.Raw != nil && node.Raw.Style&yaml.TaggedStyle != 0 {
+		parts = append(parts, node.Tag())
+	}
+	if line := node.Line(); line > 0 {
+		parts = append(parts, fmt.Sprintf("line %d, col %d", line, node.Column()))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// plural formats a count with a noun, adding "s" unless the count is 1
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -12,14 +12,29 @@ type KeyMap struct {
 	HalfDown    key.Binding
 	Top         key.Binding
 	Bottom      key.Binding
+	Parent      key.Binding
+	FirstChild  key.Binding
+	PrevSibling key.Binding
+	NextSibling key.Binding
 	Toggle      key.Binding
 	ExpandAll   key.Binding
 	CollapseAll key.Binding
+	ExpandTree  key.Binding
+	FoldTree    key.Binding
 	Search      key.Binding
+	Palette     key.Binding
 	NextMatch   key.Binding
 	PrevMatch   key.Binding
+	ClearSearch key.Binding
 	Edit        key.Binding
+	ToggleType  key.Binding
+	Reveal      key.Binding
+	ShowValue   key.Binding
+	CopyPath    key.Binding
+	CopyJQ      key.Binding
+	CopyPointer key.Binding
 	Save        key.Binding
+	Reload      key.Binding
 	Undo        key.Binding
 	Redo        key.Binding
 	Help        key.Binding
@@ -61,6 +76,22 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("G", "end"),
 			key.WithHelp("G", "go to bottom"),
 		),
+		Parent: key.NewBinding(
+			key.WithKeys("H"),
+			key.WithHelp("H", "parent"),
+		),
+		FirstChild: key.NewBinding(
+			key.WithKeys("L"),
+			key.WithHelp("L", "first child"),
+		),
+		PrevSibling: key.NewBinding(
+			key.WithKeys("{"),
+			key.WithHelp("{", "prev sibling"),
+		),
+		NextSibling: key.NewBinding(
+			key.WithKeys("}"),
+			key.WithHelp("}", "next sibling"),
+		),
 		Toggle: key.NewBinding(
 			key.WithKeys("enter", "o"),
 			key.WithHelp("Enter/o", "toggle fold"),
@@ -73,10 +104,22 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("C"),
 			key.WithHelp("C", "collapse all"),
 		),
+		ExpandTree: key.NewBinding(
+			key.WithKeys("*"),
+			key.WithHelp("*", "expand subtree"),
+		),
+		FoldTree: key.NewBinding(
+			key.WithKeys("-"),
+			key.WithHelp("-", "collapse subtree"),
+		),
 		Search: key.NewBinding(
 			key.WithKeys("/"),
 			key.WithHelp("/", "search"),
 		),
+		Palette: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "go to path"),
+		),
 		NextMatch: key.NewBinding(
 			key.WithKeys("n"),
 			key.WithHelp("n", "next match"),
@@ -85,14 +128,46 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("N"),
 			key.WithHelp("N", "prev match"),
 		),
+		ClearSearch: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("Esc", "clear search"),
+		),
 		Edit: key.NewBinding(
 			key.WithKeys("e"),
 			key.WithHelp("e", "edit"),
 		),
+		ToggleType: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "toggle string/typed"),
+		),
+		Reveal: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "reveal/hide binary"),
+		),
+		ShowValue: key.NewBinding(
+			key.WithKeys("v"),
+			key.WithHelp("v", "show full value"),
+		),
+		CopyPath: key.NewBinding(
+			key.WithKeys("y"),
+			key.WithHelp("y", "copy path"),
+		),
+		CopyJQ: key.NewBinding(
+			key.WithKeys("Y"),
+			key.WithHelp("Y", "copy jq path"),
+		),
+		CopyPointer: key.NewBinding(
+			key.WithKeys("P"),
+			key.WithHelp("P", "copy JSON Pointer"),
+		),
 		Save: key.NewBinding(
 			key.WithKeys("ctrl+s"),
 			key.WithHelp("Ctrl+S", "save"),
 		),
+		Reload: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "reload changed file"),
+		),
 		Undo: key.NewBinding(
 			key.WithKeys("u"),
 			key.WithHelp("u", "undo"),
@@ -122,9 +197,11 @@ func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.PageUp, k.PageDown},
 		{k.HalfUp, k.HalfDown, k.Top, k.Bottom},
-		{k.Toggle, k.ExpandAll, k.CollapseAll},
-		{k.Search, k.NextMatch, k.PrevMatch},
-		{k.Edit, k.Save, k.Undo, k.Redo},
+		{k.Parent, k.FirstChild, k.PrevSibling, k.NextSibling},
+		{k.Toggle, k.ExpandAll, k.CollapseAll, k.ExpandTree, k.FoldTree, k.Reveal, k.ShowValue},
+		{k.Search, k.NextMatch, k.PrevMatch, k.ClearSearch, k.Palette},
+		{k.Edit, k.ToggleType, k.Save, k.Reload, k.Undo, k.Redo},
+		{k.CopyPath, k.CopyJQ, k.CopyPointer},
 		{k.Help, k.Quit},
 	}
 }
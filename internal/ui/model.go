@@ -1,15 +1,22 @@
 package ui
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"strings"
+	"syscall"
 
+	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/simota/yam/internal/parser"
 	"github.com/simota/yam/internal/renderer"
 	"gopkg.in/yaml.v3"
@@ -23,6 +30,24 @@ type UndoEntry struct {
 	Node     *parser.YamNode
 	OldValue string
 	NewValue string
+	OldTag   string
+	NewTag   string
+	OldStyle yaml.Style
+	NewStyle yaml.Style
+}
+
+// apply sets the node's value, tag and style to the old or new state
+func (e UndoEntry) apply(old bool) {
+	if old {
+		e.Node.Raw.Value, e.Node.Raw.Tag, e.Node.Raw.Style = e.OldValue, e.OldTag, e.OldStyle
+	} else {
+		e.Node.Raw.Value, e.Node.Raw.Tag, e.Node.Raw.Style = e.NewValue, e.NewTag, e.NewStyle
+	}
+}
+
+// changed reports whether the node currently differs from the old state
+func (e UndoEntry) changed() bool {
+	return e.Node.Raw.Value != e.OldValue || e.Node.Raw.Tag != e.OldTag
 }
 
 // Model represents the TUI application state
@@ -36,6 +61,8 @@ type Model struct {
 	height    int
 	filename  string
 	renderer  *renderer.Renderer
+	lineCache *lineCache               // shared by copies of the model; see invalidate
+	revealed  map[*parser.YamNode]bool // binary values shown in full; shared with the renderer
 	keyMap    KeyMap
 	help      help.Model
 	showHelp  bool
@@ -46,6 +73,17 @@ type Model struct {
 	matches     []int // indices in flatNodes that match
 	matchIndex  int   // current position in matches
 
+	// Path palette state (see palette.go)
+	paletteMode    bool
+	paletteInput   textinput.Model
+	paletteItems   []paletteItem // every node path
+	paletteMatches []paletteItem // items matching the input, best first
+	paletteIndex   int           // selected position in paletteMatches
+
+	// Full value view state (see value.go)
+	valueNode   *parser.YamNode // scalar shown in full instead of the tree; nil when closed
+	valueOffset int             // first line of the value view shown
+
 	// Edit state
 	editMode      bool
 	editInput     textinput.Model
@@ -54,61 +92,245 @@ type Model struct {
 
 	// Dirty state
 	modified      bool
+	writeBlocked  string // why the file on disk cannot be saved; see fileWriteBlocked
 	modifiedNodes map[*parser.YamNode]bool
 	statusMessage string // temporary status message
 
 	// Undo/Redo state
 	undoStack []UndoEntry
 	redoStack []UndoEntry
+
+	// Start position (see WithStart), applied once the tree is loaded
+	startPath   string
+	startSearch string
+
+	// Fold persistence (see WithRememberedFolds)
+	rememberFolds bool
+
+	// Watch state (see watch.go)
+	changes       <-chan struct{} // signals changes to the file on disk; nil when not watching
+	reloadPending bool            // the file changed while there were unsaved edits
+	savedContent  []byte          // what the last save wrote, to ignore its own change event
+
+	// Loading state (see NewLoadingModel)
+	loading bool
+	load    LoadFunc
+	spinner spinner.Model
+	loadErr error
+}
+
+// loadedMsg carries the result of a background load
+type loadedMsg struct {
+	root *parser.YamNode
+	flat []*parser.YamNode
+	err  error
 }
 
 // NewModel creates a new TUI model
 func NewModel(root *parser.YamNode, filename string, treeStyle renderer.TreeStyle, showTypes bool) Model {
+	m := newModel(filename, treeStyle, showTypes)
+	m.setRoot(root, visibleNodes(root))
+	return m
+}
+
+// NewLoadingModel creates a TUI model that shows a spinner while load runs
+// in the background, so large inputs do not appear to hang
+func NewLoadingModel(load LoadFunc, filename string, treeStyle renderer.TreeStyle, showTypes bool) Model {
+	m := newModel(filename, treeStyle, showTypes)
+	m.loading = true
+	m.load = load
+	m.spinner = spinner.New(spinner.WithSpinner(spinner.Dot))
+	return m
+}
+
+func newModel(filename string, treeStyle renderer.TreeStyle, showTypes bool) Model {
 	opts := renderer.DefaultOptions()
 	opts.TreeStyle = treeStyle
 	opts.Interactive = true
 	opts.ShowTypes = showTypes
+	opts.Revealed = make(map[*parser.YamNode]bool)
 
 	searchTi := textinput.New()
-	searchTi.Placeholder = "search..."
+	searchTi.Placeholder = "search... (k: keys, v: values)"
 	searchTi.Prompt = "/"
 	searchTi.CharLimit = 100
 
+	paletteTi := textinput.New()
+	paletteTi.Placeholder = "type to fuzzy-match a path"
+	paletteTi.Prompt = "Go to: "
+	paletteTi.CharLimit = 200
+
 	editTi := textinput.New()
 	editTi.Placeholder = ""
 	editTi.Prompt = "Edit: "
 	editTi.CharLimit = 500
 
-	m := Model{
-		root:          root,
-		rawRoot:       root.Raw,
+	return Model{
 		filename:      filename,
 		renderer:      renderer.New(nil, opts),
+		revealed:      opts.Revealed,
+		lineCache:     newLineCache(),
 		keyMap:        DefaultKeyMap(),
 		help:          help.New(),
 		searchInput:   searchTi,
+		paletteInput:  paletteTi,
 		editInput:     editTi,
 		modifiedNodes: make(map[*parser.YamNode]bool),
 	}
-	m.rebuildFlatList()
+}
+
+// WithStart returns a copy of the model that opens with the cursor on the
+// node at path or, when path is empty, on the first match of query.
+// Collapsed ancestors are expanded.
+func (m Model) WithStart(path, query string) Model {
+	m.startPath = path
+	m.startSearch = query
+	if m.root != nil {
+		m.applyStart()
+	}
+	return m
+}
+
+// WithTheme returns a copy of the model that draws the tree with theme
+func (m Model) WithTheme(theme *renderer.Theme) Model {
+	m.renderer = m.renderer.WithTheme(theme)
+	m.lineCache = newLineCache()
+	return m
+}
+
+// WithMaxValueLength returns a copy of the model that cuts values longer
+// than n characters in the tree; the value view still shows them in full
+func (m Model) WithMaxValueLength(n int) Model {
+	m.renderer = m.renderer.WithMaxValueLength(n)
+	m.lineCache = newLineCache()
+	return m
+}
+
+// WithRememberedFolds returns a copy of the model that restores the fold
+// state last saved for its file and saves it again on quit
+func (m Model) WithRememberedFolds() Model {
+	m.rememberFolds = true
+	if m.root != nil {
+		m.restoreFolds()
+	}
 	return m
 }
 
+// applyStart moves the cursor to the start position set by WithStart
+func (m *Model) applyStart() {
+	switch {
+	case m.startPath != "":
+		node, err := parser.GetByPath(m.root, m.startPath)
+		if err != nil {
+			m.statusMessage = fmt.Sprintf("Cannot go to %s: %v", m.startPath, err)
+			return
+		}
+		m.expandAncestors(node)
+		m.rebuildFlatList()
+		m.moveToNode(node)
+
+	case m.startSearch != "":
+		m.searchInput.SetValue(m.startSearch)
+		m.search(m.startSearch)
+		if len(m.matches) > 0 {
+			m.cursor = m.matches[0]
+			m.adjustOffset()
+		}
+	}
+}
+
+// setRoot installs a loaded tree and its flattened visible nodes
+func (m *Model) setRoot(root *parser.YamNode, flat []*parser.YamNode) {
+	m.root = root
+	m.rawRoot = root.Raw
+	m.flatNodes = flat
+	m.writeBlocked = fileWriteBlocked(m.filename)
+	m.lineCache.invalidate()
+}
+
+// rebuildFlatList re-flattens the tree after its fold state changed
 func (m *Model) rebuildFlatList() {
-	m.flatNodes = parser.FlattenVisible(m.root)
-	// Skip document node if present
-	if len(m.flatNodes) > 0 && m.flatNodes[0].Kind() == parser.KindDocument {
-		m.flatNodes = m.flatNodes[1:]
+	m.flatNodes = visibleNodes(m.root)
+	m.lineCache.invalidate()
+}
+
+// visibleNodes flattens the visible nodes of a tree, skipping the document node
+func visibleNodes(root *parser.YamNode) []*parser.YamNode {
+	nodes := parser.FlattenVisible(root)
+	if len(nodes) > 0 && nodes[0].Kind() == parser.KindDocument {
+		nodes = nodes[1:]
 	}
+	return nodes
+}
+
+// loadCmd runs load off the UI goroutine, flattening the tree there too
+func loadCmd(load LoadFunc) tea.Cmd {
+	return func() tea.Msg {
+		root, err := load()
+		if err != nil {
+			return loadedMsg{err: err}
+		}
+		return loadedMsg{root: root, flat: visibleNodes(root)}
+	}
+}
+
+// updateLoading handles messages while the input is still loading
+func (m Model) updateLoading(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.help.Width = msg.Width
+
+	case tea.KeyMsg:
+		if key.Matches(msg, m.keyMap.Quit) {
+			// Abort; the background load is abandoned
+			return m, tea.Quit
+		}
+
+	case fileChangedMsg:
+		// The load in progress may miss the change; keep watching anyway
+		return m, waitForChange(m.changes)
+
+	case loadedMsg:
+		if msg.err != nil {
+			m.loadErr = msg.err
+			return m, tea.Quit
+		}
+		m.loading = false
+		m.setRoot(msg.root, msg.flat)
+		if m.rememberFolds {
+			m.restoreFolds()
+		}
+		m.applyStart()
+
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+	}
+
+	return m, nil
 }
 
 // Init implements tea.Model
 func (m Model) Init() tea.Cmd {
-	return nil
+	var cmds []tea.Cmd
+	if m.loading {
+		cmds = append(cmds, m.spinner.Tick, loadCmd(m.load))
+	}
+	if m.changes != nil {
+		cmds = append(cmds, waitForChange(m.changes))
+	}
+	return tea.Batch(cmds...)
 }
 
 // Update implements tea.Model
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.loading {
+		return m.updateLoading(msg)
+	}
+
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
@@ -116,6 +338,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 		m.help.Width = msg.Width
+		m.adjustOffset()
+
+	case fileChangedMsg:
+		return m, m.fileChanged()
+
+	case loadedMsg:
+		m.reload(msg)
 
 	case tea.KeyMsg:
 		// Clear status message on any key press
@@ -168,6 +397,45 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		// Path palette handling
+		if m.paletteMode {
+			switch msg.Type {
+			case tea.KeyEnter:
+				m.selectPaletteItem()
+				return m, nil
+			case tea.KeyEsc:
+				m.closePalette()
+				return m, nil
+			case tea.KeyUp, tea.KeyCtrlP:
+				m.movePalette(-1)
+				return m, nil
+			case tea.KeyDown, tea.KeyCtrlN:
+				m.movePalette(1)
+				return m, nil
+			default:
+				m.paletteInput, cmd = m.paletteInput.Update(msg)
+				m.filterPalette(m.paletteInput.Value())
+				return m, cmd
+			}
+		}
+
+		// Full value view handling
+		if m.valueNode != nil {
+			switch {
+			case msg.Type == tea.KeyEsc, key.Matches(msg, m.keyMap.ShowValue), key.Matches(msg, m.keyMap.Quit):
+				m.closeValue()
+			case key.Matches(msg, m.keyMap.Up):
+				m.scrollValue(-1)
+			case key.Matches(msg, m.keyMap.Down):
+				m.scrollValue(1)
+			case key.Matches(msg, m.keyMap.PageUp):
+				m.scrollValue(-m.viewportHeight())
+			case key.Matches(msg, m.keyMap.PageDown):
+				m.scrollValue(m.viewportHeight())
+			}
+			return m, nil
+		}
+
 		// Normal mode handling
 		switch {
 		case key.Matches(msg, m.keyMap.Quit):
@@ -177,6 +445,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.modified = false // Allow quit on next q press
 				return m, nil
 			}
+			m.saveFolds()
 			return m, tea.Quit
 
 		case key.Matches(msg, m.keyMap.Help):
@@ -188,9 +457,30 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, textinput.Blink
 			}
 
+		case key.Matches(msg, m.keyMap.ToggleType):
+			m.toggleType()
+
+		case key.Matches(msg, m.keyMap.Reveal):
+			m.toggleReveal()
+
+		case key.Matches(msg, m.keyMap.ShowValue):
+			m.openValue()
+
+		case key.Matches(msg, m.keyMap.CopyPath):
+			m.copyPath("path", (*parser.YamNode).PathString)
+
+		case key.Matches(msg, m.keyMap.CopyJQ):
+			m.copyPath("jq path", (*parser.YamNode).JQPath)
+
+		case key.Matches(msg, m.keyMap.CopyPointer):
+			m.copyPath("JSON Pointer", (*parser.YamNode).JSONPointer)
+
 		case key.Matches(msg, m.keyMap.Save):
 			m.saveFile()
 
+		case key.Matches(msg, m.keyMap.Reload):
+			return m, m.confirmReload()
+
 		case key.Matches(msg, m.keyMap.Undo):
 			m.undo()
 
@@ -202,12 +492,22 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.searchInput.Focus()
 			return m, textinput.Blink
 
+		case key.Matches(msg, m.keyMap.Palette):
+			m.openPalette()
+			return m, textinput.Blink
+
 		case key.Matches(msg, m.keyMap.NextMatch):
 			m.nextMatch()
 
 		case key.Matches(msg, m.keyMap.PrevMatch):
 			m.prevMatch()
 
+		case key.Matches(msg, m.keyMap.ClearSearch):
+			if m.searchInput.Value() != "" {
+				m.clearSearch()
+				m.statusMessage = "Search cleared"
+			}
+
 		case key.Matches(msg, m.keyMap.Up):
 			m.moveCursor(-1)
 
@@ -234,6 +534,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.cursor = len(m.flatNodes) - 1
 			m.adjustOffset()
 
+		case key.Matches(msg, m.keyMap.Parent):
+			m.jumpToParent()
+
+		case key.Matches(msg, m.keyMap.FirstChild):
+			m.jumpToFirstChild()
+
+		case key.Matches(msg, m.keyMap.PrevSibling):
+			m.jumpToSibling(-1)
+
+		case key.Matches(msg, m.keyMap.NextSibling):
+			m.jumpToSibling(1)
+
 		case key.Matches(msg, m.keyMap.Toggle):
 			m.toggleCurrent()
 
@@ -242,6 +554,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case key.Matches(msg, m.keyMap.CollapseAll):
 			m.collapseAll()
+
+		case key.Matches(msg, m.keyMap.ExpandTree):
+			m.expandSubtree()
+
+		case key.Matches(msg, m.keyMap.FoldTree):
+			m.collapseSubtree()
 		}
 	}
 
@@ -269,6 +587,66 @@ func (m *Model) adjustOffset() {
 	}
 }
 
+// moveToNode places the cursor on node if it is visible
+func (m *Model) moveToNode(node *parser.YamNode) bool {
+	for i, n := range m.flatNodes {
+		if n == node {
+			m.cursor = i
+			m.adjustOffset()
+			return true
+		}
+	}
+	return false
+}
+
+// jumpToParent moves the cursor to the current node's parent
+func (m *Model) jumpToParent() {
+	if m.cursor < 0 || m.cursor >= len(m.flatNodes) {
+		return
+	}
+	if parent := m.flatNodes[m.cursor].Parent; parent != nil {
+		m.moveToNode(parent)
+	}
+}
+
+// jumpToFirstChild moves the cursor to the current node's first child,
+// expanding the node if it is collapsed
+func (m *Model) jumpToFirstChild() {
+	if m.cursor < 0 || m.cursor >= len(m.flatNodes) {
+		return
+	}
+	node := m.flatNodes[m.cursor]
+	if !node.HasChildren() {
+		return
+	}
+	if node.Collapsed {
+		node.Collapsed = false
+		m.rebuildFlatList()
+	}
+	m.moveToNode(node.Children[0])
+}
+
+// jumpToSibling moves the cursor delta siblings away from the current node
+func (m *Model) jumpToSibling(delta int) {
+	if m.cursor < 0 || m.cursor >= len(m.flatNodes) {
+		return
+	}
+	node := m.flatNodes[m.cursor]
+	if node.Parent == nil {
+		return
+	}
+	siblings := node.Parent.Children
+	for i, sibling := range siblings {
+		if sibling != node {
+			continue
+		}
+		if j := i + delta; j >= 0 && j < len(siblings) {
+			m.moveToNode(siblings[j])
+		}
+		return
+	}
+}
+
 func (m *Model) viewportHeight() int {
 	return m.height - 4 // header + footer + help
 }
@@ -310,10 +688,75 @@ func (m *Model) collapseAll() {
 	m.offset = 0
 }
 
+// expandSubtree expands the container at the cursor and every container
+// below it, leaving the rest of the tree as it is
+func (m *Model) expandSubtree() {
+	if m.cursor < 0 || m.cursor >= len(m.flatNodes) {
+		return
+	}
+	node := m.flatNodes[m.cursor]
+	if !node.IsContainer() || !node.HasChildren() {
+		return
+	}
+	parser.Walk(node, func(n *parser.YamNode) bool {
+		n.Collapsed = false
+		return true
+	})
+	m.rebuildFlatList()
+	m.adjustOffset()
+}
+
+// collapseSubtree collapses the container at the cursor and every
+// container below it, so expanding it again shows its children folded.
+// On a scalar it collapses the enclosing container and moves there.
+func (m *Model) collapseSubtree() {
+	if m.cursor < 0 || m.cursor >= len(m.flatNodes) {
+		return
+	}
+	node := m.flatNodes[m.cursor]
+	if !node.IsContainer() || !node.HasChildren() {
+		node = node.Parent
+	}
+	if node == nil || node.Depth == 0 {
+		return // The root stays expanded, as with collapse all
+	}
+	parser.Walk(node, func(n *parser.YamNode) bool {
+		if n.IsContainer() && n.HasChildren() {
+			n.Collapsed = true
+		}
+		return true
+	})
+	m.rebuildFlatList()
+	m.moveToNode(node)
+}
+
+// searchScope limits which part of a node a search query is matched against
+type searchScope int
+
+const (
+	scopeAll searchScope = iota
+	scopeKeys
+	scopeValues
+)
+
+// parseSearchQuery splits an optional scope prefix from a query:
+// "k:" matches keys only, "v:" matches values only.
+func parseSearchQuery(query string) (searchScope, string) {
+	switch {
+	case strings.HasPrefix(query, "k:"):
+		return scopeKeys, query[2:]
+	case strings.HasPrefix(query, "v:"):
+		return scopeValues, query[2:]
+	}
+	return scopeAll, query
+}
+
 // search searches all nodes (including collapsed) and auto-expands parents of matches
 func (m *Model) search(query string) {
 	m.matches = nil
 	m.matchIndex = 0
+	scope, query := parseSearchQuery(query)
+	m.setHighlight(query, scope)
 	if query == "" {
 		return
 	}
@@ -326,12 +769,12 @@ func (m *Model) search(query string) {
 			return true
 		}
 		// Search in key
-		if strings.Contains(strings.ToLower(node.Key), query) {
+		if scope != scopeValues && strings.Contains(strings.ToLower(node.Key), query) {
 			matchedNodes = append(matchedNodes, node)
 			return true
 		}
 		// Search in value
-		if strings.Contains(strings.ToLower(node.Value()), query) {
+		if scope != scopeKeys && strings.Contains(strings.ToLower(node.Value()), query) {
 			matchedNodes = append(matchedNodes, node)
 		}
 		return true
@@ -356,6 +799,13 @@ func (m *Model) search(query string) {
 	}
 }
 
+// setHighlight highlights the occurrences of query within the keys and
+// values in scope on the tree lines
+func (m *Model) setHighlight(query string, scope searchScope) {
+	m.renderer = m.renderer.WithHighlight(query, scope != scopeValues, scope != scopeKeys)
+	m.lineCache.invalidate()
+}
+
 // expandAncestors expands all ancestors of a node
 func (m *Model) expandAncestors(node *parser.YamNode) {
 	for p := node.Parent; p != nil; p = p.Parent {
@@ -368,7 +818,11 @@ func (m *Model) nextMatch() {
 	if len(m.matches) == 0 {
 		return
 	}
-	m.matchIndex = (m.matchIndex + 1) % len(m.matches)
+	m.matchIndex++
+	if m.matchIndex >= len(m.matches) {
+		m.matchIndex = 0
+		m.statusMessage = "Search wrapped to top"
+	}
 	m.cursor = m.matches[m.matchIndex]
 	m.adjustOffset()
 }
@@ -381,6 +835,7 @@ func (m *Model) prevMatch() {
 	m.matchIndex--
 	if m.matchIndex < 0 {
 		m.matchIndex = len(m.matches) - 1
+		m.statusMessage = "Search wrapped to bottom"
 	}
 	m.cursor = m.matches[m.matchIndex]
 	m.adjustOffset()
@@ -396,11 +851,23 @@ func (m *Model) isMatchIndex(idx int) bool {
 	return false
 }
 
+// matchStatus describes the active search for the footer
+func (m *Model) matchStatus() string {
+	if len(m.matches) > 0 {
+		return fmt.Sprintf("  [match %d/%d]", m.matchIndex+1, len(m.matches))
+	}
+	if m.searchInput.Value() != "" {
+		return "  [no matches]"
+	}
+	return ""
+}
+
 // clearSearch clears search state
 func (m *Model) clearSearch() {
 	m.matches = nil
 	m.matchIndex = 0
 	m.searchInput.SetValue("")
+	m.setHighlight("", scopeAll)
 }
 
 // startEdit starts editing the current node if it's a scalar value
@@ -417,9 +884,9 @@ func (m *Model) startEdit() {
 		return
 	}
 
-	// Check if file is from stdin
-	if m.filename == "stdin" || m.filename == "-" {
-		m.statusMessage = "Cannot edit: read-only (stdin)"
+	// Check if input can be written back
+	if source := m.readOnlySource(); source != "" {
+		m.statusMessage = "Cannot edit: read-only (" + source + ")"
 		return
 	}
 
@@ -431,6 +898,94 @@ func (m *Model) startEdit() {
 	m.editInput.CursorEnd()
 }
 
+// toggleType switches a scalar between a string and the type its value
+// resolves to when unquoted (e.g. "3" and 3), by setting an explicit tag
+func (m *Model) toggleType() {
+	if m.cursor < 0 || m.cursor >= len(m.flatNodes) {
+		return
+	}
+	node := m.flatNodes[m.cursor]
+
+	if !m.isEditable(node) {
+		m.statusMessage = "Cannot change type: not a scalar value"
+		return
+	}
+	if source := m.readOnlySource(); source != "" {
+		m.statusMessage = "Cannot change type: read-only (" + source + ")"
+		return
+	}
+
+	raw := node.Raw
+	typed := parser.PlainTag(raw.Value)
+	if typed == "!!str" {
+		m.statusMessage = "Cannot change type: value is only valid as a string"
+		return
+	}
+
+	newTag := typed
+	if raw.Tag == typed {
+		newTag = "!!str"
+	}
+
+	// Plain style lets the encoder quote strings and leave typed values bare
+	entry := UndoEntry{
+		Node:     node,
+		OldValue: raw.Value,
+		NewValue: raw.Value,
+		OldTag:   raw.Tag,
+		NewTag:   newTag,
+		OldStyle: raw.Style,
+		NewStyle: 0,
+	}
+	m.pushUndo(entry)
+	entry.apply(false)
+	m.lineCache.invalidate()
+
+	m.modified = true
+	m.modifiedNodes[node] = true
+	m.statusMessage = "Type: " + newTag
+}
+
+// toggleReveal shows the current binary value in full, or summarizes it
+// again
+func (m *Model) toggleReveal() {
+	if m.cursor < 0 || m.cursor >= len(m.flatNodes) {
+		return
+	}
+	node := m.flatNodes[m.cursor]
+	if _, ok := parser.BinaryLabel(node); !ok {
+		m.statusMessage = "Not a binary value"
+		return
+	}
+	if m.revealed[node] {
+		delete(m.revealed, node)
+	} else {
+		m.revealed[node] = true
+	}
+	m.lineCache.invalidate()
+}
+
+// writeClipboard copies text to the system clipboard
+var writeClipboard = clipboard.WriteAll
+
+// copyPath copies the current node's path, formatted by format, to the
+// clipboard and reports it in the status line
+func (m *Model) copyPath(name string, format func(*parser.YamNode) string) {
+	if m.cursor < 0 || m.cursor >= len(m.flatNodes) {
+		return
+	}
+	path := format(m.flatNodes[m.cursor])
+	if err := writeClipboard(path); err != nil {
+		m.statusMessage = "Copy failed: " + err.Error()
+		return
+	}
+	shown := path
+	if shown == "" {
+		shown = `""` // JSON Pointer of the root
+	}
+	m.statusMessage = fmt.Sprintf("Copied %s: %s", name, shown)
+}
+
 // isEditable checks if a node can be edited (scalar values only)
 func (m *Model) isEditable(node *parser.YamNode) bool {
 	if node == nil || node.Raw == nil {
@@ -451,15 +1006,21 @@ func (m *Model) confirmEdit() {
 	// Only mark as modified if value actually changed
 	if newValue != m.originalValue {
 		// Push to undo stack before modifying
+		raw := m.editNode.Raw
 		entry := UndoEntry{
 			Node:     m.editNode,
 			OldValue: m.originalValue,
 			NewValue: newValue,
+			OldTag:   raw.Tag,
+			NewTag:   raw.Tag,
+			OldStyle: raw.Style,
+			NewStyle: raw.Style,
 		}
 		m.pushUndo(entry)
 
 		// Update the yaml.Node value
 		m.editNode.Raw.Value = newValue
+		m.lineCache.invalidate()
 
 		// Mark as modified
 		m.modified = true
@@ -473,11 +1034,48 @@ func (m *Model) confirmEdit() {
 	m.originalValue = ""
 }
 
+// readOnlySource names why the input cannot be saved back ("stdin", "URL"
+// or the reason from fileWriteBlocked), or returns "" for a writable file
+func (m *Model) readOnlySource() string {
+	switch {
+	case m.filename == "stdin" || m.filename == "-":
+		return "stdin"
+	case strings.HasPrefix(m.filename, "http://") || strings.HasPrefix(m.filename, "https://"):
+		return "URL"
+	}
+	return m.writeBlocked
+}
+
+// fileWriteBlocked returns why filename cannot be written ("not a regular
+// file" or "no write permission"), or "" when it can. Write permission is
+// checked by opening the file for writing without truncating it, so
+// ownership, ACLs and read-only mounts count. A file that does not exist
+// can be created on save.
+func fileWriteBlocked(filename string) string {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return ""
+	}
+	if !info.Mode().IsRegular() {
+		// Pipes such as <(cmd) would block on open, and cannot be saved
+		return "not a regular file"
+	}
+	f, err := os.OpenFile(filename, os.O_WRONLY, 0)
+	if err != nil {
+		if errors.Is(err, fs.ErrPermission) || errors.Is(err, syscall.EROFS) {
+			return "no write permission"
+		}
+		return ""
+	}
+	f.Close()
+	return ""
+}
+
 // saveFile saves the modified YAML to the original file
 func (m *Model) saveFile() {
-	// Check if file is from stdin
-	if m.filename == "stdin" || m.filename == "-" {
-		m.statusMessage = "Cannot save: read-only (stdin)"
+	// Check if input can be written back
+	if source := m.readOnlySource(); source != "" {
+		m.statusMessage = "Cannot save: read-only (" + source + ")"
 		return
 	}
 
@@ -486,23 +1084,25 @@ func (m *Model) saveFile() {
 		return
 	}
 
-	// Open file for writing
-	file, err := os.Create(m.filename)
-	if err != nil {
+	// Encode with yaml.v3
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(m.rawRoot); err != nil {
+		m.statusMessage = fmt.Sprintf("Error: %v", err)
+		return
+	}
+	if err := encoder.Close(); err != nil {
 		m.statusMessage = fmt.Sprintf("Error: %v", err)
 		return
 	}
-	defer file.Close()
-
-	// Encode with yaml.v3
-	encoder := yaml.NewEncoder(file)
-	encoder.SetIndent(2)
-	defer encoder.Close()
 
-	if err := encoder.Encode(m.rawRoot); err != nil {
+	if err := os.WriteFile(m.filename, buf.Bytes(), 0o666); err != nil {
 		m.statusMessage = fmt.Sprintf("Error: %v", err)
 		return
 	}
+	// A watched file reports this write as a change; it is not reloaded
+	m.savedContent = buf.Bytes()
 
 	// Clear modified state
 	m.modified = false
@@ -537,7 +1137,8 @@ func (m *Model) undo() {
 	m.undoStack = m.undoStack[:len(m.undoStack)-1]
 
 	// Restore old value
-	entry.Node.Raw.Value = entry.OldValue
+	entry.apply(true)
+	m.lineCache.invalidate()
 
 	// Push to redo stack
 	m.redoStack = append(m.redoStack, entry)
@@ -560,7 +1161,8 @@ func (m *Model) redo() {
 	m.redoStack = m.redoStack[:len(m.redoStack)-1]
 
 	// Re-apply new value
-	entry.Node.Raw.Value = entry.NewValue
+	entry.apply(false)
+	m.lineCache.invalidate()
 
 	// Push back to undo stack
 	m.undoStack = append(m.undoStack, entry)
@@ -579,15 +1181,23 @@ func (m *Model) updateModifiedState() {
 	m.modifiedNodes = make(map[*parser.YamNode]bool)
 	for _, entry := range m.undoStack {
 		// Node is modified if current value differs from original
-		if entry.Node.Raw.Value != entry.OldValue {
+		if entry.changed() {
 			m.modifiedNodes[entry.Node] = true
 		}
 	}
 	m.modified = len(m.modifiedNodes) > 0
 }
 
+// renderLine renders the tree line of a single node
+func (m Model) renderLine(node *parser.YamNode) string {
+	return m.renderer.RenderLine(m.root, node)
+}
+
 // View implements tea.Model
 func (m Model) View() string {
+	if m.loading {
+		return fmt.Sprintf("\n  %s Loading %s... (Ctrl+C to abort)\n", m.spinner.View(), m.filename)
+	}
 	if m.width == 0 {
 		return "Loading..."
 	}
@@ -606,7 +1216,7 @@ func (m Model) View() string {
 	if m.modified || len(m.modifiedNodes) > 0 {
 		headerText += " [modified]"
 	}
-	if m.filename == "stdin" || m.filename == "-" {
+	if m.readOnlySource() != "" {
 		headerText += " [read-only]"
 	}
 	b.WriteString(headerStyle.Render(headerText))
@@ -623,18 +1233,41 @@ func (m Model) View() string {
 		Background(lipgloss.Color("#3D2800")).
 		Width(m.width)
 
-	// Content
+	// Content: only the lines inside the viewport are rendered
 	vh := m.viewportHeight()
-	contentLines := m.renderContent()
 
 	// Pad or truncate to viewport height
+	var paletteLines []string
+	paletteSelected := -1
+	if m.paletteMode {
+		// The palette replaces the tree while it is open
+		paletteLines, paletteSelected = m.paletteLines(vh)
+	}
+	var valueLines []string
+	if m.valueNode != nil {
+		// So does the value view
+		valueLines = m.valueLines()
+	}
 	for i := 0; i < vh; i++ {
 		idx := m.offset + i
-		if idx < len(contentLines) {
-			line := contentLines[idx]
+		switch {
+		case m.valueNode != nil:
+			if j := m.valueOffset + i; j < len(valueLines) {
+				b.WriteString(valueLines[j])
+			}
+		case m.paletteMode:
+			if i < len(paletteLines) {
+				line := paletteLines[i]
+				if i == paletteSelected {
+					line = cursorStyle.Render(line)
+				}
+				b.WriteString(line)
+			}
+		case idx < len(m.flatNodes):
+			line := m.lineCache.line(m.flatNodes[idx], m.renderLine)
 			isMatch := m.isMatchIndex(idx)
 			isCursor := idx == m.cursor
-			isModified := idx < len(m.flatNodes) && m.isModifiedNode(m.flatNodes[idx])
+			isModified := m.isModifiedNode(m.flatNodes[idx])
 
 			// Apply styles: cursor takes priority, then modified, then match
 			if isCursor {
@@ -656,7 +1289,11 @@ func (m Model) View() string {
 		Padding(0, 1).
 		Width(m.width)
 
-	if m.editMode {
+	if m.valueNode != nil {
+		b.WriteString(footerStyle.Render(m.valueStatus()))
+	} else if m.paletteMode {
+		b.WriteString(footerStyle.Render(m.paletteInput.View() + m.paletteStatus()))
+	} else if m.editMode {
 		// Edit input display
 		editLine := m.editInput.View() + "  [Enter: confirm, Esc: cancel]"
 		b.WriteString(footerStyle.Render(editLine))
@@ -671,23 +1308,28 @@ func (m Model) View() string {
 		b.WriteString(footerStyle.Render(searchLine))
 	} else if m.statusMessage != "" {
 		// Status message display
-		b.WriteString(footerStyle.Render(m.statusMessage))
+		b.WriteString(footerStyle.Render(m.statusMessage + m.matchStatus()))
 	} else {
 		// Normal footer
 		position := fmt.Sprintf("%d/%d", m.cursor+1, len(m.flatNodes))
+		var info string
 		if m.cursor >= 0 && m.cursor < len(m.flatNodes) {
 			node := m.flatNodes[m.cursor]
 			position += " | " + node.PathString()
+			info = nodeInfo(node)
 		}
-		// Show match info if matches exist
-		if len(m.matches) > 0 {
-			position += fmt.Sprintf("  [match %d/%d]", m.matchIndex+1, len(m.matches))
-		}
+		// Show match info until the search is cleared
+		suffix := m.matchStatus()
 		// Show modified indicator with save hint
 		if m.modified || len(m.modifiedNodes) > 0 {
-			position += "  [modified - Ctrl+S to save]"
+			suffix += "  [modified - Ctrl+S to save]"
 		}
-		b.WriteString(footerStyle.Render(position))
+		// Describe the node in the room left on the line (the padding
+		// takes two columns)
+		if room := m.width - 2 - ansi.StringWidth(position+suffix) - len(" | "); info != "" && room > 0 {
+			position += " | " + ansi.Truncate(info, room, "…")
+		}
+		b.WriteString(footerStyle.Render(position + suffix))
 	}
 	b.WriteString("\n")
 
@@ -700,13 +1342,3 @@ func (m Model) View() string {
 
 	return b.String()
 }
-
-func (m Model) renderContent() []string {
-	output := m.renderer.RenderVisible(m.root)
-	lines := strings.Split(output, "\n")
-	// Remove empty last line
-	if len(lines) > 0 && lines[len(lines)-1] == "" {
-		lines = lines[:len(lines)-1]
-	}
-	return lines
-}
@@ -0,0 +1,557 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/simota/yam/internal/parser"
+	"github.com/simota/yam/internal/renderer"
+	"gopkg.in/yaml.v3"
+)
+
+// benchModel returns a model for a large document sized like a terminal
+func benchModel(b *testing.B) Model {
+	b.Helper()
+	var sb strings.Builder
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&sb, "service%d:\n  image: registry.example.com/app:%d\n  ports: [80, 443]\n  env:\n    LOG_LEVEL: debug\n", i, i)
+	}
+	root, err := parser.New().Parse(strings.NewReader(sb.String()))
+	if err != nil {
+		b.Fatalf("Parse failed: %v", err)
+	}
+
+	updated, _ := NewModel(root, "bench.yaml", renderer.TreeStyleUnicode, true).
+		Update(tea.WindowSizeMsg{Width: 120, Height: 60})
+	return updated.(Model)
+}
+
+// BenchmarkViewCursorMove measures a frame after a cursor move, which
+// reuses the cached lines
+func BenchmarkViewCursorMove(b *testing.B) {
+	m := benchModel(b)
+	m.View()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.moveCursor(1)
+		m.View()
+	}
+}
+
+// BenchmarkViewUncached measures the same frame with every line re-rendered
+func BenchmarkViewUncached(b *testing.B) {
+	m := benchModel(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m.moveCursor(1)
+		m.lineCache.invalidate()
+		m.View()
+	}
+}
+
+func TestViewInvalidatesCachedLinesOnFold(t *testing.T) {
+	root, err := parser.New().Parse(strings.NewReader("a:\n  b: 1\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	updated, _ := NewModel(root, "test.yaml", renderer.TreeStyleUnicode, false).
+		Update(tea.WindowSizeMsg{Width: 80, Height: 10})
+	m := updated.(Model)
+
+	m.View()
+	node := m.flatNodes[1] // $.a
+	unfolded := m.lineCache.lines[node]
+
+	m.cursor = 1
+	m.toggleCurrent()
+	m.View()
+
+	folded := m.lineCache.lines[node]
+	if folded == unfolded || folded != m.renderLine(node) {
+		t.Errorf("expected the cached line to be re-rendered after folding, got %q (was %q)", folded, unfolded)
+	}
+}
+
+func TestToggleType(t *testing.T) {
+	root, err := parser.New().Parse(strings.NewReader("port: '3'\nname: app\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	m := NewModel(root, "test.yaml", renderer.TreeStyleUnicode, false)
+	m.cursor = 1 // $.port
+	port := m.flatNodes[1]
+
+	m.toggleType()
+	if port.Raw.Tag != "!!int" || port.InferType() != parser.TypeNumber || !m.isModifiedNode(port) {
+		t.Fatalf("expected port to become an int, got tag %s", port.Raw.Tag)
+	}
+
+	m.toggleType()
+	if port.Raw.Tag != "!!str" {
+		t.Errorf("expected port to become a string again, got tag %s", port.Raw.Tag)
+	}
+
+	m.undo()
+	m.undo()
+	if port.Raw.Tag != "!!str" || port.Raw.Style != yaml.SingleQuotedStyle || m.isModifiedNode(port) {
+		t.Errorf("expected undo to restore the quoted string, got tag %s style %v", port.Raw.Tag, port.Raw.Style)
+	}
+
+	m.cursor = 2 // $.name
+	m.toggleType()
+	if name := m.flatNodes[2]; name.Raw.Tag != "!!str" || m.isModifiedNode(name) {
+		t.Errorf("expected a plain string to stay a string")
+	}
+}
+
+func TestWithStart(t *testing.T) {
+	input := "spec:\n  containers:\n    - name: web\n      image: nginx\nimage: base\n"
+
+	tests := []struct {
+		name     string
+		path     string
+		query    string
+		expected string
+	}{
+		{"goto", ".spec.containers[0].image", "", "$.spec.containers.0.image"},
+		{"search", "", "nginx", "$.spec.containers.0.image"},
+		{"search keys", "", "k:image", "$.spec.containers.0.image"},
+		{"missing path", ".nope", "", "$"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := parser.New().Parse(strings.NewReader(input))
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			m := NewModel(root, "test.yaml", renderer.TreeStyleUnicode, false)
+			m.collapseAll()
+
+			m = m.WithStart(tt.path, tt.query)
+			if got := m.flatNodes[m.cursor].PathString(); got != tt.expected {
+				t.Errorf("expected cursor on %s, got %s", tt.expected, got)
+			}
+			if failed := m.statusMessage != ""; failed != (tt.name == "missing path") {
+				t.Errorf("unexpected status message %q", m.statusMessage)
+			}
+		})
+	}
+}
+
+func TestCopyPath(t *testing.T) {
+	var copied string
+	defer func(orig func(string) error) { writeClipboard = orig }(writeClipboard)
+	writeClipboard = func(s string) error {
+		copied = s
+		return nil
+	}
+
+	root, err := parser.New().Parse(strings.NewReader("a:\n  b: [x, y]\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	m := NewModel(root, "test.yaml", renderer.TreeStyleUnicode, false).WithStart(".a.b[1]", "")
+
+	tests := []struct {
+		key      string
+		expected string
+		status   string
+	}{
+		{"y", "$.a.b.1", "Copied path: $.a.b.1"},
+		{"Y", ".a.b[1]", "Copied jq path: .a.b[1]"},
+		{"P", "/a/b/1", "Copied JSON Pointer: /a/b/1"},
+	}
+
+	for _, tt := range tests {
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(tt.key)})
+		got := updated.(Model)
+		if copied != tt.expected {
+			t.Errorf("%s: expected %q copied, got %q", tt.key, tt.expected, copied)
+		}
+		if got.statusMessage != tt.status {
+			t.Errorf("%s: expected status %q, got %q", tt.key, tt.status, got.statusMessage)
+		}
+	}
+}
+
+func TestRememberedFolds(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	open := func() Model {
+		root, err := parser.New().Parse(strings.NewReader("a:\n  b: 1\nc:\n  d: [1, 2]\n"))
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		return NewModel(root, "values.yaml", renderer.TreeStyleUnicode, false).WithRememberedFolds()
+	}
+
+	m := open()
+	m.cursor = 3 // $.c
+	m.toggleCurrent()
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	if got := collapsedPaths(updated.(Model).root); len(got) != 1 || got[0] != "$.c" {
+		t.Fatalf("expected $.c collapsed, got %v", got)
+	}
+
+	m = open()
+	if got := collapsedPaths(m.root); len(got) != 1 || got[0] != "$.c" {
+		t.Errorf("expected $.c to be restored, got %v", got)
+	}
+	if len(m.flatNodes) != 4 {
+		t.Errorf("expected 4 visible nodes, got %d", len(m.flatNodes))
+	}
+
+	// Expanding everything forgets the file
+	m.expandAll()
+	m.saveFolds()
+	if state := loadFoldState(); len(state) != 0 {
+		t.Errorf("expected empty state, got %v", state)
+	}
+}
+
+func TestToggleReveal(t *testing.T) {
+	blob := strings.Repeat("QUJDRGVmZ2gxMjM0", 8)
+	root, err := parser.New().Parse(strings.NewReader("data: " + blob + "\nname: app\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	m := NewModel(root, "test.yaml", renderer.TreeStyleUnicode, false)
+	data := m.flatNodes[1]
+
+	m.cursor = 1
+	if line := m.lineCache.line(data, m.renderLine); !strings.Contains(line, "<binary 96 bytes>") {
+		t.Fatalf("expected binary placeholder, got %q", line)
+	}
+
+	m.toggleReveal()
+	if line := m.lineCache.line(data, m.renderLine); !strings.Contains(line, blob) {
+		t.Errorf("expected full value after reveal, got %q", line)
+	}
+
+	m.toggleReveal()
+	if line := m.lineCache.line(data, m.renderLine); strings.Contains(line, blob) {
+		t.Errorf("expected placeholder after hiding, got %q", line)
+	}
+
+	m.cursor = 2
+	m.toggleReveal()
+	if m.statusMessage != "Not a binary value" {
+		t.Errorf("unexpected status %q", m.statusMessage)
+	}
+}
+
+func TestPalette(t *testing.T) {
+	root, err := parser.New().Parse(strings.NewReader(
+		"spec:\n  containers:\n    - name: app\n      image: nginx\nstatus:\n  containerStatuses: []\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	updated, _ := NewModel(root, "test.yaml", renderer.TreeStyleUnicode, false).
+		Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+	m := updated.(Model)
+	m.collapseAll()
+
+	press := func(msg tea.KeyMsg) {
+		updated, _ := m.Update(msg)
+		m = updated.(Model)
+	}
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	if !m.paletteMode {
+		t.Fatal("expected p to open the palette")
+	}
+	for _, r := range "conimg" {
+		press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if len(m.paletteMatches) == 0 || m.paletteMatches[0].path != "$.spec.containers.0.image" {
+		t.Fatalf("expected the image path first, got %+v", m.paletteMatches)
+	}
+	if !strings.Contains(m.View(), "$.spec.containers.0.image") {
+		t.Error("expected the palette to list the match")
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.paletteMode {
+		t.Fatal("expected Enter to close the palette")
+	}
+	if got := m.flatNodes[m.cursor].PathString(); got != "$.spec.containers.0.image" {
+		t.Errorf("expected the cursor on the selected node, got %s", got)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("zzz")})
+	if len(m.paletteMatches) != 0 {
+		t.Errorf("expected no matches, got %+v", m.paletteMatches)
+	}
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.paletteMode || m.flatNodes[m.cursor].PathString() != "$.spec.containers.0.image" {
+		t.Error("expected Esc to close the palette without moving the cursor")
+	}
+}
+
+func TestFuzzyScore(t *testing.T) {
+	if _, ok := fuzzyScore("sci", "$.spec.containers.0.image"); !ok {
+		t.Error("expected a subsequence to match")
+	}
+	if _, ok := fuzzyScore("ics", "$.spec.containers.0.image"); ok {
+		t.Error("expected out-of-order runes not to match")
+	}
+	boundary, _ := fuzzyScore("image", "$.spec.image")
+	inner, _ := fuzzyScore("image", "$.spec.baseimage")
+	if boundary <= inner {
+		t.Errorf("expected a segment-start match to score higher (%d vs %d)", boundary, inner)
+	}
+}
+
+func TestNodeInfo(t *testing.T) {
+	root, err := parser.New().Parse(strings.NewReader("base: &b\n  port: 80\n  tls: !!bool true\nlist: [a]\nref: *b\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	mapping := root.Children[0]
+
+	tests := []struct {
+		node *parser.YamNode
+		want string
+	}{
+		{mapping.Children[0], "mapping, 2 keys · line 1, col 7"},
+		{mapping.Children[0].Children[0], "int · line 2, col 9"},
+		{mapping.Children[0].Children[1], "bool · !!bool · line 3, col 8"},
+		{mapping.Children[1], "sequence, 1 item · line 4, col 7"},
+		{mapping.Children[2], "alias of &b · line 5, col 6"},
+	}
+	for _, tt := range tests {
+		if got := nodeInfo(tt.node); got != tt.want {
+			t.Errorf("nodeInfo(%s) = %q, want %q", tt.node.PathString(), got, tt.want)
+		}
+	}
+}
+
+func TestSearchHighlightsMatches(t *testing.T) {
+	root, err := parser.New().Parse(strings.NewReader("host: db.Host.local\nport: 5432\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	// Upper-case the matched text so the highlight shows without colors
+	theme := renderer.DefaultTheme()
+	theme.Match = theme.Match.Transform(strings.ToUpper)
+	m := NewModel(root, "test.yaml", renderer.TreeStyleUnicode, false).WithTheme(theme)
+	host, err := parser.GetByPath(root, ".host")
+	if err != nil {
+		t.Fatalf("GetByPath failed: %v", err)
+	}
+
+	m.search("v:host")
+	if line := m.lineCache.line(host, m.renderLine); !strings.Contains(line, "host: db.HOST.local") {
+		t.Errorf("expected only the match in the value to be highlighted, got %q", line)
+	}
+
+	m.search("HOST")
+	if line := m.lineCache.line(host, m.renderLine); !strings.Contains(line, "HOST: db.HOST.local") {
+		t.Errorf("expected the matches in key and value to be highlighted, got %q", line)
+	}
+
+	m.clearSearch()
+	if line := m.lineCache.line(host, m.renderLine); !strings.Contains(line, "host: db.Host.local") {
+		t.Errorf("expected no highlight after clearing the search, got %q", line)
+	}
+}
+
+func TestSubtreeFolds(t *testing.T) {
+	root, err := parser.New().Parse(strings.NewReader("a:\n  b:\n    c: 1\n  d: [1]\ne:\n  f: {g: 1}\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	m := NewModel(root, "test.yaml", renderer.TreeStyleUnicode, false)
+	m.collapseAll()
+
+	m.cursor = 1 // $.a
+	m.expandSubtree()
+	if got := strings.Join(collapsedPaths(m.root), ","); got != "$.e,$.e.f" {
+		t.Errorf("expected only $.e to stay collapsed, got %s", got)
+	}
+
+	m.cursor = 3 // $.a.b.c
+	m.collapseSubtree()
+	if got := strings.Join(collapsedPaths(m.root), ","); got != "$.a.b,$.e,$.e.f" {
+		t.Errorf("expected the enclosing $.a.b to be collapsed, got %s", got)
+	}
+	if node := m.flatNodes[m.cursor]; node.PathString() != "$.a.b" {
+		t.Errorf("expected the cursor on $.a.b, got %s", node.PathString())
+	}
+}
+
+func TestMaxValueLengthAndValueView(t *testing.T) {
+	long := strings.Repeat("日本語", 20)
+	root, err := parser.New().Parse(strings.NewReader("note: " + long + "\nmap:\n  a: 1\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	updated, _ := NewModel(root, "test.yaml", renderer.TreeStyleUnicode, false).
+		WithMaxValueLength(10).
+		Update(tea.WindowSizeMsg{Width: 40, Height: 20})
+	m := updated.(Model)
+
+	press := func(msg tea.KeyMsg) {
+		updated, _ := m.Update(msg)
+		m = updated.(Model)
+	}
+	// Cut to 9 whole runes and "…"
+	m.cursor = 1
+	if line := m.renderLine(m.flatNodes[1]); !strings.Contains(line, "日本語日本語日本語…") || strings.Contains(line, long) {
+		t.Errorf("expected the value cut to 10 characters, got %q", line)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("v")})
+	if m.valueNode == nil {
+		t.Fatal("expected v to open the value view")
+	}
+	view := m.View()
+	if !strings.Contains(view, "$.note (60 characters)") || !strings.Contains(view, strings.Repeat("日本語", 6)) {
+		t.Errorf("expected the full value wrapped to the screen, got:\n%s", view)
+	}
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.valueNode != nil {
+		t.Fatal("expected Esc to close the value view")
+	}
+
+	m.cursor = 2
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("v")})
+	if m.valueNode != nil || m.statusMessage != "Not a scalar value" {
+		t.Errorf("expected no value view for a mapping, got status %q", m.statusMessage)
+	}
+}
+
+func TestEmptyContainersRendered(t *testing.T) {
+	root, err := parser.New().Parse(strings.NewReader("map: {}\nlist: []\nnothing:\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	m := NewModel(root, "test.yaml", renderer.TreeStyleUnicode, false)
+
+	for i, want := range []string{"map: {}", "list: []", "nothing: null"} {
+		if line := ansi.Strip(m.renderLine(m.flatNodes[i+1])); !strings.Contains(line, want) {
+			t.Errorf("expected %q, got %q", want, line)
+		}
+	}
+}
+
+func TestWatchReload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a: 1\nb:\n  c: 2\n")
+	load := func() (*parser.YamNode, error) { return parser.New().ParseFile(file) }
+	root, err := load()
+	if err != nil {
+		t.Fatalf("ParseFile failed: %v", err)
+	}
+	updated, _ := NewModel(root, file, renderer.TreeStyleUnicode, false).
+		WithWatch(make(chan struct{})).
+		Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+	m := updated.(Model)
+	m.load = load
+	reload := func() {
+		updated, _ := m.Update(loadCmd(m.load)())
+		m = updated.(Model)
+	}
+
+	// Without edits the tree is reloaded, keeping folds and the cursor
+	m.cursor = 2
+	m.toggleCurrent()
+	write("a: 1\nz: 0\nb:\n  c: 3\n")
+	m.fileChanged()
+	if m.reloadPending {
+		t.Fatal("expected a reload without asking")
+	}
+	reload()
+	if len(m.flatNodes) != 4 || m.flatNodes[m.cursor].PathString() != "$.b" || !m.flatNodes[m.cursor].Collapsed {
+		t.Errorf("expected the new tree with $.b collapsed under the cursor, got cursor %d of %d", m.cursor, len(m.flatNodes))
+	}
+
+	// Unsaved edits are only discarded after R
+	m.modifiedNodes[m.flatNodes[1]] = true
+	write("a: 2\n")
+	m.fileChanged()
+	if !m.reloadPending || !strings.Contains(m.statusMessage, "press R") {
+		t.Fatalf("expected a prompt before discarding edits, got %q", m.statusMessage)
+	}
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("R")})
+	m = updated.(Model)
+	if cmd == nil {
+		t.Fatal("expected R to reload")
+	}
+	updated, _ = m.Update(cmd())
+	m = updated.(Model)
+	if len(m.modifiedNodes) != 0 || m.flatNodes[1].Value() != "2" {
+		t.Errorf("expected the edits dropped and the new value loaded, got %q", m.flatNodes[1].Value())
+	}
+
+	// The change made by saving is not a change to reload
+	m.modifiedNodes[m.flatNodes[1]] = true
+	m.savedContent = []byte("a: 2\n")
+	m.fileChanged()
+	if m.reloadPending {
+		t.Error("expected the model's own save to be ignored")
+	}
+}
+
+func TestReadOnlyFile(t *testing.T) {
+	root, err := parser.New().Parse(strings.NewReader("a: 1\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	dir := t.TempDir()
+
+	// A file that cannot be opened for writing, and a path that is not a
+	// regular file, are read-only from the start
+	file := filepath.Join(dir, "locked.yaml")
+	if err := os.WriteFile(file, []byte("a: 1\n"), 0o444); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		filename string
+		reason   string
+	}{
+		{"no permission", file, "no write permission"},
+		{"not a file", dir, "not a regular file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.reason == "no write permission" && os.Getuid() == 0 {
+				t.Skip("root can write any file")
+			}
+			m := NewModel(root, tt.filename, renderer.TreeStyleUnicode, false)
+			m.width, m.height = 80, 20
+			if !strings.Contains(m.View(), "[read-only]") {
+				t.Error("expected [read-only] in the header")
+			}
+			m.cursor = 1 // $.a
+			m.startEdit()
+			if m.editMode || !strings.Contains(m.statusMessage, tt.reason) {
+				t.Errorf("expected editing to be refused with %q, got %q", tt.reason, m.statusMessage)
+			}
+		})
+	}
+
+	// A writable file is not read-only
+	writable := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(writable, []byte("a: 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m := NewModel(root, writable, renderer.TreeStyleUnicode, false)
+	if source := m.readOnlySource(); source != "" {
+		t.Errorf("expected a writable file, got read-only (%s)", source)
+	}
+}
@@ -0,0 +1,150 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/simota/yam/internal/parser"
+)
+
+// paletteItem is a node offered by the path palette
+type paletteItem struct {
+	node  *parser.YamNode
+	path  string
+	score int
+}
+
+// openPalette lists the paths of all nodes, including collapsed ones, and
+// starts the palette input
+func (m *Model) openPalette() {
+	m.paletteItems = m.paletteItems[:0]
+	for _, node := range parser.Flatten(m.root) {
+		if node.Kind() == parser.KindDocument {
+			continue
+		}
+		m.paletteItems = append(m.paletteItems, paletteItem{node: node, path: node.PathString()})
+	}
+	m.paletteMode = true
+	m.paletteInput.SetValue("")
+	m.paletteInput.Focus()
+	m.filterPalette("")
+}
+
+// closePalette leaves the palette without moving the cursor
+func (m *Model) closePalette() {
+	m.paletteMode = false
+	m.paletteInput.Blur()
+	m.paletteItems = nil
+	m.paletteMatches = nil
+}
+
+// filterPalette keeps the items whose path fuzzy-matches query, best first.
+// An empty query keeps every item in document order.
+func (m *Model) filterPalette(query string) {
+	m.paletteMatches = m.paletteMatches[:0]
+	m.paletteIndex = 0
+	for _, item := range m.paletteItems {
+		score, ok := fuzzyScore(query, item.path)
+		if !ok {
+			continue
+		}
+		item.score = score
+		m.paletteMatches = append(m.paletteMatches, item)
+	}
+	if query == "" {
+		return
+	}
+	sort.SliceStable(m.paletteMatches, func(i, j int) bool {
+		a, b := m.paletteMatches[i], m.paletteMatches[j]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		return len(a.path) < len(b.path)
+	})
+}
+
+// movePalette moves the palette selection by delta, staying in range
+func (m *Model) movePalette(delta int) {
+	m.paletteIndex += delta
+	if m.paletteIndex >= len(m.paletteMatches) {
+		m.paletteIndex = len(m.paletteMatches) - 1
+	}
+	if m.paletteIndex < 0 {
+		m.paletteIndex = 0
+	}
+}
+
+// selectPaletteItem closes the palette and moves the cursor to the selected
+// node, expanding its ancestors
+func (m *Model) selectPaletteItem() {
+	if m.paletteIndex >= len(m.paletteMatches) {
+		m.closePalette()
+		return
+	}
+	node := m.paletteMatches[m.paletteIndex].node
+	m.closePalette()
+	m.expandAncestors(node)
+	m.rebuildFlatList()
+	m.moveToNode(node)
+}
+
+// paletteLines returns up to height lines of matches, scrolled so the
+// selection is visible, and the index of the selected line
+func (m *Model) paletteLines(height int) ([]string, int) {
+	start := 0
+	if m.paletteIndex >= height {
+		start = m.paletteIndex - height + 1
+	}
+	var lines []string
+	for i := start; i < len(m.paletteMatches) && len(lines) < height; i++ {
+		lines = append(lines, "  "+m.paletteMatches[i].path)
+	}
+	return lines, m.paletteIndex - start
+}
+
+// paletteStatus describes the palette matches for the footer
+func (m *Model) paletteStatus() string {
+	if len(m.paletteMatches) == 0 {
+		return "  [no matches]"
+	}
+	return fmt.Sprintf("  [%d/%d]", m.paletteIndex+1, len(m.paletteMatches))
+}
+
+// fuzzyScore reports whether the runes of pattern appear in s in order,
+// ignoring case, and scores the match. Consecutive runes and runes at the
+// start of a path segment score higher.
+func fuzzyScore(pattern, s string) (int, bool) {
+	pattern = strings.ToLower(pattern)
+	if pattern == "" {
+		return 0, true
+	}
+
+	want := []rune(pattern)
+	score, matched := 0, 0
+	prevMatched := false
+	prev := rune(0)
+	for i, r := range strings.ToLower(s) {
+		if matched < len(want) && r == want[matched] {
+			score++
+			if prevMatched {
+				score += 5
+			}
+			if i == 0 || isPathBoundary(prev) {
+				score += 8
+			}
+			matched++
+			prevMatched = true
+		} else {
+			prevMatched = false
+		}
+		prev = r
+	}
+	return score, matched == len(want)
+}
+
+// isPathBoundary reports whether r separates words in a path
+func isPathBoundary(r rune) bool {
+	return r == '.' || r == '[' || r == '_' || r == '-' || r == '/' || unicode.IsSpace(r)
+}
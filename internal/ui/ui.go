@@ -6,10 +6,44 @@ import (
 	"github.com/simota/yam/internal/renderer"
 )
 
-// Run starts the TUI application
-func Run(root *parser.YamNode, filename string, treeStyle renderer.TreeStyle, showTypes bool) error {
-	m := NewModel(root, filename, treeStyle, showTypes)
+// LoadFunc produces the tree to display. It runs in the background while
+// the TUI shows a loading spinner.
+type LoadFunc func() (*parser.YamNode, error)
+
+// Options configures how the TUI opens
+type Options struct {
+	GotoPath       string          // Start on the node at this path
+	Search         string          // Start with this search active, on its first match
+	RememberFolds  bool            // Restore the fold state saved for the file, and save it on quit
+	Theme          *renderer.Theme // Colors for the tree; nil uses the default theme
+	MaxValueLength int             // Cut values longer than this many characters (0 = unlimited)
+	Changes        <-chan struct{} // Reload the tree on each value, e.g. from a file watcher (nil = never)
+}
+
+// Run starts the TUI application. A load error is returned once the TUI
+// has exited; aborting the load with Ctrl+C returns nil.
+func Run(load LoadFunc, filename string, treeStyle renderer.TreeStyle, showTypes bool, opts Options) error {
+	m := NewLoadingModel(load, filename, treeStyle, showTypes)
+	if opts.Theme != nil {
+		m = m.WithTheme(opts.Theme)
+	}
+	if opts.MaxValueLength > 0 {
+		m = m.WithMaxValueLength(opts.MaxValueLength)
+	}
+	if opts.Changes != nil {
+		m = m.WithWatch(opts.Changes)
+	}
+	if opts.RememberFolds {
+		m = m.WithRememberedFolds()
+	}
+	m = m.WithStart(opts.GotoPath, opts.Search)
 	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
-	_, err := p.Run()
-	return err
+	final, err := p.Run()
+	if err != nil {
+		return err
+	}
+	if fm, ok := final.(Model); ok && fm.loadErr != nil {
+		return fm.loadErr
+	}
+	return nil
 }
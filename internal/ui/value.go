@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/simota/yam/internal/parser"
+)
+
+// openValue shows the full value of the scalar under the cursor, however
+// long, in place of the tree
+func (m *Model) openValue() {
+	if m.cursor < 0 || m.cursor >= len(m.flatNodes) {
+		return
+	}
+	node := m.flatNodes[m.cursor]
+	if node.Kind() != parser.KindScalar {
+		m.statusMessage = "Not a scalar value"
+		return
+	}
+	m.valueNode = node
+	m.valueOffset = 0
+}
+
+// closeValue returns from the value view to the tree
+func (m *Model) closeValue() {
+	m.valueNode = nil
+	m.valueOffset = 0
+}
+
+// valueLines returns the value of valueNode wrapped to the screen width,
+// with a title line
+func (m *Model) valueLines() []string {
+	value := m.valueNode.Value()
+	lines := []string{fmt.Sprintf("  %s (%d characters)", m.valueNode.PathString(), utf8.RuneCountInString(value))}
+	width := m.width - 4
+	if width < 1 {
+		width = 1
+	}
+	for _, line := range strings.Split(ansi.Wrap(value, width, ""), "\n") {
+		lines = append(lines, "  "+line)
+	}
+	return lines
+}
+
+// scrollValue moves the value view by delta lines, staying in range
+func (m *Model) scrollValue(delta int) {
+	m.valueOffset += delta
+	if last := len(m.valueLines()) - m.viewportHeight(); m.valueOffset > last {
+		m.valueOffset = last
+	}
+	if m.valueOffset < 0 {
+		m.valueOffset = 0
+	}
+}
+
+// valueStatus describes the scroll position of the value view for the
+// footer
+func (m *Model) valueStatus() string {
+	total := len(m.valueLines())
+	last := m.valueOffset + m.viewportHeight()
+	if last > total {
+		last = total
+	}
+	return fmt.Sprintf("lines %d-%d/%d  [↑/↓: scroll, Esc: close]", m.valueOffset+1, last, total)
+}
@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"bytes"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/simota/yam/internal/parser"
+)
+
+// fileChangedMsg reports that the file shown changed on disk
+type fileChangedMsg struct{}
+
+// WithWatch returns a copy of the model that reloads the tree whenever a
+// value arrives on changes. With unsaved edits it asks first.
+func (m Model) WithWatch(changes <-chan struct{}) Model {
+	m.changes = changes
+	return m
+}
+
+// waitForChange waits for the next change on changes; a closed channel
+// ends the watch
+func waitForChange(changes <-chan struct{}) tea.Cmd {
+	return func() tea.Msg {
+		if _, ok := <-changes; !ok {
+			return nil
+		}
+		return fileChangedMsg{}
+	}
+}
+
+// fileChanged reloads the tree after a change on disk, unless the change
+// is the model's own save, or asks before discarding unsaved edits
+func (m *Model) fileChanged() tea.Cmd {
+	wait := waitForChange(m.changes)
+	if m.savedContent != nil {
+		if data, err := os.ReadFile(m.filename); err == nil && bytes.Equal(data, m.savedContent) {
+			return wait
+		}
+	}
+	if m.modified || len(m.modifiedNodes) > 0 || m.editMode {
+		m.reloadPending = true
+		m.statusMessage = "File changed on disk: press R to reload and discard your edits"
+		return wait
+	}
+	return tea.Batch(wait, loadCmd(m.load))
+}
+
+// confirmReload reloads the tree that changed on disk while it had unsaved
+// edits
+func (m *Model) confirmReload() tea.Cmd {
+	if !m.reloadPending {
+		m.statusMessage = "File has not changed on disk"
+		return nil
+	}
+	m.reloadPending = false
+	return loadCmd(m.load)
+}
+
+// reload replaces the tree with a newly loaded one, keeping the folds, the
+// cursor position and the search of the old tree where their paths still
+// exist. Edits and their undo history belong to the old tree and are
+// dropped. A load error keeps the old tree.
+func (m *Model) reload(msg loadedMsg) {
+	if msg.err != nil {
+		m.statusMessage = "Reload failed: " + msg.err.Error()
+		return
+	}
+
+	var cursorPath string
+	if m.cursor >= 0 && m.cursor < len(m.flatNodes) {
+		cursorPath = m.flatNodes[m.cursor].PathString()
+	}
+	collapsed := collapsedPaths(m.root)
+
+	m.setRoot(msg.root, msg.flat)
+	m.collapsePaths(collapsed)
+	m.modified = false
+	m.modifiedNodes = make(map[*parser.YamNode]bool)
+	m.undoStack, m.redoStack = nil, nil
+	m.savedContent = nil
+	m.reloadPending = false
+	clear(m.revealed)
+	m.closeValue()
+
+	if query := m.searchInput.Value(); query != "" {
+		m.search(query)
+	}
+	m.cursor = 0
+	for i, node := range m.flatNodes {
+		if node.PathString() == cursorPath {
+			m.cursor = i
+			break
+		}
+	}
+	m.adjustOffset()
+	m.statusMessage = "Reloaded " + m.filename
+}